@@ -9,6 +9,9 @@ import (
 	"github.com/max-sanch/BotFreelancer-core"
 	"github.com/max-sanch/BotFreelancer-core/pkg/handler"
 	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+	"github.com/max-sanch/BotFreelancer-core/pkg/scheduler"
+	"github.com/max-sanch/BotFreelancer-core/pkg/secret"
+	"github.com/max-sanch/BotFreelancer-core/pkg/sender"
 	"github.com/max-sanch/BotFreelancer-core/pkg/service"
 
 	"github.com/joho/godotenv"
@@ -39,8 +42,39 @@ func main() {
 		logrus.Fatalf("failed initialize postgres database: %s", err.Error())
 	}
 
+	credentialsKeys, err := secret.ParseKeyring(os.Getenv("CREDENTIALS_KEYS"))
+	if err != nil {
+		logrus.Fatalf("error parsing credentials keys: %s", err.Error())
+	}
+
 	repos := repository.NewPostgresRepos(db)
-	services := service.NewService(repos)
+	services := service.NewService(repos, service.Config{
+		SigningKey:      os.Getenv("SIGNING_KEY"),
+		ErasureKey:      os.Getenv("ERASURE_KEY"),
+		CredentialsKeys: credentialsKeys,
+		BootstrapKey:    os.Getenv("ADMIN_API_KEY"),
+		ParserKeyId:     os.Getenv("PARSER_KEY_ID"),
+		ParserSecret:    os.Getenv("PARSER_SECRET"),
+	})
+
+	if credentialsKeys == nil {
+		logrus.Warn("CREDENTIALS_KEYS is not set, channels can not be created or updated")
+	} else {
+		rotated, err := services.Channel.RotateCredentials()
+		if err != nil {
+			logrus.Fatalf("error occured while sealing channel credentials: %s", err.Error())
+		} else if rotated > 0 {
+			logrus.Infof("sealed credentials of %d channels with the current key", rotated)
+		}
+
+		rotated, err = services.ApiKey.RotateSecrets()
+		if err != nil {
+			logrus.Fatalf("error occured while sealing api key secrets: %s", err.Error())
+		} else if rotated > 0 {
+			logrus.Infof("sealed signing secrets of %d api keys with the current key", rotated)
+		}
+	}
+
 	handlers := handler.NewHandler(services)
 
 	srv := new(core.Server)
@@ -52,10 +86,28 @@ func main() {
 
 	logrus.Print("Server started")
 
+	ctx, cancel := context.WithCancel(context.Background())
+	go scheduler.NewScheduler(services, viper.GetDuration("scheduler.interval")).Run(ctx)
+
+	if viper.GetString("sender.enabled") == "True" {
+		bot := sender.NewBotClient(sender.Config{
+			BaseURL:      viper.GetString("sender.url"),
+			Token:        os.Getenv("TG_BOT_TOKEN"),
+			GlobalRate:   viper.GetInt("sender.global_rate"),
+			ChatInterval: viper.GetDuration("sender.chat_interval"),
+			MaxRetries:   viper.GetInt("sender.max_retries"),
+		})
+		go sender.NewSender(services, bot, viper.GetDuration("sender.interval")).Run(ctx)
+
+		logrus.Print("Sender started")
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
+	cancel()
+
 	if err := srv.Shutdown(context.Background()); err != nil {
 		logrus.Errorf("error occured on server shutting down: %s", err.Error())
 	}
@@ -0,0 +1,39 @@
+package core
+
+import "errors"
+
+// Kinds of domain errors. Errors of a kind are matched with errors.Is, so
+// handlers can pick a status code without knowing where the error came from.
+var (
+	ErrNotFound         = errors.New("record not found")
+	ErrAlreadyExists    = errors.New("record already exists")
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	ErrConflict         = errors.New("record was changed concurrently")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrNotConfigured    = errors.New("feature is not configured")
+)
+
+// Error is a domain error of one of the kinds above. Message is safe to
+// show to clients, Err keeps the underlying error for logs.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+// NewError returns an error of the given kind with its own message.
+func NewError(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
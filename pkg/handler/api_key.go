@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+func (h *Handler) getApiKeys(c *gin.Context) {
+	keys, err := h.services.ApiKey.GetAll()
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.ApiKeysResponse{
+		Keys: keys,
+	})
+}
+
+func (h *Handler) createApiKey(c *gin.Context) {
+	var input core.ApiKeyInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	key, err := h.services.ApiKey.Create(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *Handler) revokeApiKey(c *gin.Context) {
+	var input core.ApiKeyIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.ApiKey.Revoke(input.Id); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
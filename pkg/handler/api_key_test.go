@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_createApiKey(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockApiKey, input core.ApiKeyInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		input               core.ApiKeyInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"name":"fl-parser","scopes":["parser:ingest"]}`,
+			input: core.ApiKeyInput{
+				Name:   "fl-parser",
+				Scopes: []string{core.ScopeParserIngest},
+			},
+			mockBehavior: func(s *mock_service.MockApiKey, input core.ApiKeyInput) {
+				s.EXPECT().Create(input).Return(core.ApiKeyCreatedResponse{Id: 1, Key: "bfc_key"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"key":"bfc_key"}`,
+		},
+		{
+			name:                "Unknown Scope",
+			inputBody:           `{"name":"fl-parser","scopes":["root"]}`,
+			mockBehavior:        func(s *mock_service.MockApiKey, input core.ApiKeyInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:                "No Scopes",
+			inputBody:           `{"name":"fl-parser","scopes":[]}`,
+			mockBehavior:        func(s *mock_service.MockApiKey, input core.ApiKeyInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKey := mock_service.NewMockApiKey(c)
+			testCase.mockBehavior(apiKey, testCase.input)
+			services := &service.Service{ApiKey: apiKey}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/createApiKey", handler.createApiKey)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/createApiKey", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_revokeApiKey(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockApiKey, id int)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		id                  int
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"id":1}`,
+			id:        1,
+			mockBehavior: func(s *mock_service.MockApiKey, id int) {
+				s.EXPECT().Revoke(id).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"id":1}`,
+			id:        1,
+			mockBehavior: func(s *mock_service.MockApiKey, id int) {
+				s.EXPECT().Revoke(id).Return(service.ErrApiKeyNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"api key not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKey := mock_service.NewMockApiKey(c)
+			testCase.mockBehavior(apiKey, testCase.id)
+			services := &service.Service{ApiKey: apiKey}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/revokeApiKey", handler.revokeApiKey)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/revokeApiKey", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
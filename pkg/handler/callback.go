@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+)
+
+func (h *Handler) applyCallback(c *gin.Context) {
+	var input core.CallbackInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	action, err := h.services.Feedback.ApplyCallback(input)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidCallback) {
+			statusCode = http.StatusBadRequest
+		}
+
+		NewErrorResponse(c, statusCode, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, core.CallbackResponse{
+		Action: action,
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_applyCallback(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockFeedback, callbackInput core.CallbackInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputCallback       core.CallbackInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"data":"h.1.signature"}`,
+			inputCallback: core.CallbackInput{
+				TgId: 1111,
+				Data: "h.1.signature",
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, callbackInput core.CallbackInput) {
+				s.EXPECT().ApplyCallback(callbackInput).Return(service.ActionHideTask, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"action":"hide_task"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{"tg_id":1111}`,
+			mockBehavior:        func(s *mock_service.MockFeedback, callbackInput core.CallbackInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Invalid Signature",
+			inputBody: `{"tg_id":1111,"data":"h.2.signature"}`,
+			inputCallback: core.CallbackInput{
+				TgId: 1111,
+				Data: "h.2.signature",
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, callbackInput core.CallbackInput) {
+				s.EXPECT().ApplyCallback(callbackInput).Return("", service.ErrInvalidCallback)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid callback data"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"tg_id":1111,"data":"h.1.signature"}`,
+			inputCallback: core.CallbackInput{
+				TgId: 1111,
+				Data: "h.1.signature",
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, callbackInput core.CallbackInput) {
+				s.EXPECT().ApplyCallback(callbackInput).Return("", errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			feedback := mock_service.NewMockFeedback(c)
+			testCase.mockBehavior(feedback, testCase.inputCallback)
+			services := &service.Service{Feedback: feedback}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/applyCallback", handler.applyCallback)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/applyCallback", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
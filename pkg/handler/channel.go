@@ -1,23 +1,57 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	core "github.com/max-sanch/BotFreelancer-core"
-	"net/http"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
 )
 
 func (h *Handler) getTasksChannel(c *gin.Context) {
-	tasks, err := h.services.Channel.GetTasks()
+	var input core.FeedInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	tasks, err := h.services.Channel.GetTasks(input)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	digests, err := h.services.Channel.GetDigests(input.Format)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, core.ChannelTasksResponse{
-		Tasks: tasks,
+		Tasks:   tasks,
+		Digests: digests,
 	})
 }
 
+func (h *Handler) getChannels(c *gin.Context) {
+	var input core.ChannelListInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	channels, err := h.services.Channel.GetAll(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
 func (h *Handler) getChannel(c *gin.Context) {
 	var input core.ApiIdInput
 
@@ -28,13 +62,31 @@ func (h *Handler) getChannel(c *gin.Context) {
 
 	channel, err := h.services.Channel.GetByApiId(input.ApiId)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
+	setETag(c, channel.Version)
 	c.JSON(http.StatusOK, channel)
 }
 
+func (h *Handler) getChannelCredentials(c *gin.Context) {
+	var input core.ApiIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	credentials, err := h.services.Channel.GetCredentials(input.ApiId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
 func (h *Handler) createChannel(c *gin.Context) {
 	var input core.ChannelInput
 
@@ -45,7 +97,7 @@ func (h *Handler) createChannel(c *gin.Context) {
 
 	id, err := h.services.Channel.Create(input)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
@@ -54,17 +106,29 @@ func (h *Handler) createChannel(c *gin.Context) {
 	})
 }
 
+// updateChannel keeps If-Match optional as v1 always did. A stale version
+// fails with 412 when the header was sent and with 409 when only the body
+// carries it.
 func (h *Handler) updateChannel(c *gin.Context) {
 	var input core.ChannelInput
 
+	version, ifMatch, ok := ifMatchVersion(c, false)
+	if !ok {
+		return
+	}
+
 	if err := c.BindJSON(&input); err != nil {
 		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
 		return
 	}
 
+	if version != 0 {
+		input.Version = version
+	}
+
 	id, err := h.services.Channel.Update(input)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		updateErrorResponse(c, err, ifMatch)
 		return
 	}
 
@@ -73,6 +137,39 @@ func (h *Handler) updateChannel(c *gin.Context) {
 	})
 }
 
+// upsertChannel creates the channel or updates an existing one and answers
+// 201 or 200 accordingly.
+func (h *Handler) upsertChannel(c *gin.Context) {
+	var input core.ChannelInput
+
+	version, ifMatch, ok := ifMatchVersion(c, false)
+	if !ok {
+		return
+	}
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if ifMatch {
+		input.Version = version
+	}
+
+	result, err := h.services.Channel.Upsert(input)
+	if err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Created {
+		statusCode = http.StatusCreated
+	}
+
+	c.JSON(statusCode, result)
+}
+
 func (h *Handler) deleteChannel(c *gin.Context) {
 	var input core.ApiIdInput
 
@@ -81,8 +178,27 @@ func (h *Handler) deleteChannel(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Channel.Delete(input.ApiId); err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+	// Deleting a missing channel succeeds in v1.
+	if err := h.services.Channel.Delete(input.ApiId); err != nil && !errors.Is(err, service.ErrChannelNotFound) {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) restoreChannel(c *gin.Context) {
+	var input core.ApiIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Channel.Restore(input.ApiId); err != nil {
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
@@ -90,3 +206,94 @@ func (h *Handler) deleteChannel(c *gin.Context) {
 		"status": "ok",
 	})
 }
+
+func (h *Handler) purgeChannel(c *gin.Context) {
+	var input core.ApiIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Channel.Purge(input.ApiId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) pauseChannel(c *gin.Context) {
+	var input core.ApiIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Channel.Pause(input.ApiId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) resumeChannel(c *gin.Context) {
+	var input core.ApiIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Channel.Resume(input.ApiId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) setChannelStatus(c *gin.Context) {
+	var input core.ChannelStatusInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Channel.SetStatus(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) reportChannelDelivery(c *gin.Context) {
+	var input core.ChannelDeliveryInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	status, err := h.services.Channel.ReportDelivery(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.RecipientStatusResponse{
+		Status: status,
+	})
+}
@@ -27,26 +27,26 @@ func TestHandler_getTasksChannel(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockChannel) {
-				s.EXPECT().GetTasks().Return([]core.ChannelTaskResponse{
+				s.EXPECT().GetTasks(core.FeedInput{}).Return([]core.ChannelTaskResponse{
 					{
-						ApiId:   1111,
-						ApiHash: "hash1111",
-						Title:   "Test",
-						Body:    "TestBody",
-						Url:     "TestUrl",
+						ApiId: 1111,
+						Title: "Test",
+						Body:  "TestBody",
+						Url:   "TestUrl",
 					},
 				}, nil)
+				s.EXPECT().GetDigests("").Return(nil, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"tasks":[{"api_id":1111,"api_hash":"hash1111","title":"Test","body":"TestBody","url":"TestUrl"}]}`,
+			expectedRequestBody: `{"tasks":[{"api_id":1111,"title":"Test","body":"TestBody","url":"TestUrl"}]}`,
 		},
 		{
 			name: "Service Failure",
 			mockBehavior: func(s *mock_service.MockChannel) {
-				s.EXPECT().GetTasks().Return([]core.ChannelTaskResponse{}, errors.New("service failure"))
+				s.EXPECT().GetTasks(core.FeedInput{}).Return([]core.ChannelTaskResponse{}, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -99,27 +99,28 @@ func TestHandler_getChannel(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
 				s.EXPECT().GetByApiId(apiIdInput.ApiId).Return(core.ChannelResponse{
-					Id:      1,
-					ApiId:   1111,
-					ApiHash: "hash1111",
-					Name:    "channel-1",
+					Id:     1,
+					ApiId:  1111,
+					Name:   "channel-1",
+					Status: "active",
 					Setting: core.SettingResponse{
-						IsSafeDeal: false,
-						IsBudget:   false,
-						IsTerm:     false,
-						Categories: []int{1, 2},
+						IsSafeDeal:   false,
+						IsBudget:     false,
+						IsTerm:       false,
+						Categories:   []int{1, 2},
+						DeliveryMode: "instant",
 					},
 				}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"id":1,"api_id":1111,"api_hash":"hash1111","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			expectedRequestBody: `{"id":1,"api_id":1111,"name":"channel-1","status":"active","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2],"delivery_mode":"instant"}}`,
 		},
 		{
 			name:                "Empty Fields",
 			inputBody:           `{}`,
 			mockBehavior:        func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -131,7 +132,7 @@ func TestHandler_getChannel(t *testing.T) {
 				s.EXPECT().GetByApiId(apiIdInput.ApiId).Return(core.ChannelResponse{}, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -202,7 +203,7 @@ func TestHandler_createChannel(t *testing.T) {
 			inputBody:           `{"api_hash":"hash1111","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
 			mockBehavior:        func(s *mock_service.MockChannel, channelInput core.ChannelInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -222,7 +223,7 @@ func TestHandler_createChannel(t *testing.T) {
 				s.EXPECT().Create(channelInput).Return(0, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -262,6 +263,7 @@ func TestHandler_updateChannel(t *testing.T) {
 
 	testTable := []struct {
 		name                string
+		ifMatch             string
 		inputBody           string
 		inputChannel        core.ChannelInput
 		mockBehavior        mockBehavior
@@ -288,12 +290,55 @@ func TestHandler_updateChannel(t *testing.T) {
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"id":1}`,
 		},
+		{
+			name:      "Stale Version",
+			ifMatch:   `"2"`,
+			inputBody: `{"api_id":1111,"api_hash":"hash1111","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputChannel: core.ChannelInput{
+				ApiId:   1111,
+				ApiHash: "hash1111",
+				Name:    "channel-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+				Version: 2,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, channelInput core.ChannelInput) {
+				s.EXPECT().Update(channelInput).Return(0, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:      "Stale Body Version",
+			inputBody: `{"api_id":1111,"api_hash":"hash1111","name":"channel-1","version":2,"setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputChannel: core.ChannelInput{
+				ApiId:   1111,
+				ApiHash: "hash1111",
+				Name:    "channel-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+				Version: 2,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, channelInput core.ChannelInput) {
+				s.EXPECT().Update(channelInput).Return(0, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"conflict","message":"record was changed concurrently"}`,
+		},
 		{
 			name:                "Empty Fields",
 			inputBody:           `{"api_hash":"hash1111","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
 			mockBehavior:        func(s *mock_service.MockChannel, channelInput core.ChannelInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -313,7 +358,7 @@ func TestHandler_updateChannel(t *testing.T) {
 				s.EXPECT().Update(channelInput).Return(0, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -336,6 +381,9 @@ func TestHandler_updateChannel(t *testing.T) {
 			// Test Request
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("POST", "/updateChannel", bytes.NewBufferString(testCase.inputBody))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
 
 			// Perform Request
 			r.ServeHTTP(w, req)
@@ -375,7 +423,19 @@ func TestHandler_deleteChannel(t *testing.T) {
 			inputBody:           `{}`,
 			mockBehavior:        func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Missing Channel",
+			inputBody: `{"api_id":1111}`,
+			inputApiId: core.ApiIdInput{
+				ApiId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
+				s.EXPECT().Delete(apiIdInput.ApiId).Return(service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -387,7 +447,7 @@ func TestHandler_deleteChannel(t *testing.T) {
 				s.EXPECT().Delete(apiIdInput.ApiId).Return(errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -420,3 +480,462 @@ func TestHandler_deleteChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_setChannelStatus(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, statusInput core.ChannelStatusInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputStatus         core.ChannelStatusInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111,"status":"paused"}`,
+			inputStatus: core.ChannelStatusInput{
+				ApiId:       1111,
+				StatusInput: core.StatusInput{Status: "paused"},
+			},
+			mockBehavior: func(s *mock_service.MockChannel, statusInput core.ChannelStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:                "Unknown Status",
+			inputBody:           `{"api_id":1111,"status":"sleeping"}`,
+			mockBehavior:        func(s *mock_service.MockChannel, statusInput core.ChannelStatusInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Unknown Channel",
+			inputBody: `{"api_id":1111,"status":"active"}`,
+			inputStatus: core.ChannelStatusInput{
+				ApiId:       1111,
+				StatusInput: core.StatusInput{Status: "active"},
+			},
+			mockBehavior: func(s *mock_service.MockChannel, statusInput core.ChannelStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"api_id":1111,"status":"active"}`,
+			inputStatus: core.ChannelStatusInput{
+				ApiId:       1111,
+				StatusInput: core.StatusInput{Status: "active"},
+			},
+			mockBehavior: func(s *mock_service.MockChannel, statusInput core.ChannelStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.inputStatus)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/setChannelStatus", handler.setChannelStatus)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/setChannelStatus", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_pauseChannel(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, apiId int)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		apiId               int
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111}`,
+			apiId:     1111,
+			mockBehavior: func(s *mock_service.MockChannel, apiId int) {
+				s.EXPECT().Pause(apiId).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Unknown Channel",
+			inputBody: `{"api_id":1111}`,
+			apiId:     1111,
+			mockBehavior: func(s *mock_service.MockChannel, apiId int) {
+				s.EXPECT().Pause(apiId).Return(service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.apiId)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/pauseChannel", handler.pauseChannel)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/pauseChannel", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_reportChannelDelivery(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, deliveryInput core.ChannelDeliveryInput)
+	isTrue := true
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputDelivery       core.ChannelDeliveryInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111,"url":"test-url","is_delivered":true}`,
+			inputDelivery: core.ChannelDeliveryInput{
+				ApiId: 1111,
+				DeliveryInput: core.DeliveryInput{
+					Url:         "test-url",
+					IsDelivered: &isTrue,
+				},
+			},
+			mockBehavior: func(s *mock_service.MockChannel, deliveryInput core.ChannelDeliveryInput) {
+				s.EXPECT().ReportDelivery(deliveryInput).Return("active", nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"active"}`,
+		},
+		{
+			name:      "Unknown Channel",
+			inputBody: `{"api_id":1111,"url":"test-url","is_delivered":true}`,
+			inputDelivery: core.ChannelDeliveryInput{
+				ApiId: 1111,
+				DeliveryInput: core.DeliveryInput{
+					Url:         "test-url",
+					IsDelivered: &isTrue,
+				},
+			},
+			mockBehavior: func(s *mock_service.MockChannel, deliveryInput core.ChannelDeliveryInput) {
+				s.EXPECT().ReportDelivery(deliveryInput).Return("", service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.inputDelivery)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/reportChannelDelivery", handler.reportChannelDelivery)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reportChannelDelivery", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getChannels(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, listInput core.ChannelListInput)
+
+	testTable := []struct {
+		name                string
+		query               string
+		inputList           core.ChannelListInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?status=paused&page=2&per_page=1",
+			inputList: core.ChannelListInput{
+				PageInput: core.PageInput{Page: 2, PerPage: 1},
+				Status:    "paused",
+			},
+			mockBehavior: func(s *mock_service.MockChannel, listInput core.ChannelListInput) {
+				s.EXPECT().GetAll(listInput).Return(core.ChannelListResponse{
+					Channels: []core.ChannelListItemResponse{
+						{ApiId: 1111, Name: "test", Status: "paused"},
+					},
+					Total:   2,
+					Page:    2,
+					PerPage: 1,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"channels":[{"api_id":1111,"name":"test","status":"paused"}],` +
+				`"total":2,"page":2,"per_page":1}`,
+		},
+		{
+			name:                "Unknown Status",
+			query:               "?status=removed",
+			mockBehavior:        func(s *mock_service.MockChannel, listInput core.ChannelListInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
+		{
+			name:  "Service Failure",
+			query: "",
+			mockBehavior: func(s *mock_service.MockChannel, listInput core.ChannelListInput) {
+				s.EXPECT().GetAll(listInput).Return(core.ChannelListResponse{}, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.inputList)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/channels", handler.getChannels)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/channels"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_restoreChannel(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputApiId          core.ApiIdInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111}`,
+			inputApiId: core.ApiIdInput{
+				ApiId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
+				s.EXPECT().Restore(apiIdInput.ApiId).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Window Passed",
+			inputBody: `{"api_id":1111}`,
+			inputApiId: core.ApiIdInput{
+				ApiId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
+				s.EXPECT().Restore(apiIdInput.ApiId).Return(service.ErrNotRestorable)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"conflict","message":"channel is not deleted or its restore window has passed"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.inputApiId)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/restoreChannel", handler.restoreChannel)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/restoreChannel", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getChannelCredentials(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputApiId          core.ApiIdInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111}`,
+			inputApiId: core.ApiIdInput{
+				ApiId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
+				s.EXPECT().GetCredentials(apiIdInput.ApiId).Return(core.ChannelCredentialsResponse{
+					ApiId:   1111,
+					ApiHash: "hash1111",
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"api_id":1111,"api_hash":"hash1111"}`,
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"api_id":1111}`,
+			inputApiId: core.ApiIdInput{
+				ApiId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {
+				s.EXPECT().GetCredentials(apiIdInput.ApiId).Return(core.ChannelCredentialsResponse{}, service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockChannel, apiIdInput core.ApiIdInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel, testCase.inputApiId)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/getChannelCredentials", handler.getChannelCredentials)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/getChannelCredentials", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// bindApiId binds the api_id path parameter and answers 400 when it is not
+// a number.
+func bindApiId(c *gin.Context) (int, bool) {
+	var uri core.ApiIdUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid path params")
+		return 0, false
+	}
+
+	return uri.ApiId, true
+}
+
+func (h *Handler) createChannelV2(c *gin.Context) {
+	var input core.ChannelInput
+
+	if !bindJSONV2(c, &input) {
+		return
+	}
+
+	if _, err := h.services.Channel.Create(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	channel, err := h.services.Channel.GetByApiId(input.ApiId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v2/channels/"+strconv.Itoa(input.ApiId))
+	setETag(c, channel.Version)
+	c.JSON(http.StatusCreated, channel)
+}
+
+func (h *Handler) getChannelV2(c *gin.Context) {
+	apiId, ok := bindApiId(c)
+	if !ok {
+		return
+	}
+
+	channel, err := h.services.Channel.GetByApiId(apiId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	setETag(c, channel.Version)
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *Handler) patchChannelV2(c *gin.Context) {
+	var input core.ChannelPatchInput
+
+	apiId, ok := bindApiId(c)
+	if !ok {
+		return
+	}
+
+	version, _, ok := ifMatchVersion(c, true)
+	if !ok || !bindJSONV2(c, &input) {
+		return
+	}
+	input.Version = version
+
+	channel, err := h.services.Channel.Patch(apiId, input)
+	if err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	setETag(c, channel.Version)
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *Handler) replaceChannelV2(c *gin.Context) {
+	var input core.ChannelBody
+
+	apiId, ok := bindApiId(c)
+	if !ok {
+		return
+	}
+
+	version, _, ok := ifMatchVersion(c, true)
+	if !ok || !bindJSONV2(c, &input) {
+		return
+	}
+	input.Version = version
+
+	if _, err := h.services.Channel.Update(input.Input(apiId)); err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	channel, err := h.services.Channel.GetByApiId(apiId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	setETag(c, channel.Version)
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *Handler) deleteChannelV2(c *gin.Context) {
+	apiId, ok := bindApiId(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Channel.Delete(apiId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_createChannelV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel)
+
+	isFalse := false
+	input := core.ChannelInput{
+		ApiId:   1111,
+		ApiHash: "hash",
+		Name:    "channel-1",
+		Setting: core.SettingInput{
+			IsSafeDeal: &isFalse,
+			IsBudget:   &isFalse,
+			IsTerm:     &isFalse,
+			Categories: []int{1},
+		},
+	}
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedRequestBody  string
+		expectedLocationPath string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"api_id":1111,"api_hash":"hash","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Create(input).Return(1, nil)
+				s.EXPECT().GetByApiId(1111).Return(core.ChannelResponse{Id: 1, ApiId: 1111, Name: "channel-1", Version: 1}, nil)
+			},
+			expectedStatusCode:   201,
+			expectedRequestBody:  `{"id":1,"api_id":1111,"name":"channel-1","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""},"version":1}`,
+			expectedLocationPath: "/api/v2/channels/1111",
+		},
+		{
+			name:      "Already Exists",
+			inputBody: `{"api_id":1111,"api_hash":"hash","name":"channel-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Create(input).Return(0, service.ErrChannelExists)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"already_exists","message":"channel already exists"}`,
+		},
+		{
+			name:                "Malformed Body",
+			inputBody:           `{"api_id":`,
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/channels", handler.createChannelV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/channels", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedLocationPath, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHandler_getChannelV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel)
+
+	testTable := []struct {
+		name                string
+		path                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+		expectedETag        string
+	}{
+		{
+			name: "OK",
+			path: "/channels/1111",
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().GetByApiId(1111).Return(core.ChannelResponse{Id: 1, ApiId: 1111, Name: "channel-1", Version: 2}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"api_id":1111,"name":"channel-1","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""},"version":2}`,
+			expectedETag:        `"2"`,
+		},
+		{
+			name: "Not Found",
+			path: "/channels/1111",
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().GetByApiId(1111).Return(core.ChannelResponse{}, service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+		{
+			name:                "Invalid Path",
+			path:                "/channels/abc",
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid path params"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/channels/:api_id", handler.getChannelV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, bytes.NewBuffer([]byte{}))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_patchChannelV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel)
+
+	isTrue := true
+	patch := core.ChannelPatchInput{
+		Setting: &core.SettingPatchInput{
+			IsBudget:         &isTrue,
+			AddCategories:    []int{3},
+			RemoveCategories: []int{1},
+		},
+		Version: 2,
+	}
+
+	testTable := []struct {
+		name                string
+		ifMatch             string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+		expectedETag        string
+	}{
+		{
+			name:      "OK",
+			ifMatch:   `"2"`,
+			inputBody: `{"setting":{"is_budget":true,"add_categories":[3],"remove_categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Patch(1111, patch).Return(core.ChannelResponse{
+					Id:    1,
+					ApiId: 1111,
+					Name:  "channel-1",
+					Setting: core.SettingResponse{
+						IsBudget:   true,
+						Categories: []int{2, 3},
+					},
+					Version: 3,
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"api_id":1111,"name":"channel-1","status":"","setting":{"is_safe_deal":false,"is_budget":true,"is_term":false,"categories":[2,3],"delivery_mode":""},"version":3}`,
+			expectedETag:        `"3"`,
+		},
+		{
+			name:      "Stale Version",
+			ifMatch:   `"2"`,
+			inputBody: `{"setting":{"is_budget":true,"add_categories":[3],"remove_categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Patch(1111, patch).Return(core.ChannelResponse{}, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:                "Missing If-Match",
+			inputBody:           `{"setting":{"is_budget":true}}`,
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  428,
+			expectedRequestBody: `{"code":"precondition_required","message":"If-Match header is required"}`,
+		},
+		{
+			name:                "Weak If-Match",
+			ifMatch:             `W/"2"`,
+			inputBody:           `{"setting":{"is_budget":true}}`,
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:                "Malformed Body",
+			ifMatch:             "*",
+			inputBody:           `{"setting":{"add_categories":"3"}}`,
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PATCH("/channels/:api_id", handler.patchChannelV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/channels/1111", bytes.NewBufferString(testCase.inputBody))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_replaceChannelV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel)
+
+	isFalse := false
+	input := core.ChannelInput{
+		ApiId:   1111,
+		ApiHash: "hash",
+		Name:    "channel-2",
+		Setting: core.SettingInput{
+			IsSafeDeal: &isFalse,
+			IsBudget:   &isFalse,
+			IsTerm:     &isFalse,
+			Categories: []int{1},
+		},
+		Version: 2,
+	}
+	body := `{"api_hash":"hash","name":"channel-2","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1]}}`
+
+	testTable := []struct {
+		name                string
+		ifMatch             string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+		expectedETag        string
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"2"`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Update(input).Return(1, nil)
+				s.EXPECT().GetByApiId(1111).Return(core.ChannelResponse{Id: 1, ApiId: 1111, Name: "channel-2", Version: 3}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"api_id":1111,"name":"channel-2","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""},"version":3}`,
+			expectedETag:        `"3"`,
+		},
+		{
+			name:    "Stale Version",
+			ifMatch: `"2"`,
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Update(input).Return(0, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:                "Missing If-Match",
+			mockBehavior:        func(s *mock_service.MockChannel) {},
+			expectedStatusCode:  428,
+			expectedRequestBody: `{"code":"precondition_required","message":"If-Match header is required"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PUT("/channels/:api_id", handler.replaceChannelV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/channels/1111", bytes.NewBufferString(body))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_deleteChannelV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockChannel)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Delete(1111).Return(nil)
+			},
+			expectedStatusCode: 204,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func(s *mock_service.MockChannel) {
+				s.EXPECT().Delete(1111).Return(service.ErrChannelNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"channel not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			channel := mock_service.NewMockChannel(c)
+			testCase.mockBehavior(channel)
+			services := &service.Service{Channel: channel}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.DELETE("/channels/:api_id", handler.deleteChannelV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/channels/1111", bytes.NewBuffer([]byte{}))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
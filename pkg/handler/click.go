@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+func (h *Handler) openLink(c *gin.Context) {
+	url, err := h.services.Click.Open(c.Param("token"))
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+func (h *Handler) getCategoryRates(c *gin.Context) {
+	var input core.PeriodInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	rates, err := h.services.Click.GetCategoryRates(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.ClickRatesResponse{
+		Rates: rates,
+	})
+}
+
+func (h *Handler) getSourceRates(c *gin.Context) {
+	var input core.PeriodInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	rates, err := h.services.Click.GetSourceRates(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.ClickRatesResponse{
+		Rates: rates,
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_openLink(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockClick, token string)
+
+	testTable := []struct {
+		name               string
+		token              string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedLocation   string
+	}{
+		{
+			name:  "OK",
+			token: "1.signature",
+			mockBehavior: func(s *mock_service.MockClick, token string) {
+				s.EXPECT().Open(token).Return("https://example.com/task/1", nil)
+			},
+			expectedStatusCode: 302,
+			expectedLocation:   "https://example.com/task/1",
+		},
+		{
+			name:  "Invalid Token",
+			token: "1.forged",
+			mockBehavior: func(s *mock_service.MockClick, token string) {
+				s.EXPECT().Open(token).Return("", service.ErrInvalidLink)
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name:  "Service Failure",
+			token: "1.signature",
+			mockBehavior: func(s *mock_service.MockClick, token string) {
+				s.EXPECT().Open(token).Return("", errors.New("service failure"))
+			},
+			expectedStatusCode: 500,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			click := mock_service.NewMockClick(c)
+			testCase.mockBehavior(click, testCase.token)
+			services := &service.Service{Click: click}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/r/:token", handler.openLink)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/r/"+testCase.token, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHandler_getSourceRates(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockClick)
+
+	testTable := []struct {
+		name                string
+		query               string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?from=2021-10-01&to=2021-10-31",
+			mockBehavior: func(s *mock_service.MockClick) {
+				s.EXPECT().GetSourceRates(gomock.Any()).Return([]core.ClickRateResponse{
+					{Source: "Habr Freelance", Links: 4, Clicked: 1, Clicks: 2, Ctr: 0.25},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"rates":[{"source":"Habr Freelance","links":4,"clicked":1,"clicks":2,"ctr":0.25}]}`,
+		},
+		{
+			name:                "Invalid Date",
+			query:               "?from=01.10.2021",
+			mockBehavior:        func(s *mock_service.MockClick) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
+		{
+			name:  "From After To",
+			query: "?from=2021-10-31&to=2021-10-01",
+			mockBehavior: func(s *mock_service.MockClick) {
+				s.EXPECT().GetSourceRates(gomock.Any()).
+					Return(nil, core.NewError(core.ErrInvalidInput, "from must not be after to"))
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"invalid_input","message":"from must not be after to"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			click := mock_service.NewMockClick(c)
+			testCase.mockBehavior(click)
+			services := &service.Service{Click: click}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/sources", handler.getSourceRates)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/sources"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
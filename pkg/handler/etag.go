@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// Users and channels are tagged with their version, e.g. ETag: "3". An
+// update sends the tag back in If-Match and fails with 412 when the record
+// was changed in between.
+
+func setETag(c *gin.Context, version int) {
+	if version != 0 {
+		c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+	}
+}
+
+// ifMatchVersion returns the version in the If-Match header, or 0 for "*"
+// which matches any version. It answers 428 when a required header is
+// missing and 412 when the header holds no version. present reports
+// whether the header was sent.
+func ifMatchVersion(c *gin.Context, required bool) (version int, present, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if required {
+			NewErrorResponse(c, http.StatusPreconditionRequired, "If-Match header is required")
+			return 0, false, false
+		}
+		return 0, false, true
+	}
+
+	if header == "*" {
+		return 0, true, true
+	}
+
+	// Weak tags never match in If-Match, see RFC 7232, section 3.1.
+	tag, err := strconv.Unquote(header)
+	if err == nil {
+		version, err = strconv.Atoi(tag)
+	}
+	if err != nil || version <= 0 {
+		NewErrorResponse(c, http.StatusPreconditionFailed, "If-Match does not match the current version")
+		return 0, true, false
+	}
+
+	return version, true, true
+}
+
+// updateErrorResponse answers 412 for a version conflict of an update made
+// with If-Match and falls back to NewErrorResponseFromError otherwise.
+func updateErrorResponse(c *gin.Context, err error, ifMatch bool) {
+	if ifMatch && errors.Is(err, core.ErrConflict) {
+		NewErrorResponse(c, http.StatusPreconditionFailed, "If-Match does not match the current version")
+		return
+	}
+
+	NewErrorResponseFromError(c, err)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+const exportFormatZip = "zip"
+
+func (h *Handler) exportUser(c *gin.Context) {
+	var input core.UserExportInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	export, err := h.services.User.Export(input.TgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	if input.Format != exportFormatZip {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := exportArchive(export)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=user-"+strconv.Itoa(input.TgId)+".zip")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// exportArchive packs the export into a ZIP with a CSV file per section.
+func exportArchive(export core.UserExportResponse) ([]byte, error) {
+	profile := export.Profile
+	setting := profile.Setting
+
+	var quietFrom, quietTo string
+	if profile.QuietHours != nil {
+		quietFrom, quietTo = profile.QuietHours.From, profile.QuietHours.To
+	}
+
+	var templateId string
+	if setting.TemplateId != nil {
+		templateId = strconv.Itoa(*setting.TemplateId)
+	}
+
+	files := []struct {
+		name    string
+		header  []string
+		records [][]string
+	}{
+		{
+			name: "profile.csv",
+			header: []string{"tg_id", "username", "status", "timezone", "language", "quiet_from", "quiet_to",
+				"is_safe_deal", "is_budget", "is_term", "delivery_mode", "digest_time", "template_id",
+				"excluded_keywords", "exported_at"},
+			records: [][]string{{strconv.Itoa(profile.TgId), profile.Username, profile.Status, profile.Timezone,
+				profile.Language, quietFrom, quietTo, strconv.FormatBool(setting.IsSafeDeal),
+				strconv.FormatBool(setting.IsBudget), strconv.FormatBool(setting.IsTerm), setting.DeliveryMode,
+				setting.DigestTime, templateId, strings.Join(setting.ExcludedKeywords, ";"),
+				export.ExportedAt.Format(time.RFC3339)}},
+		},
+		{
+			name:   "subscriptions.csv",
+			header: []string{"category_id", "category"},
+		},
+		{
+			name:   "deliveries.csv",
+			header: []string{"task_url", "is_delivered", "is_permanent", "reason", "created_at"},
+		},
+		{
+			name:   "saved_tasks.csv",
+			header: []string{"task_url", "title", "saved_at"},
+		},
+		{
+			name:   "not_interested.csv",
+			header: []string{"task_url", "title", "category_id", "reason", "created_at"},
+		},
+		{
+			name:   "hidden_tasks.csv",
+			header: []string{"task_url", "hidden_at"},
+		},
+		{
+			name:   "held_tasks.csv",
+			header: []string{"task_url", "title", "held_at"},
+		},
+		{
+			name:   "digest_queue.csv",
+			header: []string{"task_url", "title", "queued_at"},
+		},
+		{
+			name:   "digests.csv",
+			header: []string{"title", "tasks_count", "created_at", "served_at"},
+		},
+		{
+			name:   "matches.csv",
+			header: []string{"task_url", "fl_name", "category_id", "published_at", "matched_at"},
+		},
+		{
+			name:   "tracked_links.csv",
+			header: []string{"task_url", "fl_name", "category_id", "created_at"},
+		},
+		{
+			name:   "clicks.csv",
+			header: []string{"task_url", "clicked_at"},
+		},
+	}
+
+	for _, subscription := range export.Subscriptions {
+		files[1].records = append(files[1].records, []string{strconv.Itoa(subscription.CategoryId), subscription.Category})
+	}
+
+	for _, delivery := range export.Deliveries {
+		files[2].records = append(files[2].records, []string{delivery.Url, strconv.FormatBool(delivery.IsDelivered),
+			strconv.FormatBool(delivery.IsPermanent), delivery.Reason, delivery.CreatedAt.Format(time.RFC3339)})
+	}
+
+	for _, task := range export.SavedTasks {
+		files[3].records = append(files[3].records, []string{task.Url, task.Title, task.SavedAt.Format(time.RFC3339)})
+	}
+
+	activity := export.Activity
+
+	for _, mark := range activity.NotInterested {
+		files[4].records = append(files[4].records, []string{mark.Url, mark.Title, strconv.Itoa(mark.CategoryId),
+			mark.Reason, mark.CreatedAt.Format(time.RFC3339)})
+	}
+
+	for _, task := range activity.HiddenTasks {
+		files[5].records = append(files[5].records, []string{task.Url, task.HiddenAt.Format(time.RFC3339)})
+	}
+
+	for _, task := range activity.HeldTasks {
+		files[6].records = append(files[6].records, []string{task.Url, task.Title, task.AddedAt.Format(time.RFC3339)})
+	}
+
+	for _, task := range activity.DigestQueue {
+		files[7].records = append(files[7].records, []string{task.Url, task.Title, task.AddedAt.Format(time.RFC3339)})
+	}
+
+	for _, digest := range activity.Digests {
+		files[8].records = append(files[8].records, []string{digest.Title, strconv.Itoa(digest.TasksCount),
+			digest.CreatedAt.Format(time.RFC3339), formatExportTime(digest.ServedAt)})
+	}
+
+	for _, match := range activity.Matches {
+		files[9].records = append(files[9].records, []string{match.Url, match.FLName, strconv.Itoa(match.CategoryId),
+			formatExportTime(match.PublishedAt), match.MatchedAt.Format(time.RFC3339)})
+	}
+
+	for _, link := range activity.Links {
+		files[10].records = append(files[10].records, []string{link.Url, link.FLName, strconv.Itoa(link.CategoryId),
+			link.CreatedAt.Format(time.RFC3339)})
+	}
+
+	for _, click := range activity.Clicks {
+		files[11].records = append(files[11].records, []string{click.Url, click.ClickedAt.Format(time.RFC3339)})
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		w := csv.NewWriter(f)
+		if err := w.Write(file.header); err != nil {
+			return nil, err
+		}
+		if err := w.WriteAll(file.records); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatExportTime leaves the cell empty for a missing time.
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_exportUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, tgId int)
+
+	exportedAt := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	export := core.UserExportResponse{
+		Profile: core.UserResponse{
+			TgId:     1111,
+			Username: "test",
+			Status:   "active",
+			Setting:  core.SettingResponse{Categories: []int{1}, DeliveryMode: "instant"},
+		},
+		Subscriptions: []core.SubscriptionResponse{{CategoryId: 1, Category: "Backend"}},
+		SavedTasks:    []core.SavedTaskResponse{{Url: "https://fl.ru/7", Title: "Golang API", SavedAt: exportedAt}},
+		Activity: core.UserActivityResponse{
+			Digests: []core.DigestRecordResponse{{Title: "Digest", TasksCount: 2, CreatedAt: exportedAt}},
+			Clicks:  []core.ClickRecordResponse{{Url: "https://fl.ru/7", ClickedAt: exportedAt}},
+		},
+		ExportedAt: exportedAt,
+	}
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+		expectedFiles       map[string]string
+	}{
+		{
+			name:      "JSON",
+			inputBody: `{"tg_id":1111}`,
+			mockBehavior: func(s *mock_service.MockUser, tgId int) {
+				s.EXPECT().Export(tgId).Return(export, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"profile":{"id":0,"tg_id":1111,"username":"test","status":"active",` +
+				`"setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1],` +
+				`"delivery_mode":"instant"}},"subscriptions":[{"category_id":1,"category":"Backend"}],` +
+				`"deliveries":null,"saved_tasks":[{"task_url":"https://fl.ru/7","title":"Golang API",` +
+				`"saved_at":"2021-10-01T12:00:00Z"}],"activity":{"not_interested":null,"hidden_tasks":null,` +
+				`"held_tasks":null,"digest_queue":null,"digests":[{"title":"Digest","tasks_count":2,` +
+				`"created_at":"2021-10-01T12:00:00Z","served_at":null}],"matches":null,"tracked_links":null,` +
+				`"clicks":[{"task_url":"https://fl.ru/7","clicked_at":"2021-10-01T12:00:00Z"}]},` +
+				`"exported_at":"2021-10-01T12:00:00Z"}`,
+		},
+		{
+			name:      "ZIP",
+			inputBody: `{"tg_id":1111,"format":"zip"}`,
+			mockBehavior: func(s *mock_service.MockUser, tgId int) {
+				s.EXPECT().Export(tgId).Return(export, nil)
+			},
+			expectedStatusCode: 200,
+			expectedFiles: map[string]string{
+				"profile.csv": "tg_id,username,status,timezone,language,quiet_from,quiet_to,is_safe_deal,is_budget," +
+					"is_term,delivery_mode,digest_time,template_id,excluded_keywords,exported_at\n" +
+					"1111,test,active,,,,,false,false,false,instant,,,,2021-10-01T12:00:00Z\n",
+				"subscriptions.csv":  "category_id,category\n1,Backend\n",
+				"deliveries.csv":     "task_url,is_delivered,is_permanent,reason,created_at\n",
+				"saved_tasks.csv":    "task_url,title,saved_at\nhttps://fl.ru/7,Golang API,2021-10-01T12:00:00Z\n",
+				"not_interested.csv": "task_url,title,category_id,reason,created_at\n",
+				"hidden_tasks.csv":   "task_url,hidden_at\n",
+				"held_tasks.csv":     "task_url,title,held_at\n",
+				"digest_queue.csv":   "task_url,title,queued_at\n",
+				"digests.csv":        "title,tasks_count,created_at,served_at\nDigest,2,2021-10-01T12:00:00Z,\n",
+				"matches.csv":        "task_url,fl_name,category_id,published_at,matched_at\n",
+				"tracked_links.csv":  "task_url,fl_name,category_id,created_at\n",
+				"clicks.csv":         "task_url,clicked_at\nhttps://fl.ru/7,2021-10-01T12:00:00Z\n",
+			},
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"tg_id":1111}`,
+			mockBehavior: func(s *mock_service.MockUser, tgId int) {
+				s.EXPECT().Export(tgId).Return(core.UserExportResponse{}, service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:                "Unknown Format",
+			inputBody:           `{"tg_id":1111,"format":"xml"}`,
+			mockBehavior:        func(s *mock_service.MockUser, tgId int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, 1111)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/exportUser", handler.exportUser)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/exportUser", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedFiles == nil {
+				assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+				return
+			}
+
+			archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+			if err != nil {
+				t.Fatalf("response is not a zip archive: %s", err)
+			}
+
+			files := make(map[string]string)
+			for _, file := range archive.File {
+				f, err := file.Open()
+				if err != nil {
+					t.Fatalf("cannot open %s: %s", file.Name, err)
+				}
+				content, err := ioutil.ReadAll(f)
+				if err != nil {
+					t.Fatalf("cannot read %s: %s", file.Name, err)
+				}
+				files[file.Name] = string(content)
+			}
+			assert.Equal(t, testCase.expectedFiles, files)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+)
+
+func (h *Handler) saveTask(c *gin.Context) {
+	var input core.FeedbackTaskInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Feedback.SaveTask(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) getSavedTasks(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	tasks, err := h.services.Feedback.GetSavedTasks(input.TgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.SavedTasksResponse{
+		Tasks: tasks,
+	})
+}
+
+func (h *Handler) markNotInterested(c *gin.Context) {
+	var input core.NotInterestedInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	rules, err := h.services.Feedback.NotInterested(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.ExclusionRulesResponse{
+		Rules: rules,
+	})
+}
+
+func (h *Handler) getSuggestions(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	rules, err := h.services.Feedback.GetSuggestions(input.TgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.ExclusionRulesResponse{
+		Rules: rules,
+	})
+}
+
+func (h *Handler) applyRule(c *gin.Context) {
+	var input core.ExclusionRuleInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Feedback.ApplyRule(input); err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidRule) {
+			statusCode = http.StatusBadRequest
+		}
+
+		NewErrorResponse(c, statusCode, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
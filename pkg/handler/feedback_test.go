@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_markNotInterested(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockFeedback, notInterestedInput core.NotInterestedInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputNotInterested  core.NotInterestedInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"task_id":1,"reason":"too cheap"}`,
+			inputNotInterested: core.NotInterestedInput{
+				FeedbackTaskInput: core.FeedbackTaskInput{TgId: 1111, TaskId: 1},
+				Reason:            "too cheap",
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, notInterestedInput core.NotInterestedInput) {
+				s.EXPECT().NotInterested(notInterestedInput).Return([]core.ExclusionRuleResponse{
+					{Type: core.RuleCategory, CategoryId: 2, Category: "Wordpress", Count: 3},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"rules":[{"type":"category","category_id":2,"category":"Wordpress","count":3}]}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{"tg_id":1111}`,
+			mockBehavior:        func(s *mock_service.MockFeedback, notInterestedInput core.NotInterestedInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"tg_id":1111,"task_id":1}`,
+			inputNotInterested: core.NotInterestedInput{
+				FeedbackTaskInput: core.FeedbackTaskInput{TgId: 1111, TaskId: 1},
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, notInterestedInput core.NotInterestedInput) {
+				s.EXPECT().NotInterested(notInterestedInput).Return(nil, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			feedback := mock_service.NewMockFeedback(c)
+			testCase.mockBehavior(feedback, testCase.inputNotInterested)
+			services := &service.Service{Feedback: feedback}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/notInterested", handler.markNotInterested)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/notInterested", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_applyRule(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockFeedback, ruleInput core.ExclusionRuleInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputRule           core.ExclusionRuleInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"type":"keyword","keyword":"wordpress"}`,
+			inputRule: core.ExclusionRuleInput{
+				TgId:    1111,
+				Type:    core.RuleKeyword,
+				Keyword: "wordpress",
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, ruleInput core.ExclusionRuleInput) {
+				s.EXPECT().ApplyRule(ruleInput).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:                "Unknown Type",
+			inputBody:           `{"tg_id":1111,"type":"source"}`,
+			mockBehavior:        func(s *mock_service.MockFeedback, ruleInput core.ExclusionRuleInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Missing Keyword",
+			inputBody: `{"tg_id":1111,"type":"keyword"}`,
+			inputRule: core.ExclusionRuleInput{
+				TgId: 1111,
+				Type: core.RuleKeyword,
+			},
+			mockBehavior: func(s *mock_service.MockFeedback, ruleInput core.ExclusionRuleInput) {
+				s.EXPECT().ApplyRule(ruleInput).Return(service.ErrInvalidRule)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"category rule requires category_id and keyword rule requires keyword"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			feedback := mock_service.NewMockFeedback(c)
+			testCase.mockBehavior(feedback, testCase.inputRule)
+			services := &service.Service{Feedback: feedback}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/applyRule", handler.applyRule)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/applyRule", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -1,18 +1,22 @@
 package handler
 
 import (
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/ratelimit"
 	"github.com/max-sanch/BotFreelancer-core/pkg/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 type Handler struct {
-	services *service.Service
+	services  *service.Service
+	rateStore ratelimit.Store
 }
 
 func NewHandler(services *service.Service) *Handler {
-	return &Handler{services: services}
+	return &Handler{services: services, rateStore: ratelimit.NewMemoryStore()}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
@@ -21,24 +25,132 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	}
 
 	router := gin.New()
+	router.Use(h.requestId)
 
-	api := router.Group("/api")
+	router.GET("/r/:token", h.openLink)
+
+	// Parsers push tasks with parser:ingest. Bots read feeds with bot:read
+	// and change users and channels on their behalf with bot:write. Channel
+	// credentials need their own scope and everything else is for admin
+	// tools.
+	ingest := h.requireScope(core.ScopeParserIngest)
+	bot := h.requireScope(core.ScopeBotRead)
+	botWrite := h.requireScope(core.ScopeBotWrite)
+	credentials := h.requireScope(core.ScopeCredentials)
+	admin := h.requireScope(core.ScopeAdmin)
+
+	api := router.Group("/api", h.authenticate)
+	if viper.GetString("ratelimit.enabled") == "True" {
+		var limits ratelimit.Config
+		if err := viper.UnmarshalKey("ratelimit", &limits); err != nil {
+			logrus.Fatalf("error reading rate limits: %s", err.Error())
+		}
+
+		api.Use(h.rateLimit(limits))
+	}
 	{
 		channels := api.Group("/channels")
 		{
-			channels.GET("/data", h.getTasksChannel)
-			channels.POST("/channel", h.getChannel)
-			channels.POST("/create", h.createChannel)
-			channels.POST("/update", h.updateChannel)
-			channels.POST("/delete", h.deleteChannel)
+			channels.GET("", admin, h.getChannels)
+			channels.GET("/data", bot, h.getTasksChannel)
+			channels.POST("/channel", bot, h.getChannel)
+			channels.POST("/credentials", credentials, h.getChannelCredentials)
+			channels.POST("/create", botWrite, h.createChannel)
+			channels.POST("/update", botWrite, h.updateChannel)
+			channels.POST("/upsert", botWrite, h.upsertChannel)
+			channels.POST("/delete", admin, h.deleteChannel)
+			channels.POST("/restore", admin, h.restoreChannel)
+			channels.POST("/purge", admin, h.purgeChannel)
+			channels.POST("/pause", admin, h.pauseChannel)
+			channels.POST("/resume", admin, h.resumeChannel)
+			channels.POST("/status", botWrite, h.setChannelStatus)
+			channels.POST("/delivery", botWrite, h.reportChannelDelivery)
 		}
 
 		users := api.Group("/users")
 		{
-			users.GET("/data", h.getTasksUser)
-			users.POST("/user", h.getUser)
-			users.POST("/create", h.createUser)
-			users.POST("/update", h.updateUser)
+			users.GET("", admin, h.getUsers)
+			users.GET("/data", bot, h.getTasksUser)
+			users.POST("/user", bot, h.getUser)
+			users.POST("/create", botWrite, h.createUser)
+			users.POST("/update", botWrite, h.updateUser)
+			users.POST("/upsert", botWrite, h.upsertUser)
+			users.POST("/delete", admin, h.deleteUser)
+			users.POST("/deactivate", admin, h.deactivateUser)
+			users.POST("/reactivate", admin, h.reactivateUser)
+			users.POST("/erase", admin, h.eraseUser)
+			users.POST("/export", admin, h.exportUser)
+			users.POST("/status", botWrite, h.setUserStatus)
+			users.POST("/delivery", botWrite, h.reportUserDelivery)
+		}
+
+		tasks := api.Group("/tasks")
+		{
+			tasks.GET("/search", admin, h.searchTasks)
+			tasks.POST("/ingest", ingest, h.ingestTasks)
+		}
+
+		api.POST("/callbacks", botWrite, h.applyCallback)
+
+		feedback := api.Group("/feedback")
+		{
+			feedback.POST("/save", botWrite, h.saveTask)
+			feedback.POST("/saved", bot, h.getSavedTasks)
+			feedback.POST("/not-interested", botWrite, h.markNotInterested)
+			feedback.POST("/suggestions", bot, h.getSuggestions)
+			feedback.POST("/apply", botWrite, h.applyRule)
+		}
+
+		clicks := api.Group("/clicks", admin)
+		{
+			clicks.GET("/categories", h.getCategoryRates)
+			clicks.GET("/sources", h.getSourceRates)
+		}
+
+		stats := api.Group("/stats", admin)
+		{
+			stats.GET("/tasks", h.getTaskStats)
+			stats.GET("/deliveries", h.getDeliveryStats)
+			stats.GET("/subscribers", h.getSubscriberStats)
+			stats.GET("/latency", h.getLatencyStats)
+			stats.GET("/categories", h.getCategoryStats)
+		}
+
+		templates := api.Group("/templates")
+		{
+			templates.GET("/list", bot, h.getTemplates)
+			templates.POST("/create", admin, h.createTemplate)
+			templates.POST("/update", admin, h.updateTemplate)
+			templates.POST("/delete", admin, h.deleteTemplate)
+			templates.POST("/preview", admin, h.previewTemplate)
+		}
+
+		keys := api.Group("/keys", admin)
+		{
+			keys.GET("", h.getApiKeys)
+			keys.POST("/create", h.createApiKey)
+			keys.POST("/revoke", h.revokeApiKey)
+		}
+
+		v2 := api.Group("/v2")
+		{
+			users := v2.Group("/users")
+			{
+				users.POST("", botWrite, h.createUserV2)
+				users.GET("/:tg_id", bot, h.getUserV2)
+				users.PUT("/:tg_id", botWrite, h.replaceUserV2)
+				users.PATCH("/:tg_id", botWrite, h.patchUserV2)
+				users.DELETE("/:tg_id", admin, h.deleteUserV2)
+			}
+
+			channels := v2.Group("/channels")
+			{
+				channels.POST("", botWrite, h.createChannelV2)
+				channels.GET("/:api_id", bot, h.getChannelV2)
+				channels.PUT("/:api_id", botWrite, h.replaceChannelV2)
+				channels.PATCH("/:api_id", botWrite, h.patchChannelV2)
+				channels.DELETE("/:api_id", admin, h.deleteChannelV2)
+			}
 		}
 	}
 
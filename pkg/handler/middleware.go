@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/ratelimit"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	"github.com/max-sanch/BotFreelancer-core/pkg/signing"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	apiKeyCtx       = "apiKey"
+	requestIdCtx    = "requestId"
+	requestIdHeader = "X-Request-Id"
+)
+
+// requestId keeps the X-Request-Id of the client or generates a new one,
+// stores it in the context for error responses and logs, and returns it
+// in the response header.
+func (h *Handler) requestId(c *gin.Context) {
+	id := c.GetHeader(requestIdHeader)
+	if !isValidRequestId(id) {
+		id = newRequestId()
+	}
+
+	c.Set(requestIdCtx, id)
+	c.Header(requestIdHeader, id)
+	c.Next()
+}
+
+// isValidRequestId accepts up to 64 letters, digits, '-', '_' and '.', so
+// a client cannot inject arbitrary text into logs.
+func isValidRequestId(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// authenticate resolves the API key from the "Authorization: Bearer"
+// header, or from the signature headers of a signed request, and stores
+// it in the context for requireScope.
+func (h *Handler) authenticate(c *gin.Context) {
+	if c.GetHeader(signing.HeaderSignature) != "" {
+		h.authenticateSigned(c)
+		return
+	}
+
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		NewErrorResponse(c, http.StatusUnauthorized, "missing api key")
+		return
+	}
+
+	apiKey, err := h.services.ApiKey.Authenticate(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidApiKey) {
+			statusCode = http.StatusUnauthorized
+		}
+
+		NewErrorResponse(c, statusCode, err.Error())
+		return
+	}
+
+	c.Set(apiKeyCtx, apiKey)
+	c.Next()
+}
+
+func (h *Handler) authenticateSigned(c *gin.Context) {
+	keyId, err := strconv.Atoi(c.GetHeader(signing.HeaderKeyId))
+	if err != nil {
+		NewErrorResponse(c, http.StatusUnauthorized, service.ErrInvalidSignature.Error())
+		return
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	apiKey, err := h.services.ApiKey.AuthenticateSigned(core.SignedRequestInput{
+		KeyId:     keyId,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Timestamp: c.GetHeader(signing.HeaderTimestamp),
+		Nonce:     c.GetHeader(signing.HeaderNonce),
+		Signature: c.GetHeader(signing.HeaderSignature),
+		Body:      body,
+	}, time.Now())
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidSignature) || errors.Is(err, service.ErrStaleRequest) ||
+			errors.Is(err, service.ErrReplayedRequest) {
+			statusCode = http.StatusUnauthorized
+		}
+
+		NewErrorResponse(c, statusCode, err.Error())
+		return
+	}
+
+	c.Set(apiKeyCtx, apiKey)
+	c.Next()
+}
+
+// requireScope lets through requests authenticated with a key that grants
+// scope.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(apiKeyCtx)
+		if !ok {
+			NewErrorResponse(c, http.StatusUnauthorized, "missing api key")
+			return
+		}
+
+		if apiKey, ok := value.(core.ApiKey); !ok || !apiKey.HasScope(scope) {
+			NewErrorResponse(c, http.StatusForbidden, "api key lacks the "+scope+" scope")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimit spends a token of the bucket kept for the client and the route
+// and rejects the request with 429 when the bucket is empty.
+func (h *Handler) rateLimit(limits ratelimit.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+
+		client := "bootstrap"
+		if value, ok := c.Get(apiKeyCtx); ok {
+			if apiKey, ok := value.(core.ApiKey); ok && apiKey.Id != 0 {
+				client = "key:" + strconv.Itoa(apiKey.Id)
+			}
+		}
+
+		allowed, retryAfter, err := h.rateStore.Take(client+" "+route, limits.For(route), time.Now())
+		if err != nil {
+			// A broken shared store should not take the API down.
+			logrus.Errorf("error occured while checking rate limit: %s", err.Error())
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			NewErrorResponse(c, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
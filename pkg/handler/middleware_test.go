@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/ratelimit"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+	"github.com/max-sanch/BotFreelancer-core/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_authenticate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockApiKey, key string)
+
+	testTable := []struct {
+		name                string
+		headerValue         string
+		key                 string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "Bot Key",
+			headerValue: "Bearer bfc_bot",
+			key:         "bfc_bot",
+			mockBehavior: func(s *mock_service.MockApiKey, key string) {
+				s.EXPECT().Authenticate(key).Return(core.ApiKey{Id: 1, Name: "bot", Scopes: []string{core.ScopeBotRead}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: "bot",
+		},
+		{
+			name:        "Admin Key",
+			headerValue: "Bearer bfc_admin",
+			key:         "bfc_admin",
+			mockBehavior: func(s *mock_service.MockApiKey, key string) {
+				s.EXPECT().Authenticate(key).Return(core.ApiKey{Name: "bootstrap", Scopes: []string{core.ScopeAdmin}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: "bootstrap",
+		},
+		{
+			name:        "Missing Scope",
+			headerValue: "Bearer bfc_parser",
+			key:         "bfc_parser",
+			mockBehavior: func(s *mock_service.MockApiKey, key string) {
+				s.EXPECT().Authenticate(key).Return(core.ApiKey{Id: 2, Scopes: []string{core.ScopeParserIngest}}, nil)
+			},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"code":"forbidden","message":"api key lacks the bot:read scope"}`,
+		},
+		{
+			name:        "Invalid Key",
+			headerValue: "Bearer bfc_revoked",
+			key:         "bfc_revoked",
+			mockBehavior: func(s *mock_service.MockApiKey, key string) {
+				s.EXPECT().Authenticate(key).Return(core.ApiKey{}, service.ErrInvalidApiKey)
+			},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"code":"unauthorized","message":"invalid api key"}`,
+		},
+		{
+			name:                "No Header",
+			mockBehavior:        func(s *mock_service.MockApiKey, key string) {},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"code":"unauthorized","message":"missing api key"}`,
+		},
+		{
+			name:        "Service Failure",
+			headerValue: "Bearer bfc_bot",
+			key:         "bfc_bot",
+			mockBehavior: func(s *mock_service.MockApiKey, key string) {
+				s.EXPECT().Authenticate(key).Return(core.ApiKey{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKey := mock_service.NewMockApiKey(c)
+			testCase.mockBehavior(apiKey, testCase.key)
+			services := &service.Service{ApiKey: apiKey}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/protected", handler.authenticate, handler.requireScope(core.ScopeBotRead), func(c *gin.Context) {
+				value, _ := c.Get(apiKeyCtx)
+				c.String(200, value.(core.ApiKey).Name)
+			})
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/protected", nil)
+			if testCase.headerValue != "" {
+				req.Header.Set("Authorization", testCase.headerValue)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_authenticateSigned(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockApiKey, input core.SignedRequestInput)
+
+	testTable := []struct {
+		name                string
+		headers             map[string]string
+		input               core.SignedRequestInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			headers: map[string]string{
+				signing.HeaderKeyId:     "2",
+				signing.HeaderTimestamp: "1633089600",
+				signing.HeaderNonce:     "n1",
+				signing.HeaderSignature: "abcd",
+			},
+			input: core.SignedRequestInput{
+				KeyId:     2,
+				Method:    "POST",
+				Path:      "/protected?page=1",
+				Timestamp: "1633089600",
+				Nonce:     "n1",
+				Signature: "abcd",
+				Body:      []byte(`{"tg_id":1}`),
+			},
+			mockBehavior: func(s *mock_service.MockApiKey, input core.SignedRequestInput) {
+				s.EXPECT().AuthenticateSigned(input, gomock.Any()).
+					Return(core.ApiKey{Id: 2, Name: "bot", Scopes: []string{core.ScopeBotRead}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `bot {"tg_id":1}`,
+		},
+		{
+			name: "Replayed",
+			headers: map[string]string{
+				signing.HeaderKeyId:     "2",
+				signing.HeaderTimestamp: "1633089600",
+				signing.HeaderNonce:     "n1",
+				signing.HeaderSignature: "abcd",
+			},
+			input: core.SignedRequestInput{
+				KeyId:     2,
+				Method:    "POST",
+				Path:      "/protected?page=1",
+				Timestamp: "1633089600",
+				Nonce:     "n1",
+				Signature: "abcd",
+				Body:      []byte(`{"tg_id":1}`),
+			},
+			mockBehavior: func(s *mock_service.MockApiKey, input core.SignedRequestInput) {
+				s.EXPECT().AuthenticateSigned(input, gomock.Any()).Return(core.ApiKey{}, service.ErrReplayedRequest)
+			},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"code":"unauthorized","message":"request nonce was already used"}`,
+		},
+		{
+			name: "Bad Key Id",
+			headers: map[string]string{
+				signing.HeaderKeyId:     "bot",
+				signing.HeaderSignature: "abcd",
+			},
+			mockBehavior:        func(s *mock_service.MockApiKey, input core.SignedRequestInput) {},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"code":"unauthorized","message":"invalid request signature"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKey := mock_service.NewMockApiKey(c)
+			testCase.mockBehavior(apiKey, testCase.input)
+			services := &service.Service{ApiKey: apiKey}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/protected", handler.authenticate, handler.requireScope(core.ScopeBotRead), func(c *gin.Context) {
+				value, _ := c.Get(apiKeyCtx)
+				body, _ := ioutil.ReadAll(c.Request.Body)
+				c.String(200, value.(core.ApiKey).Name+" "+string(body))
+			})
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/protected?page=1", bytes.NewBufferString(`{"tg_id":1}`))
+			for key, value := range testCase.headers {
+				req.Header.Set(key, value)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_rateLimit(t *testing.T) {
+	limits := ratelimit.Config{
+		Default: ratelimit.Limit{Rate: 100, Burst: 100},
+		Routes: map[string]ratelimit.Limit{
+			"get /api/channels/data": {Rate: 0.1, Burst: 1},
+		},
+	}
+
+	testTable := []struct {
+		name               string
+		apiKey             core.ApiKey
+		path               string
+		expectedStatusCode int
+		expectedRetryAfter string
+	}{
+		{name: "First", apiKey: core.ApiKey{Id: 1}, path: "/api/channels/data", expectedStatusCode: 200},
+		{name: "Limited", apiKey: core.ApiKey{Id: 1}, path: "/api/channels/data", expectedStatusCode: 429, expectedRetryAfter: "10"},
+		{name: "Other Client", apiKey: core.ApiKey{Id: 2}, path: "/api/channels/data", expectedStatusCode: 200},
+		{name: "Other Route", apiKey: core.ApiKey{Id: 1}, path: "/api/users/data", expectedStatusCode: 200},
+	}
+
+	// Init Deps
+	handler := NewHandler(&service.Service{})
+
+	// Test Server
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var apiKey core.ApiKey
+	api := r.Group("/api", func(c *gin.Context) { c.Set(apiKeyCtx, apiKey) }, handler.rateLimit(limits))
+	api.GET("/channels/data", func(c *gin.Context) { c.Status(200) })
+	api.GET("/users/data", func(c *gin.Context) { c.Status(200) })
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			apiKey = testCase.apiKey
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRetryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestHandler_requestId(t *testing.T) {
+	testTable := []struct {
+		name                string
+		headerValue         string
+		err                 error
+		expectedStatusCode  int
+		expectedRequestId   string
+		expectedRequestBody string
+	}{
+		{
+			name:                "Client Id",
+			headerValue:         "req-1",
+			err:                 service.ErrUserNotFound,
+			expectedStatusCode:  404,
+			expectedRequestId:   "req-1",
+			expectedRequestBody: `{"code":"not_found","message":"user not found","request_id":"req-1"}`,
+		},
+		{
+			name:                "Hidden Internal Error",
+			headerValue:         "req-2",
+			err:                 errors.New(`pq: relation "users" does not exist`),
+			expectedStatusCode:  500,
+			expectedRequestId:   "req-2",
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error","request_id":"req-2"}`,
+		},
+		{
+			name:               "Invalid Client Id",
+			headerValue:        "req 3\n",
+			err:                core.NewError(core.ErrConflict, "settings were changed"),
+			expectedStatusCode: 409,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			handler := NewHandler(&service.Service{})
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/failing", handler.requestId, func(c *gin.Context) {
+				NewErrorResponseFromError(c, testCase.err)
+			})
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/failing", nil)
+			req.Header.Set(requestIdHeader, testCase.headerValue)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+
+			requestId := w.Header().Get(requestIdHeader)
+			if testCase.expectedRequestId != "" {
+				assert.Equal(t, testCase.expectedRequestId, requestId)
+				assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			} else {
+				assert.Equal(t, 32, len(requestId))
+				assert.Equal(t, `{"code":"conflict","message":"settings were changed","request_id":"`+requestId+`"}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,15 +1,66 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
 	"github.com/sirupsen/logrus"
 )
 
+// internalErrorMessage replaces the message of 500 responses, which may
+// carry driver errors. The original message is only logged.
+const internalErrorMessage = "internal server error"
+
+// errorKinds maps domain error kinds to response statuses and codes.
+var errorKinds = []struct {
+	kind       error
+	statusCode int
+	code       string
+}{
+	{core.ErrNotFound, http.StatusNotFound, "not_found"},
+	{core.ErrAlreadyExists, http.StatusConflict, "already_exists"},
+	{core.ErrConflict, http.StatusConflict, "conflict"},
+	{core.ErrInvalidReference, http.StatusUnprocessableEntity, "invalid_reference"},
+	{core.ErrInvalidInput, http.StatusUnprocessableEntity, "invalid_input"},
+	{core.ErrNotConfigured, http.StatusServiceUnavailable, "not_configured"},
+}
+
 type errorResponse struct {
-	Message string `json:"message"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestId string `json:"request_id,omitempty"`
 }
 
+// NewErrorResponse aborts the request with an error whose code is derived
+// from the status, e.g. "not_found" for 404.
 func NewErrorResponse(c *gin.Context, statusCode int, message string) {
-	logrus.Error(message)
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(statusCode)), " ", "_")
+	newErrorResponse(c, statusCode, code, message)
+}
+
+// NewErrorResponseFromError aborts the request with the status and code of
+// the domain error kind of err, or with 500 for other errors.
+func NewErrorResponseFromError(c *gin.Context, err error) {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.kind) {
+			newErrorResponse(c, kind.statusCode, kind.code, err.Error())
+			return
+		}
+	}
+
+	NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
+
+func newErrorResponse(c *gin.Context, statusCode int, code, message string) {
+	requestId := c.GetString(requestIdCtx)
+	logrus.WithField("request_id", requestId).Error(message)
+
+	if statusCode == http.StatusInternalServerError {
+		message = internalErrorMessage
+	}
+
+	c.AbortWithStatusJSON(statusCode, errorResponse{Code: code, Message: message, RequestId: requestId})
 }
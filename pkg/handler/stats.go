@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+const statsFormatCSV = "csv"
+
+func (h *Handler) getTaskStats(c *gin.Context) {
+	var input core.StatsInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	counts, err := h.services.Stats.GetTaskCounts(input.PeriodInput)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	records := make([][]string, 0, len(counts))
+	for _, count := range counts {
+		records = append(records, []string{count.Day, count.Source, count.Category, strconv.Itoa(count.Tasks)})
+	}
+
+	respondStats(c, input.Format, "tasks", counts, []string{"day", "source", "category", "tasks"}, records)
+}
+
+func (h *Handler) getDeliveryStats(c *gin.Context) {
+	var input core.StatsInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	counts, err := h.services.Stats.GetDeliveryCounts(input.PeriodInput)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	records := make([][]string, 0, len(counts))
+	for _, count := range counts {
+		records = append(records, []string{count.Type, strconv.Itoa(count.Key), strconv.Itoa(count.Matches),
+			strconv.Itoa(count.Delivered), strconv.Itoa(count.Failed)})
+	}
+
+	respondStats(c, input.Format, "deliveries", counts,
+		[]string{"type", "key", "matches", "delivered", "failed"}, records)
+}
+
+func (h *Handler) getSubscriberStats(c *gin.Context) {
+	var input core.StatsInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	counts, err := h.services.Stats.GetSubscriberCounts()
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	records := [][]string{{strconv.Itoa(counts.ActiveUsers), strconv.Itoa(counts.Users),
+		strconv.Itoa(counts.ActiveChannels), strconv.Itoa(counts.Channels)}}
+
+	respondStats(c, input.Format, "subscribers", counts,
+		[]string{"active_users", "users", "active_channels", "channels"}, records)
+}
+
+func (h *Handler) getLatencyStats(c *gin.Context) {
+	var input core.StatsInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	latency, err := h.services.Stats.GetDeliveryLatency(input.PeriodInput)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	records := [][]string{{strconv.Itoa(latency.Deliveries),
+		strconv.FormatFloat(latency.MedianSeconds, 'f', -1, 64)}}
+
+	respondStats(c, input.Format, "latency", latency, []string{"deliveries", "median_seconds"}, records)
+}
+
+func (h *Handler) getCategoryStats(c *gin.Context) {
+	var input core.StatsInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	counts, err := h.services.Stats.GetTopCategories(input.PeriodInput, input.Limit)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	records := make([][]string, 0, len(counts))
+	for _, count := range counts {
+		records = append(records, []string{strconv.Itoa(count.CategoryId), count.Category,
+			strconv.Itoa(count.Tasks), strconv.Itoa(count.Matches)})
+	}
+
+	respondStats(c, input.Format, "categories", counts,
+		[]string{"category_id", "category", "tasks", "matches"}, records)
+}
+
+// respondStats writes the statistics as {"<name>": data}, or as a CSV
+// file built from header and records when format is csv.
+func respondStats(c *gin.Context, format, name string, data interface{}, header []string, records [][]string) {
+	if format != statsFormatCSV {
+		c.JSON(http.StatusOK, map[string]interface{}{
+			name: data,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+	if err := w.WriteAll(records); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+name+".csv")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_getTaskStats(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockStats, periodInput core.PeriodInput)
+
+	testTable := []struct {
+		name                string
+		query               string
+		inputPeriod         core.PeriodInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?from=2021-10-01&to=2021-10-31",
+			inputPeriod: core.PeriodInput{
+				From: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockStats, periodInput core.PeriodInput) {
+				s.EXPECT().GetTaskCounts(periodInput).Return([]core.TaskCountResponse{
+					{Day: "2021-10-01", Source: "Habr Freelance", Category: "Backend", Tasks: 12},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"tasks":[{"day":"2021-10-01","source":"Habr Freelance","category":"Backend","tasks":12}]}`,
+		},
+		{
+			name:  "CSV",
+			query: "?format=csv",
+			mockBehavior: func(s *mock_service.MockStats, periodInput core.PeriodInput) {
+				s.EXPECT().GetTaskCounts(periodInput).Return([]core.TaskCountResponse{
+					{Day: "2021-10-01", Source: "Habr Freelance", Category: "Backend, API", Tasks: 12},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: "day,source,category,tasks\n2021-10-01,Habr Freelance,\"Backend, API\",12\n",
+		},
+		{
+			name:                "Unknown Format",
+			query:               "?format=xml",
+			mockBehavior:        func(s *mock_service.MockStats, periodInput core.PeriodInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
+		{
+			name:  "Service Failure",
+			query: "",
+			mockBehavior: func(s *mock_service.MockStats, periodInput core.PeriodInput) {
+				s.EXPECT().GetTaskCounts(periodInput).Return(nil, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			stats := mock_service.NewMockStats(c)
+			testCase.mockBehavior(stats, testCase.inputPeriod)
+			services := &service.Service{Stats: stats}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/tasks", handler.getTaskStats)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/tasks"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+func (h *Handler) searchTasks(c *gin.Context) {
+	var input core.TaskSearchInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	result, err := h.services.Task.Search(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ingestTasks(c *gin.Context) {
+	var input core.TasksInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Task.Ingest(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_searchTasks(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTask, searchInput core.TaskSearchInput)
+
+	testTable := []struct {
+		name                string
+		query               string
+		inputSearch         core.TaskSearchInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?q=golang&category=Backend&min_budget=10000&source=FL.ru&from=2021-10-01&page=2&per_page=1",
+			inputSearch: core.TaskSearchInput{
+				PeriodInput: core.PeriodInput{From: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)},
+				Query:       "golang",
+				Category:    "Backend",
+				MinBudget:   10000,
+				PageInput:   core.PageInput{Page: 2, PerPage: 1},
+				Source:      "FL.ru",
+			},
+			mockBehavior: func(s *mock_service.MockTask, searchInput core.TaskSearchInput) {
+				s.EXPECT().Search(searchInput).Return(core.TaskSearchResponse{
+					Tasks: []core.Task{
+						{Id: 7, FLName: "FL.ru", Url: "https://fl.ru/7", CategoryId: 1, Category: "Backend",
+							Title: "Golang API", Budget: 15000, DateTime: "2021-10-02 10:00:00"},
+					},
+					Total:   3,
+					Page:    2,
+					PerPage: 1,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"tasks":[{"id":7,"fl_name":"FL.ru","fl_url":"","task_url":"https://fl.ru/7",` +
+				`"category_id":1,"category":"Backend","title":"Golang API","description":"","budget":15000,` +
+				`"is_budget_per_hour":false,"term":"","is_safe_deal":false,"datetime":"2021-10-02 10:00:00"}],` +
+				`"total":3,"page":2,"per_page":1}`,
+		},
+		{
+			name:                "Invalid Page",
+			query:               "?q=golang&page=0&per_page=1000",
+			mockBehavior:        func(s *mock_service.MockTask, searchInput core.TaskSearchInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
+		{
+			name:        "Service Failure",
+			query:       "?q=golang",
+			inputSearch: core.TaskSearchInput{Query: "golang"},
+			mockBehavior: func(s *mock_service.MockTask, searchInput core.TaskSearchInput) {
+				s.EXPECT().Search(searchInput).Return(core.TaskSearchResponse{}, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			task := mock_service.NewMockTask(c)
+			testCase.mockBehavior(task, testCase.inputSearch)
+			services := &service.Service{Task: task}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/search", handler.searchTasks)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/search"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ingestTasks(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTask, tasksInput core.TasksInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputTasks          core.TasksInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			inputBody: `{"tasks":[{"fl_name":"fl","fl_url":"fl-url","task_url":"task-url","category":"Go",` +
+				`"title":"title","description":"description","datetime":"2021-10-01 12:00:00"}]}`,
+			inputTasks: core.TasksInput{Tasks: []core.TaskDataInput{{
+				FLName:      "fl",
+				FLUrl:       "fl-url",
+				TaskUrl:     "task-url",
+				Category:    "Go",
+				Title:       "title",
+				Description: "description",
+				DateTime:    "2021-10-01 12:00:00",
+			}}},
+			mockBehavior: func(s *mock_service.MockTask, tasksInput core.TasksInput) {
+				s.EXPECT().Ingest(tasksInput).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:                "Missing Tasks",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockTask, tasksInput core.TasksInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:       "Service Failure",
+			inputBody:  `{"tasks":[]}`,
+			inputTasks: core.TasksInput{Tasks: []core.TaskDataInput{}},
+			mockBehavior: func(s *mock_service.MockTask, tasksInput core.TasksInput) {
+				s.EXPECT().Ingest(tasksInput).Return(errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			task := mock_service.NewMockTask(c)
+			testCase.mockBehavior(task, testCase.inputTasks)
+			services := &service.Service{Task: task}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/ingest", handler.ingestTasks)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/ingest", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
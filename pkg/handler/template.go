@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+)
+
+func (h *Handler) getTemplates(c *gin.Context) {
+	templates, err := h.services.Template.GetAll()
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.TemplatesResponse{
+		Templates: templates,
+	})
+}
+
+func (h *Handler) createTemplate(c *gin.Context) {
+	var input core.TemplateInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Template.Create(input)
+	if err != nil {
+		NewErrorResponse(c, templateErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (h *Handler) updateTemplate(c *gin.Context) {
+	var input core.TemplateUpdateInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Template.Update(input)
+	if err != nil {
+		NewErrorResponse(c, templateErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (h *Handler) deleteTemplate(c *gin.Context) {
+	var input core.TemplateIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Template.Delete(input.Id); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) previewTemplate(c *gin.Context) {
+	var input core.TemplatePreviewInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	body, err := h.services.Template.Preview(input)
+	if err != nil {
+		NewErrorResponse(c, templateErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, core.TemplatePreviewResponse{
+		Body: body,
+	})
+}
+
+func templateErrorStatus(err error) int {
+	if errors.Is(err, render.ErrInvalidTemplate) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
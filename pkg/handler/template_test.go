@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_createTemplate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTemplate, templateInput core.TemplateInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputTemplate       core.TemplateInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"name":"short","body":"{{.Title}}"}`,
+			inputTemplate: core.TemplateInput{
+				Name: "short",
+				Body: "{{.Title}}",
+			},
+			mockBehavior: func(s *mock_service.MockTemplate, templateInput core.TemplateInput) {
+				s.EXPECT().Create(templateInput).Return(1, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{"name":"short"}`,
+			mockBehavior:        func(s *mock_service.MockTemplate, templateInput core.TemplateInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Invalid Template",
+			inputBody: `{"name":"short","body":"{{.Title"}`,
+			inputTemplate: core.TemplateInput{
+				Name: "short",
+				Body: "{{.Title",
+			},
+			mockBehavior: func(s *mock_service.MockTemplate, templateInput core.TemplateInput) {
+				s.EXPECT().Create(templateInput).Return(0, fmt.Errorf("%w: unclosed action", render.ErrInvalidTemplate))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid template: unclosed action"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"name":"short","body":"{{.Title}}"}`,
+			inputTemplate: core.TemplateInput{
+				Name: "short",
+				Body: "{{.Title}}",
+			},
+			mockBehavior: func(s *mock_service.MockTemplate, templateInput core.TemplateInput) {
+				s.EXPECT().Create(templateInput).Return(0, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			template := mock_service.NewMockTemplate(c)
+			testCase.mockBehavior(template, testCase.inputTemplate)
+			services := &service.Service{Template: template}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/createTemplate", handler.createTemplate)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/createTemplate", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_previewTemplate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTemplate, previewInput core.TemplatePreviewInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputPreview        core.TemplatePreviewInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"body":"{{.Title}}"}`,
+			inputPreview: core.TemplatePreviewInput{
+				Body: "{{.Title}}",
+			},
+			mockBehavior: func(s *mock_service.MockTemplate, previewInput core.TemplatePreviewInput) {
+				s.EXPECT().Preview(previewInput).Return("Test", nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"body":"Test"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockTemplate, previewInput core.TemplatePreviewInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Invalid Template",
+			inputBody: `{"body":"{{.Unknown}}"}`,
+			inputPreview: core.TemplatePreviewInput{
+				Body: "{{.Unknown}}",
+			},
+			mockBehavior: func(s *mock_service.MockTemplate, previewInput core.TemplatePreviewInput) {
+				s.EXPECT().Preview(previewInput).Return("", fmt.Errorf("%w: can't evaluate field Unknown", render.ErrInvalidTemplate))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid template: can't evaluate field Unknown"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			template := mock_service.NewMockTemplate(c)
+			testCase.mockBehavior(template, testCase.inputPreview)
+			services := &service.Service{Template: template}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/previewTemplate", handler.previewTemplate)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/previewTemplate", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -1,20 +1,37 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	core "github.com/max-sanch/BotFreelancer-core"
-	"net/http"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
 )
 
 func (h *Handler) getTasksUser(c *gin.Context) {
-	tasks, err := h.services.User.GetTasks()
+	var input core.FeedInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	tasks, err := h.services.User.GetTasks(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	digests, err := h.services.User.GetDigests(input.Format)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, core.UserTasksResponse{
-		Tasks: tasks,
+		Tasks:   tasks,
+		Digests: digests,
 	})
 }
 
@@ -28,10 +45,11 @@ func (h *Handler) getUser(c *gin.Context) {
 
 	user, err := h.services.User.GetByTgId(input.TgId)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, user)
 }
 
@@ -45,7 +63,7 @@ func (h *Handler) createUser(c *gin.Context) {
 
 	id, err := h.services.User.Create(input)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		NewErrorResponseFromError(c, err)
 		return
 	}
 
@@ -54,17 +72,29 @@ func (h *Handler) createUser(c *gin.Context) {
 	})
 }
 
+// updateUser keeps If-Match optional as v1 always did. A stale version
+// fails with 412 when the header was sent and with 409 when only the body
+// carries it.
 func (h *Handler) updateUser(c *gin.Context) {
 	var input core.UserInput
 
+	version, ifMatch, ok := ifMatchVersion(c, false)
+	if !ok {
+		return
+	}
+
 	if err := c.BindJSON(&input); err != nil {
 		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
 		return
 	}
 
+	if version != 0 {
+		input.Version = version
+	}
+
 	id, err := h.services.User.Update(input)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, err.Error())
+		updateErrorResponse(c, err, ifMatch)
 		return
 	}
 
@@ -72,3 +102,163 @@ func (h *Handler) updateUser(c *gin.Context) {
 		"id": id,
 	})
 }
+
+// upsertUser creates the user or updates an existing one and answers 201
+// or 200 accordingly.
+func (h *Handler) upsertUser(c *gin.Context) {
+	var input core.UserInput
+
+	version, ifMatch, ok := ifMatchVersion(c, false)
+	if !ok {
+		return
+	}
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if ifMatch {
+		input.Version = version
+	}
+
+	result, err := h.services.User.Upsert(input)
+	if err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Created {
+		statusCode = http.StatusCreated
+	}
+
+	c.JSON(statusCode, result)
+}
+
+func (h *Handler) getUsers(c *gin.Context) {
+	var input core.UserListInput
+
+	if err := c.BindQuery(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	users, err := h.services.User.GetAll(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *Handler) deleteUser(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	// Deleting a missing user succeeds in v1.
+	if err := h.services.User.Delete(input.TgId); err != nil && !errors.Is(err, service.ErrUserNotFound) {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) deactivateUser(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.User.Deactivate(input.TgId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) reactivateUser(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.User.Reactivate(input.TgId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) eraseUser(c *gin.Context) {
+	var input core.TgIdInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.User.Erase(input.TgId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) setUserStatus(c *gin.Context) {
+	var input core.UserStatusInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.User.SetStatus(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (h *Handler) reportUserDelivery(c *gin.Context) {
+	var input core.UserDeliveryInput
+
+	if err := c.BindJSON(&input); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	status, err := h.services.User.ReportDelivery(input)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, core.RecipientStatusResponse{
+		Status: status,
+	})
+}
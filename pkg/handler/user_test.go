@@ -20,6 +20,7 @@ func TestHandler_getTasksUser(t *testing.T) {
 
 	testTable := []struct {
 		name                string
+		query               string
 		mockBehavior        mockBehavior
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -27,7 +28,7 @@ func TestHandler_getTasksUser(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockUser) {
-				s.EXPECT().GetTasks().Return([]core.UserTaskResponse{
+				s.EXPECT().GetTasks(core.FeedInput{}).Return([]core.UserTaskResponse{
 					{
 						TgId:  1111,
 						Title: "Test",
@@ -35,17 +36,63 @@ func TestHandler_getTasksUser(t *testing.T) {
 						Url:   "TestUrl",
 					},
 				}, nil)
+				s.EXPECT().GetDigests("").Return(nil, nil)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"tasks":[{"tg_id":1111,"title":"Test","body":"TestBody","url":"TestUrl"}]}`,
 		},
+		{
+			name: "With Digests",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetTasks(core.FeedInput{}).Return([]core.UserTaskResponse{}, nil)
+				s.EXPECT().GetDigests("").Return([]core.UserDigestResponse{
+					{
+						DigestId: 3,
+						TgId:     1111,
+						Title:    "Digest",
+						Body:     "DigestBody",
+						Count:    2,
+					},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"tasks":[],"digests":[{"digest_id":3,"tg_id":1111,"title":"Digest","body":"DigestBody","count":2}]}`,
+		},
+		{
+			name:  "HTML Format",
+			query: "?format=html",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetTasks(core.FeedInput{Format: "html"}).Return([]core.UserTaskResponse{
+					{
+						TgId:  1111,
+						Title: "Test",
+						Body:  "TestBody",
+						Url:   "TestUrl",
+						FormattedMessage: core.FormattedMessage{
+							ParseMode: "HTML",
+							Messages:  []string{`<a href="TestUrl">Test</a>`},
+						},
+					},
+				}, nil)
+				s.EXPECT().GetDigests("html").Return(nil, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"tasks":[{"tg_id":1111,"title":"Test","body":"TestBody","url":"TestUrl","parse_mode":"HTML","messages":["\u003ca href=\"TestUrl\"\u003eTest\u003c/a\u003e"]}]}`,
+		},
+		{
+			name:                "Unknown Format",
+			query:               "?format=markdown",
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
 		{
 			name: "Service Failure",
 			mockBehavior: func(s *mock_service.MockUser) {
-				s.EXPECT().GetTasks().Return([]core.UserTaskResponse{}, errors.New("service failure"))
+				s.EXPECT().GetTasks(core.FeedInput{}).Return([]core.UserTaskResponse{}, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -67,7 +114,7 @@ func TestHandler_getTasksUser(t *testing.T) {
 
 			// Test Request
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest("GET", "/getTasksUser", bytes.NewBuffer([]byte{}))
+			req := httptest.NewRequest("GET", "/getTasksUser"+testCase.query, bytes.NewBuffer([]byte{}))
 
 			// Perform Request
 			r.ServeHTTP(w, req)
@@ -101,23 +148,25 @@ func TestHandler_getUser(t *testing.T) {
 					Id:       1,
 					TgId:     1111,
 					Username: "user-1",
+					Status:   "active",
 					Setting: core.SettingResponse{
-						IsSafeDeal: false,
-						IsBudget:   false,
-						IsTerm:     false,
-						Categories: []int{1, 2},
+						IsSafeDeal:   false,
+						IsBudget:     false,
+						IsTerm:       false,
+						Categories:   []int{1, 2},
+						DeliveryMode: "instant",
 					},
 				}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"id":1,"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			expectedRequestBody: `{"id":1,"tg_id":1111,"username":"user-1","status":"active","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2],"delivery_mode":"instant"}}`,
 		},
 		{
 			name:                "Empty Fields",
 			inputBody:           `{}`,
 			mockBehavior:        func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -129,7 +178,7 @@ func TestHandler_getUser(t *testing.T) {
 				s.EXPECT().GetByTgId(tgIdInput.TgId).Return(core.UserResponse{}, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -199,7 +248,7 @@ func TestHandler_createUser(t *testing.T) {
 			inputBody:           `{"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
 			mockBehavior:        func(s *mock_service.MockUser, userInput core.UserInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -218,7 +267,7 @@ func TestHandler_createUser(t *testing.T) {
 				s.EXPECT().Create(userInput).Return(0, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -258,6 +307,7 @@ func TestHandler_updateUser(t *testing.T) {
 
 	testTable := []struct {
 		name                string
+		ifMatch             string
 		inputBody           string
 		inputUser           core.UserInput
 		mockBehavior        mockBehavior
@@ -283,12 +333,53 @@ func TestHandler_updateUser(t *testing.T) {
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"id":1}`,
 		},
+		{
+			name:      "Stale Version",
+			ifMatch:   `"2"`,
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputUser: core.UserInput{
+				TgId:     1111,
+				Username: "user-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+				Version: 2,
+			},
+			mockBehavior: func(s *mock_service.MockUser, userInput core.UserInput) {
+				s.EXPECT().Update(userInput).Return(0, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:      "Stale Body Version",
+			inputBody: `{"tg_id":1111,"username":"user-1","version":2,"setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputUser: core.UserInput{
+				TgId:     1111,
+				Username: "user-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+				Version: 2,
+			},
+			mockBehavior: func(s *mock_service.MockUser, userInput core.UserInput) {
+				s.EXPECT().Update(userInput).Return(0, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"conflict","message":"record was changed concurrently"}`,
+		},
 		{
 			name:                "Empty Fields",
 			inputBody:           `{"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
 			mockBehavior:        func(s *mock_service.MockUser, userInput core.UserInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -307,7 +398,7 @@ func TestHandler_updateUser(t *testing.T) {
 				s.EXPECT().Update(userInput).Return(0, errors.New("service failure"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service failure"}`,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
 		},
 	}
 
@@ -330,6 +421,538 @@ func TestHandler_updateUser(t *testing.T) {
 			// Test Request
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("POST", "/updateUser", bytes.NewBufferString(testCase.inputBody))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_upsertUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, userInput core.UserInput)
+	isFalse := false
+
+	testTable := []struct {
+		name                string
+		ifMatch             string
+		inputBody           string
+		inputUser           core.UserInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "Created",
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputUser: core.UserInput{
+				TgId:     1111,
+				Username: "user-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockUser, userInput core.UserInput) {
+				s.EXPECT().Upsert(userInput).Return(core.UpsertResponse{Id: 1, Created: true}, nil)
+			},
+			expectedStatusCode:  201,
+			expectedRequestBody: `{"id":1,"created":true}`,
+		},
+		{
+			name:      "Updated",
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputUser: core.UserInput{
+				TgId:     1111,
+				Username: "user-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockUser, userInput core.UserInput) {
+				s.EXPECT().Upsert(userInput).Return(core.UpsertResponse{Id: 1}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"created":false}`,
+		},
+		{
+			name:      "Stale If-Match",
+			ifMatch:   `"2"`,
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			inputUser: core.UserInput{
+				TgId:     1111,
+				Username: "user-1",
+				Setting: core.SettingInput{
+					IsSafeDeal: &isFalse,
+					IsBudget:   &isFalse,
+					IsTerm:     &isFalse,
+					Categories: []int{1, 2},
+				},
+				Version: 2,
+			},
+			mockBehavior: func(s *mock_service.MockUser, userInput core.UserInput) {
+				s.EXPECT().Upsert(userInput).Return(core.UpsertResponse{}, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1,2]}}`,
+			mockBehavior:        func(s *mock_service.MockUser, userInput core.UserInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.inputUser)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/upsertUser", handler.upsertUser)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/upsertUser", bytes.NewBufferString(testCase.inputBody))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_reportUserDelivery(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, deliveryInput core.UserDeliveryInput)
+	isFalse := false
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputDelivery       core.UserDeliveryInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"url":"test-url","is_delivered":false,"is_permanent":true,"reason":"blocked"}`,
+			inputDelivery: core.UserDeliveryInput{
+				TgId: 1111,
+				DeliveryInput: core.DeliveryInput{
+					Url:         "test-url",
+					IsDelivered: &isFalse,
+					IsPermanent: true,
+					Reason:      "blocked",
+				},
+			},
+			mockBehavior: func(s *mock_service.MockUser, deliveryInput core.UserDeliveryInput) {
+				s.EXPECT().ReportDelivery(deliveryInput).Return("blocked", nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"blocked"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{"tg_id":1111,"url":"test-url"}`,
+			mockBehavior:        func(s *mock_service.MockUser, deliveryInput core.UserDeliveryInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Unknown User",
+			inputBody: `{"tg_id":1111,"url":"test-url","is_delivered":false}`,
+			inputDelivery: core.UserDeliveryInput{
+				TgId: 1111,
+				DeliveryInput: core.DeliveryInput{
+					Url:         "test-url",
+					IsDelivered: &isFalse,
+				},
+			},
+			mockBehavior: func(s *mock_service.MockUser, deliveryInput core.UserDeliveryInput) {
+				s.EXPECT().ReportDelivery(deliveryInput).Return("", service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"tg_id":1111,"url":"test-url","is_delivered":false}`,
+			inputDelivery: core.UserDeliveryInput{
+				TgId: 1111,
+				DeliveryInput: core.DeliveryInput{
+					Url:         "test-url",
+					IsDelivered: &isFalse,
+				},
+			},
+			mockBehavior: func(s *mock_service.MockUser, deliveryInput core.UserDeliveryInput) {
+				s.EXPECT().ReportDelivery(deliveryInput).Return("", errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.inputDelivery)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/reportUserDelivery", handler.reportUserDelivery)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reportUserDelivery", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_setUserStatus(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, statusInput core.UserStatusInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputStatus         core.UserStatusInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"status":"blocked"}`,
+			inputStatus: core.UserStatusInput{
+				TgId:        1111,
+				StatusInput: core.StatusInput{Status: "blocked"},
+			},
+			mockBehavior: func(s *mock_service.MockUser, statusInput core.UserStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Unknown User",
+			inputBody: `{"tg_id":1111,"status":"blocked"}`,
+			inputStatus: core.UserStatusInput{
+				TgId:        1111,
+				StatusInput: core.StatusInput{Status: "blocked"},
+			},
+			mockBehavior: func(s *mock_service.MockUser, statusInput core.UserStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:      "Deactivated User",
+			inputBody: `{"tg_id":1111,"status":"active"}`,
+			inputStatus: core.UserStatusInput{
+				TgId:        1111,
+				StatusInput: core.StatusInput{Status: "active"},
+			},
+			mockBehavior: func(s *mock_service.MockUser, statusInput core.UserStatusInput) {
+				s.EXPECT().SetStatus(statusInput).Return(core.NewError(core.ErrConflict, "recipient is inactive"))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"conflict","message":"recipient is inactive"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.inputStatus)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/setUserStatus", handler.setUserStatus)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/setUserStatus", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_deactivateUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, tgId int)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		tgId                int
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111}`,
+			tgId:      1111,
+			mockBehavior: func(s *mock_service.MockUser, tgId int) {
+				s.EXPECT().Deactivate(tgId).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Unknown User",
+			inputBody: `{"tg_id":1111}`,
+			tgId:      1111,
+			mockBehavior: func(s *mock_service.MockUser, tgId int) {
+				s.EXPECT().Deactivate(tgId).Return(service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.tgId)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/deactivateUser", handler.deactivateUser)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/deactivateUser", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getUsers(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, listInput core.UserListInput)
+
+	testTable := []struct {
+		name                string
+		query               string
+		inputList           core.UserListInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?status=inactive&per_page=10",
+			inputList: core.UserListInput{
+				PageInput: core.PageInput{PerPage: 10},
+				Status:    "inactive",
+			},
+			mockBehavior: func(s *mock_service.MockUser, listInput core.UserListInput) {
+				s.EXPECT().GetAll(listInput).Return(core.UserListResponse{
+					Users: []core.UserListItemResponse{
+						{TgId: 1111, Username: "test", Status: "inactive"},
+					},
+					Total:   1,
+					Page:    1,
+					PerPage: 10,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"users":[{"tg_id":1111,"username":"test","status":"inactive"}],` +
+				`"total":1,"page":1,"per_page":10}`,
+		},
+		{
+			name:                "Invalid Page Size",
+			query:               "?per_page=1000",
+			mockBehavior:        func(s *mock_service.MockUser, listInput core.UserListInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid query params"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.inputList)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/users", handler.getUsers)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/users"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_eraseUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser, tgIdInput core.TgIdInput)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputTgId           core.TgIdInput
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111}`,
+			inputTgId: core.TgIdInput{
+				TgId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {
+				s.EXPECT().Erase(tgIdInput.TgId).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"tg_id":1111}`,
+			inputTgId: core.TgIdInput{
+				TgId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {
+				s.EXPECT().Erase(tgIdInput.TgId).Return(service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:      "No Erasure Key",
+			inputBody: `{"tg_id":1111}`,
+			inputTgId: core.TgIdInput{
+				TgId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {
+				s.EXPECT().Erase(tgIdInput.TgId).Return(service.ErrNoErasureKey)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"code":"not_configured","message":"erasure key is not configured"}`,
+		},
+		{
+			name:                "Empty Fields",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"tg_id":1111}`,
+			inputTgId: core.TgIdInput{
+				TgId: 1111,
+			},
+			mockBehavior: func(s *mock_service.MockUser, tgIdInput core.TgIdInput) {
+				s.EXPECT().Erase(tgIdInput.TgId).Return(errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user, testCase.inputTgId)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/eraseUser", handler.eraseUser)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/eraseUser", bytes.NewBufferString(testCase.inputBody))
 
 			// Perform Request
 			r.ServeHTTP(w, req)
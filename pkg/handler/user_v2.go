@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// bindJSONV2 binds the body and answers 400 for malformed JSON and 422 for
+// JSON that fails validation. It reports whether binding succeeded.
+func bindJSONV2(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid input body")
+	} else {
+		newErrorResponse(c, http.StatusUnprocessableEntity, "invalid_input", err.Error())
+	}
+
+	return false
+}
+
+// bindTgId binds the tg_id path parameter and answers 400 when it is not a
+// number.
+func bindTgId(c *gin.Context) (int, bool) {
+	var uri core.TgIdUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid path params")
+		return 0, false
+	}
+
+	return uri.TgId, true
+}
+
+func (h *Handler) createUserV2(c *gin.Context) {
+	var input core.UserInput
+
+	if !bindJSONV2(c, &input) {
+		return
+	}
+
+	if _, err := h.services.User.Create(input); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	user, err := h.services.User.GetByTgId(input.TgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v2/users/"+strconv.Itoa(input.TgId))
+	setETag(c, user.Version)
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *Handler) getUserV2(c *gin.Context) {
+	tgId, ok := bindTgId(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.services.User.GetByTgId(tgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) replaceUserV2(c *gin.Context) {
+	var input core.UserBody
+
+	tgId, ok := bindTgId(c)
+	if !ok {
+		return
+	}
+
+	version, _, ok := ifMatchVersion(c, true)
+	if !ok || !bindJSONV2(c, &input) {
+		return
+	}
+	input.Version = version
+
+	if _, err := h.services.User.Update(input.Input(tgId)); err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	user, err := h.services.User.GetByTgId(tgId)
+	if err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) patchUserV2(c *gin.Context) {
+	var input core.UserPatchInput
+
+	tgId, ok := bindTgId(c)
+	if !ok {
+		return
+	}
+
+	version, _, ok := ifMatchVersion(c, true)
+	if !ok || !bindJSONV2(c, &input) {
+		return
+	}
+	input.Version = version
+
+	user, err := h.services.User.Patch(tgId, input)
+	if err != nil {
+		updateErrorResponse(c, err, true)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) deleteUserV2(c *gin.Context) {
+	tgId, ok := bindTgId(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.User.Delete(tgId); err != nil {
+		NewErrorResponseFromError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+	mock_service "github.com/max-sanch/BotFreelancer-core/pkg/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestHandler_createUserV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser)
+
+	isFalse := false
+	input := core.UserInput{
+		TgId:     1111,
+		Username: "user-1",
+		Setting: core.SettingInput{
+			IsSafeDeal: &isFalse,
+			IsBudget:   &isFalse,
+			IsTerm:     &isFalse,
+			Categories: []int{1},
+		},
+	}
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedRequestBody  string
+		expectedLocationPath string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Create(input).Return(1, nil)
+				s.EXPECT().GetByTgId(1111).Return(core.UserResponse{Id: 1, TgId: 1111, Username: "user-1"}, nil)
+			},
+			expectedStatusCode:   201,
+			expectedRequestBody:  `{"id":1,"tg_id":1111,"username":"user-1","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""}}`,
+			expectedLocationPath: "/api/v2/users/1111",
+		},
+		{
+			name:      "Already Exists",
+			inputBody: `{"tg_id":1111,"username":"user-1","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":[1]}}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Create(input).Return(0, service.ErrUserExists)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"already_exists","message":"user already exists"}`,
+		},
+		{
+			name:                "Malformed Body",
+			inputBody:           `{"tg_id":`,
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/users", handler.createUserV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/users", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedLocationPath, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHandler_getUserV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser)
+
+	testTable := []struct {
+		name                string
+		path                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			path: "/users/1111",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetByTgId(1111).Return(core.UserResponse{Id: 1, TgId: 1111, Username: "user-1"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"tg_id":1111,"username":"user-1","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""}}`,
+		},
+		{
+			name: "Not Found",
+			path: "/users/1111",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetByTgId(1111).Return(core.UserResponse{}, service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:                "Invalid Path",
+			path:                "/users/abc",
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid path params"}`,
+		},
+		{
+			name: "Service Failure",
+			path: "/users/1111",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().GetByTgId(1111).Return(core.UserResponse{}, errors.New("service failure"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_server_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/users/:tg_id", handler.getUserV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, bytes.NewBuffer([]byte{}))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_patchUserV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser)
+
+	username := "user-2"
+	patch := core.UserPatchInput{Username: &username, Version: 1}
+
+	testTable := []struct {
+		name                string
+		ifMatch             string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			ifMatch:   `"1"`,
+			inputBody: `{"username":"user-2"}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Patch(1111, patch).
+					Return(core.UserResponse{Id: 1, TgId: 1111, Username: "user-2"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"tg_id":1111,"username":"user-2","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""}}`,
+		},
+		{
+			name:                "Malformed Body",
+			ifMatch:             `"1"`,
+			inputBody:           `{"username":1}`,
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:                "Invalid Field",
+			ifMatch:             `"1"`,
+			inputBody:           `{"language":"de"}`,
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"invalid_input","message":"Key: 'UserPatchInput.Language' Error:Field validation for 'Language' failed on the 'oneof' tag"}`,
+		},
+		{
+			name:      "Clear Quiet Hours",
+			ifMatch:   `"1"`,
+			inputBody: `{"clear_quiet_hours":true,"setting":{"clear_template":true}}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Patch(1111, core.UserPatchInput{
+					ClearQuietHours: true,
+					Setting:         &core.SettingPatchInput{ClearTemplate: true},
+					Version:         1,
+				}).Return(core.UserResponse{Id: 1, TgId: 1111, Username: "user-2"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"tg_id":1111,"username":"user-2","status":"","setting":{"is_safe_deal":false,"is_budget":false,"is_term":false,"categories":null,"delivery_mode":""}}`,
+		},
+		{
+			name:                "Set And Clear",
+			ifMatch:             `"1"`,
+			inputBody:           `{"quiet_hours":{"from":"23:00","to":"08:00"},"clear_quiet_hours":true}`,
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"invalid_input","message":"Key: 'UserPatchInput.ClearQuietHours' Error:Field validation for 'ClearQuietHours' failed on the 'excluded_with' tag"}`,
+		},
+		{
+			name:      "Invalid Input",
+			ifMatch:   `"1"`,
+			inputBody: `{"username":"user-2"}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Patch(1111, patch).
+					Return(core.UserResponse{}, fmt.Errorf("%w: unknown category", service.ErrInvalidInput))
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"invalid_input","message":"invalid input: unknown category"}`,
+		},
+		{
+			name:      "Not Found",
+			ifMatch:   `"1"`,
+			inputBody: `{"username":"user-2"}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Patch(1111, patch).
+					Return(core.UserResponse{}, service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+		{
+			name:      "Stale Version",
+			ifMatch:   `"1"`,
+			inputBody: `{"username":"user-2"}`,
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Patch(1111, patch).Return(core.UserResponse{}, core.NewError(core.ErrConflict, "record was changed concurrently"))
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"precondition_failed","message":"If-Match does not match the current version"}`,
+		},
+		{
+			name:                "Missing If-Match",
+			inputBody:           `{"username":"user-2"}`,
+			mockBehavior:        func(s *mock_service.MockUser) {},
+			expectedStatusCode:  428,
+			expectedRequestBody: `{"code":"precondition_required","message":"If-Match header is required"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PATCH("/users/:tg_id", handler.patchUserV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/users/1111", bytes.NewBufferString(testCase.inputBody))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_deleteUserV2(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUser)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Delete(1111).Return(nil)
+			},
+			expectedStatusCode: 204,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func(s *mock_service.MockUser) {
+				s.EXPECT().Delete(1111).Return(service.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"not_found","message":"user not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			user := mock_service.NewMockUser(c)
+			testCase.mockBehavior(user)
+			services := &service.Service{User: user}
+			handler := NewHandler(services)
+
+			// Test Server
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.DELETE("/users/:tg_id", handler.deleteUserV2)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/users/1111", bytes.NewBuffer([]byte{}))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
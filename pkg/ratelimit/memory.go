@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// fullAt is when the bucket is refilled and can be forgotten.
+	fullAt time.Time
+}
+
+// MemoryStore keeps token buckets in process memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	nextPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	if limit.Rate <= 0 {
+		return true, 0, nil
+	}
+
+	burst := math.Max(float64(limit.Burst), 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		s.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*limit.Rate)
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, wait, nil
+	}
+
+	b.tokens--
+	b.fullAt = now.Add(time.Duration((burst - b.tokens) / limit.Rate * float64(time.Second)))
+
+	return true, 0, nil
+}
+
+// prune forgets the buckets that are full again, at most once a minute.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	s.nextPrune = now.Add(time.Minute)
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Rate: 0.5, Burst: 2}
+
+	type take struct {
+		key        string
+		at         time.Duration
+		want       bool
+		retryAfter time.Duration
+	}
+
+	takes := []take{
+		{key: "bot GET /api/channels/data", at: 0, want: true},
+		{key: "bot GET /api/channels/data", at: 0, want: true},
+		{key: "bot GET /api/channels/data", at: 0, want: false, retryAfter: 2 * time.Second},
+		{key: "other GET /api/channels/data", at: 0, want: true},
+		{key: "bot GET /api/channels/data", at: time.Second, want: false, retryAfter: time.Second},
+		{key: "bot GET /api/channels/data", at: 2 * time.Second, want: true},
+		{key: "bot GET /api/channels/data", at: 2 * time.Second, want: false, retryAfter: 2 * time.Second},
+		{key: "bot GET /api/channels/data", at: time.Hour, want: true},
+		{key: "bot GET /api/channels/data", at: time.Hour, want: true},
+	}
+
+	s := NewMemoryStore()
+	for i, tc := range takes {
+		got, retryAfter, err := s.Take(tc.key, limit, now.Add(tc.at))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, got, "take %d", i)
+		assert.Equal(t, tc.retryAfter, retryAfter, "take %d", i)
+	}
+}
+
+func TestMemoryStore_TakeUnlimited(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 100; i++ {
+		got, _, err := s.Take("bot", Limit{}, now)
+		assert.NoError(t, err)
+		assert.True(t, got)
+	}
+}
+
+func TestConfig_For(t *testing.T) {
+	config := Config{
+		Default: Limit{Rate: 10, Burst: 20},
+		Routes: map[string]Limit{
+			"get /api/channels/data": {Rate: 0.2, Burst: 2},
+		},
+	}
+
+	assert.Equal(t, Limit{Rate: 0.2, Burst: 2}, config.For("GET /api/channels/data"))
+	assert.Equal(t, Limit{Rate: 10, Burst: 20}, config.For("GET /api/users/data"))
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket that holds up to Burst requests and is refilled
+// at Rate requests per second. A zero Rate means no limit.
+type Limit struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// Store keeps the token buckets. The in-memory store limits a single
+// instance, a shared store lets several instances share the quotas.
+type Store interface {
+	// Take removes a token from the bucket at key. When the bucket is
+	// empty it returns false and the time until a token is available.
+	Take(key string, limit Limit, now time.Time) (bool, time.Duration, error)
+}
+
+// Config holds the limit for every route along with the default one.
+// Routes are keyed by method and path pattern, e.g. "GET /api/users/data".
+type Config struct {
+	Default Limit            `mapstructure:"default"`
+	Routes  map[string]Limit `mapstructure:"routes"`
+}
+
+// For returns the limit of route. Route keys are compared case
+// insensitively, as configuration keys are lowercased when loaded.
+func (c Config) For(route string) Limit {
+	for key, limit := range c.Routes {
+		if strings.EqualFold(key, route) {
+			return limit
+		}
+	}
+
+	return c.Default
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// Output formats of task messages. Markdown and HTML map to the Telegram
+// MarkdownV2 and HTML parse modes.
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdownv2"
+	FormatHTML     = "html"
+)
+
+const (
+	// MaxMessageLength is the Telegram limit for the text of a message.
+	MaxMessageLength = 4096
+	// MaxMessages caps how many messages a single task is split into. The
+	// text that does not fit is cut with an ellipsis.
+	MaxMessages = 3
+
+	maxTitleLength = 256
+	ellipsis       = "…"
+)
+
+const markdownSpecial = "_*[]()~`>#+-=|{}.!\\"
+
+// ParseMode returns the Telegram parse_mode for format, or an empty string
+// for plain text.
+func ParseMode(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return "MarkdownV2"
+	case FormatHTML:
+		return "HTML"
+	default:
+		return ""
+	}
+}
+
+// Messages composes a task message in format and splits it into texts that
+// fit into a Telegram message. The title links to url; in plain text the
+// url follows the title. Parts are only cut between whole characters of
+// the source text, so escape sequences and entities are never broken, and
+// a cut is moved back to a line break or a space when there is one nearby.
+func Messages(format, title, body, url string) []string {
+	title = truncate(maxTitleLength, title)
+
+	units := []string{header(format, title, url)}
+	if body != "" {
+		units = append(units, "\n", "\n")
+		for _, r := range body {
+			units = append(units, escapeRune(format, r))
+		}
+	}
+
+	return split(units, MaxMessageLength, MaxMessages)
+}
+
+// Escape escapes text for format.
+func Escape(format, text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		escaped.WriteString(escapeRune(format, r))
+	}
+
+	return escaped.String()
+}
+
+func header(format, title, url string) string {
+	if url == "" {
+		return Escape(format, title)
+	}
+
+	switch format {
+	case FormatMarkdown:
+		escapedUrl := strings.NewReplacer(`\`, `\\`, `)`, `\)`).Replace(url)
+		return "[" + Escape(format, title) + "](" + escapedUrl + ")"
+	case FormatHTML:
+		escapedUrl := strings.ReplaceAll(Escape(format, url), `"`, "&quot;")
+		return `<a href="` + escapedUrl + `">` + Escape(format, title) + "</a>"
+	default:
+		return title + "\n" + url
+	}
+}
+
+func escapeRune(format string, r rune) string {
+	switch format {
+	case FormatMarkdown:
+		if strings.ContainsRune(markdownSpecial, r) {
+			return `\` + string(r)
+		}
+	case FormatHTML:
+		switch r {
+		case '&':
+			return "&amp;"
+		case '<':
+			return "&lt;"
+		case '>':
+			return "&gt;"
+		}
+	}
+
+	return string(r)
+}
+
+// split joins units into parts of at most limit characters without
+// splitting a unit. The last allowed part is cut with an ellipsis.
+func split(units []string, limit, maxParts int) []string {
+	var parts, current []string
+	size := 0
+
+	for i := 0; i < len(units); i++ {
+		unitSize := textLength(units[i])
+		if size+unitSize <= limit || len(current) == 0 {
+			current = append(current, units[i])
+			size += unitSize
+			continue
+		}
+
+		if len(parts) == maxParts-1 {
+			current = trimTrailingSpace(current[:breakPoint(current)])
+			size = unitsLength(current)
+			for len(current) > 1 && size+textLength(ellipsis) > limit {
+				size -= textLength(current[len(current)-1])
+				current = current[:len(current)-1]
+			}
+			return append(parts, strings.Join(current, "")+ellipsis)
+		}
+
+		cut := breakPoint(current)
+		parts = append(parts, strings.Join(trimTrailingSpace(current[:cut]), ""))
+
+		current = trimLeadingSpace(current[cut:])
+		size = unitsLength(current)
+		i--
+	}
+
+	if len(current) > 0 {
+		parts = append(parts, strings.Join(trimTrailingSpace(current), ""))
+	}
+
+	return parts
+}
+
+// breakPoint returns where to cut units: after the last line break or,
+// failing that, the last space in the second half of the part.
+func breakPoint(units []string) int {
+	for _, separator := range []string{"\n", " "} {
+		for i := len(units) - 1; i >= len(units)/2; i-- {
+			if units[i] == separator {
+				return i + 1
+			}
+		}
+	}
+
+	return len(units)
+}
+
+func trimLeadingSpace(units []string) []string {
+	for len(units) > 0 && isSpace(units[0]) {
+		units = units[1:]
+	}
+
+	return append([]string(nil), units...)
+}
+
+func trimTrailingSpace(units []string) []string {
+	for len(units) > 0 && isSpace(units[len(units)-1]) {
+		units = units[:len(units)-1]
+	}
+
+	return units
+}
+
+func isSpace(unit string) bool {
+	return unit == "\n" || unit == " "
+}
+
+func unitsLength(units []string) int {
+	length := 0
+	for _, unit := range units {
+		length += textLength(unit)
+	}
+
+	return length
+}
+
+// textLength counts characters the way Telegram does, in UTF-16 code units.
+func textLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
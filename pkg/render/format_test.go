@@ -0,0 +1,83 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessages(t *testing.T) {
+	testTable := []struct {
+		name   string
+		format string
+		title  string
+		body   string
+		url    string
+		want   []string
+	}{
+		{
+			name:   "Plain",
+			format: FormatPlain,
+			title:  "Bot (Go)",
+			body:   "1 < 2!",
+			url:    "https://fl.ru/1",
+			want:   []string{"Bot (Go)\nhttps://fl.ru/1\n\n1 < 2!"},
+		},
+		{
+			name:   "MarkdownV2",
+			format: FormatMarkdown,
+			title:  "Bot (Go)",
+			body:   "Budget: 1.000 - 2.000!",
+			url:    "https://fl.ru/projects/(1)",
+			want:   []string{`[Bot \(Go\)](https://fl.ru/projects/(1\))` + "\n\n" + `Budget: 1\.000 \- 2\.000\!`},
+		},
+		{
+			name:   "HTML",
+			format: FormatHTML,
+			title:  "Bot <Go>",
+			body:   "A & B",
+			url:    `https://fl.ru/?a=1&b="2"`,
+			want:   []string{`<a href="https://fl.ru/?a=1&amp;b=&quot;2&quot;">Bot &lt;Go&gt;</a>` + "\n\nA &amp; B"},
+		},
+		{
+			name:   "Without Url",
+			format: FormatMarkdown,
+			title:  "Digest: 2",
+			body:   "1. Task",
+			want:   []string{`Digest: 2` + "\n\n" + `1\. Task`},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := Messages(testCase.format, testCase.title, testCase.body, testCase.url)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestMessages_Split(t *testing.T) {
+	line := strings.Repeat("a.", 500) + "\n"
+	body := strings.Repeat(line, 5)
+
+	got := Messages(FormatMarkdown, "Title", body, "https://fl.ru/1")
+
+	assert.Equal(t, 3, len(got))
+	for _, message := range got {
+		assert.LessOrEqual(t, textLength(message), MaxMessageLength)
+		assert.False(t, strings.HasSuffix(message, `\`), "escape sequence is broken")
+	}
+	assert.True(t, strings.HasPrefix(got[1], "a"), "part should start at a line break")
+}
+
+func TestMessages_Truncate(t *testing.T) {
+	body := strings.Repeat("<b> ", MaxMessageLength)
+
+	got := Messages(FormatHTML, "Title", body, "")
+
+	assert.Equal(t, MaxMessages, len(got))
+	last := got[len(got)-1]
+	assert.True(t, strings.HasSuffix(last, "&lt;b&gt;"+ellipsis), "entity is broken: %q", last[len(last)-12:])
+	assert.LessOrEqual(t, textLength(last), MaxMessageLength)
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+}
+
+// localeFuncs returns the template helpers bound to language.
+func localeFuncs(language string) template.FuncMap {
+	return template.FuncMap{
+		"tr": func(key string) string {
+			return T(language, key)
+		},
+		"money": func(amount int) string {
+			return Money(language, amount)
+		},
+		"date": func(datetime string) string {
+			return Date(language, datetime)
+		},
+		"reltime": func(datetime string) string {
+			return RelativeTime(language, datetime, time.Now())
+		},
+		"truncate": truncate,
+	}
+}
+
+// ParseDateTime reads the publication time as sent by the parser. Times
+// without a zone are treated as UTC.
+func ParseDateTime(datetime string) (time.Time, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, datetime); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown datetime format %q", datetime)
+}
+
+// Money formats an amount in roubles with thousands grouped the way
+// language expects: 15 000 ₽ or 15,000 ₽.
+func Money(language string, amount int) string {
+	return groupDigits(amount, getLocale(language).thousandsSeparator) + " ₽"
+}
+
+// Date formats the publication time for language. Values that cannot be
+// parsed are returned unchanged.
+func Date(language, datetime string) string {
+	t, err := ParseDateTime(datetime)
+	if err != nil {
+		return datetime
+	}
+
+	return t.Format(getLocale(language).dateTimeLayout)
+}
+
+// RelativeTime describes how long before now datetime was: "5 минут назад".
+func RelativeTime(language, datetime string, now time.Time) string {
+	t, err := ParseDateTime(datetime)
+	if err != nil {
+		return datetime
+	}
+
+	elapsed := now.Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return T(language, "just_now")
+	case elapsed < time.Hour:
+		return ago(language, int(elapsed/time.Minute), "minutes")
+	case elapsed < 24*time.Hour:
+		return ago(language, int(elapsed/time.Hour), "hours")
+	default:
+		return ago(language, int(elapsed/(24*time.Hour)), "days")
+	}
+}
+
+func ago(language string, n int, unit string) string {
+	forms := strings.Split(T(language, unit), "|")
+	return fmt.Sprintf(T(language, "ago"), n, getLocale(language).plural(n, forms))
+}
+
+func groupDigits(n int, separator string) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+
+	return sign + strings.Join(groups, separator)
+}
+
+// truncate shortens text to at most length characters, marking the cut
+// with an ellipsis. It is written to be used in pipelines:
+// {{.Description | truncate 200}}.
+func truncate(length int, text string) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	if length < 1 {
+		return ""
+	}
+
+	return strings.TrimRight(string(runes[:length-1]), " \n") + "…"
+}
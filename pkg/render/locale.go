@@ -0,0 +1,145 @@
+package render
+
+// DefaultLanguage is used for recipients without a language and for keys
+// that are missing from other catalogs.
+const DefaultLanguage = "ru"
+
+type locale struct {
+	thousandsSeparator string
+	dateTimeLayout     string
+	plural             func(n int, forms []string) string
+}
+
+var locales = map[string]locale{
+	"ru": {thousandsSeparator: " ", dateTimeLayout: "02.01.2006 15:04", plural: slavicPlural},
+	"en": {thousandsSeparator: ",", dateTimeLayout: "Jan 2, 2006 15:04", plural: englishPlural},
+	"uk": {thousandsSeparator: " ", dateTimeLayout: "02.01.2006 15:04", plural: slavicPlural},
+}
+
+// catalogs hold the labels used by the default template, digests and
+// task buttons. Plural forms are separated by "|": one|few|many for ru
+// and uk and one|other for en.
+var catalogs = map[string]map[string]string{
+	"ru": {
+		"task_from":       "Заказ с",
+		"category":        "Категория",
+		"description":     "Описание",
+		"safe_deal":       "Безопасная сделка!",
+		"budget":          "Бюджет",
+		"budget_per_hour": "в час",
+		"budget_unknown":  "не указан",
+		"term":            "Сроки",
+		"term_unknown":    "не указаны",
+		"published":       "Время публикации",
+		"just_now":        "только что",
+		"ago":             "%d %s назад",
+		"minutes":         "минуту|минуты|минут",
+		"hours":           "час|часа|часов",
+		"days":            "день|дня|дней",
+		"digest_title":    "Подборка заказов: %d",
+		"digest_more":     "…и ещё %d",
+		"quiet_title":     "Заказы за время тишины: %d",
+		"button_open":     "Открыть заказ",
+		"button_hide":     "Не интересно",
+		"button_mute":     "Скрыть категорию",
+		"button_save":     "Сохранить",
+	},
+	"en": {
+		"task_from":       "Task from",
+		"category":        "Category",
+		"description":     "Description",
+		"safe_deal":       "Safe deal!",
+		"budget":          "Budget",
+		"budget_per_hour": "per hour",
+		"budget_unknown":  "not specified",
+		"term":            "Term",
+		"term_unknown":    "not specified",
+		"published":       "Published",
+		"just_now":        "just now",
+		"ago":             "%d %s ago",
+		"minutes":         "minute|minutes",
+		"hours":           "hour|hours",
+		"days":            "day|days",
+		"digest_title":    "Task digest: %d",
+		"digest_more":     "…and %d more",
+		"quiet_title":     "Tasks during quiet hours: %d",
+		"button_open":     "Open task",
+		"button_hide":     "Not interested",
+		"button_mute":     "Hide category",
+		"button_save":     "Save",
+	},
+	"uk": {
+		"task_from":       "Замовлення з",
+		"category":        "Категорія",
+		"description":     "Опис",
+		"safe_deal":       "Безпечна угода!",
+		"budget":          "Бюджет",
+		"budget_per_hour": "за годину",
+		"budget_unknown":  "не вказано",
+		"term":            "Терміни",
+		"term_unknown":    "не вказані",
+		"published":       "Час публікації",
+		"just_now":        "щойно",
+		"ago":             "%d %s тому",
+		"minutes":         "хвилину|хвилини|хвилин",
+		"hours":           "годину|години|годин",
+		"days":            "день|дні|днів",
+		"digest_title":    "Добірка замовлень: %d",
+		"digest_more":     "…і ще %d",
+		"quiet_title":     "Замовлення за час тиші: %d",
+		"button_open":     "Відкрити замовлення",
+		"button_hide":     "Не цікаво",
+		"button_mute":     "Приховати категорію",
+		"button_save":     "Зберегти",
+	},
+}
+
+// T returns the label for key in language, falling back to Russian when
+// the language or the key is unknown.
+func T(language, key string) string {
+	if message, ok := catalogs[language][key]; ok {
+		return message
+	}
+
+	if message, ok := catalogs[DefaultLanguage][key]; ok {
+		return message
+	}
+
+	return key
+}
+
+func getLocale(language string) locale {
+	if l, ok := locales[language]; ok {
+		return l
+	}
+
+	return locales[DefaultLanguage]
+}
+
+func slavicPlural(n int, forms []string) string {
+	if len(forms) < 3 {
+		return forms[len(forms)-1]
+	}
+
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return forms[2]
+	}
+
+	switch n % 10 {
+	case 1:
+		return forms[0]
+	case 2, 3, 4:
+		return forms[1]
+	default:
+		return forms[2]
+	}
+}
+
+func englishPlural(n int, forms []string) string {
+	if n == 1 || len(forms) < 2 {
+		return forms[0]
+	}
+
+	return forms[1]
+}
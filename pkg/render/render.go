@@ -0,0 +1,80 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// ErrInvalidTemplate is returned when a template does not compile or fails
+// to render a task.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// DefaultTemplate is used for recipients without a template of their own.
+// Labels come from the recipient's message catalog.
+const DefaultTemplate = `{{tr "task_from"}} {{.FLName}}
+
+{{tr "category"}}: {{.Category}}
+
+{{tr "description"}}:
+{{.Description}}
+
+{{if .IsSafeDeal}}{{tr "safe_deal"}}
+{{end}}{{tr "budget"}}: {{if .Budget}}{{money .Budget}}{{if .IsBudgetPerHour}} {{tr "budget_per_hour"}}{{end}}{{else}}{{tr "budget_unknown"}}{{end}}
+{{tr "term"}}: {{if .Term}}{{.Term}}{{else}}{{tr "term_unknown"}}{{end}}
+{{tr "published"}}: {{date .DateTime}}`
+
+// Parse compiles a message template with the helper functions available.
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(localeFuncs(DefaultLanguage)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err.Error())
+	}
+
+	return tmpl, nil
+}
+
+// Execute renders task with tmpl, formatting labels, money and dates for
+// language.
+func Execute(tmpl *template.Template, task core.Task, language string) (string, error) {
+	localized, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+
+	if err := localized.Funcs(localeFuncs(language)).Execute(&body, task); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTemplate, err.Error())
+	}
+
+	return body.String(), nil
+}
+
+// Validate checks that text compiles and renders a sample task.
+func Validate(text string) error {
+	tmpl, err := Parse("validate", text)
+	if err != nil {
+		return err
+	}
+
+	_, err = Execute(tmpl, SampleTask, DefaultLanguage)
+	return err
+}
+
+// SampleTask is rendered when a template is previewed without a task.
+var SampleTask = core.Task{
+	FLName:      "FL.ru",
+	FLUrl:       "https://www.fl.ru",
+	Url:         "https://www.fl.ru/projects/1/",
+	Category:    "Программирование",
+	Title:       "Доработать Telegram-бота на Go",
+	Description: "Нужно добавить команду поиска заказов и покрыть её тестами.",
+	Budget:      15000,
+	Term:        "7 дней",
+	IsSafeDeal:  true,
+	DateTime:    "2022-04-20 12:00:00",
+}
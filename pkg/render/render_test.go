@@ -0,0 +1,128 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute(t *testing.T) {
+	task := core.Task{
+		FLName:      "FL.ru",
+		Category:    "Программирование",
+		Description: "Описание заказа",
+		Budget:      15000,
+		IsSafeDeal:  true,
+		DateTime:    "2022-04-20 12:00:00",
+	}
+
+	testTable := []struct {
+		name     string
+		text     string
+		language string
+		task     core.Task
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Default",
+			text:     DefaultTemplate,
+			language: "ru",
+			task:     task,
+			want: "Заказ с FL.ru\n\nКатегория: Программирование\n\nОписание:\nОписание заказа\n\n" +
+				"Безопасная сделка!\nБюджет: 15 000 ₽\nСроки: не указаны\nВремя публикации: 20.04.2022 12:00",
+		},
+		{
+			name:     "Default English",
+			text:     DefaultTemplate,
+			language: "en",
+			task:     task,
+			want: "Task from FL.ru\n\nCategory: Программирование\n\nDescription:\nОписание заказа\n\n" +
+				"Safe deal!\nBudget: 15,000 ₽\nTerm: not specified\nPublished: Apr 20, 2022 12:00",
+		},
+		{
+			name:     "Unknown Language",
+			text:     "{{tr \"budget\"}}: {{money .Budget}}",
+			language: "de",
+			task:     task,
+			want:     "Бюджет: 15 000 ₽",
+		},
+		{
+			name: "Truncate",
+			text: "{{.Title | truncate 8}}",
+			task: core.Task{Title: "Доработать бота"},
+			want: "Доработ…",
+		},
+		{
+			name:    "Unknown Field",
+			text:    "{{.Unknown}}",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpl, err := Parse(testCase.name, testCase.text)
+			assert.NoError(t, err)
+
+			got, err := Execute(tmpl, testCase.task, testCase.language)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTemplate)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("{{.Title}} {{money .Budget}} {{reltime .DateTime}}"))
+	assert.ErrorIs(t, Validate("{{.Title"), ErrInvalidTemplate)
+	assert.ErrorIs(t, Validate("{{unknown .Title}}"), ErrInvalidTemplate)
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2022, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name     string
+		language string
+		datetime string
+		want     string
+	}{
+		{
+			name:     "Russian Minutes",
+			language: "ru",
+			datetime: "2022-04-20 11:55:00",
+			want:     "5 минут назад",
+		},
+		{
+			name:     "Ukrainian Hours",
+			language: "uk",
+			datetime: "2022-04-20 09:00:00",
+			want:     "3 години тому",
+		},
+		{
+			name:     "English Day",
+			language: "en",
+			datetime: "2022-04-19 11:00:00",
+			want:     "1 day ago",
+		},
+		{
+			name:     "Unknown Format",
+			language: "en",
+			datetime: "yesterday",
+			want:     "yesterday",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, RelativeTime(testCase.language, testCase.datetime, now))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ApiKeyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewApiKeyPostgres(db *sqlx.DB) *ApiKeyPostgres {
+	return &ApiKeyPostgres{db: db}
+}
+
+// apiKeyRow is an API key with its scopes stored space separated.
+type apiKeyRow struct {
+	Id         int        `db:"id"`
+	Name       string     `db:"name"`
+	Scopes     string     `db:"scopes"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (r *ApiKeyPostgres) Create(name, keyHash, signingSecret string, scopes []string) (int, error) {
+	var id int
+
+	query := fmt.Sprintf("INSERT INTO %s (name, key_hash, signing_secret, scopes) VALUES ($1, $2, $3, $4) RETURNING id;",
+		apiKeysTable)
+	row := r.db.QueryRow(query, name, keyHash, signingSecret, strings.Join(scopes, " "))
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Authenticate returns the key with the given hash unless it is revoked
+// and marks it as used. It returns sql.ErrNoRows for unknown keys.
+func (r *ApiKeyPostgres) Authenticate(keyHash string) (core.ApiKey, error) {
+	var row apiKeyRow
+
+	query := fmt.Sprintf(`UPDATE %s SET last_used_at = now() WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING id, name, scopes, created_at, last_used_at, revoked_at;`, apiKeysTable)
+	if err := r.db.Get(&row, query, keyHash); err != nil {
+		return core.ApiKey{}, err
+	}
+
+	return core.ApiKey{Id: row.Id, Name: row.Name, Scopes: strings.Fields(row.Scopes)}, nil
+}
+
+// GetSigningKey returns an active key that has a signing secret along with
+// the sealed secret. It returns sql.ErrNoRows for other keys.
+func (r *ApiKeyPostgres) GetSigningKey(id int) (core.ApiKey, string, error) {
+	var row struct {
+		apiKeyRow
+		SigningSecret string `db:"signing_secret"`
+	}
+
+	query := fmt.Sprintf(`SELECT id, name, scopes, created_at, last_used_at, revoked_at, signing_secret FROM %s
+		WHERE id = $1 AND revoked_at IS NULL AND signing_secret != '';`, apiKeysTable)
+	if err := r.db.Get(&row, query, id); err != nil {
+		return core.ApiKey{}, "", err
+	}
+
+	return core.ApiKey{Id: row.Id, Name: row.Name, Scopes: strings.Fields(row.Scopes)}, row.SigningSecret, nil
+}
+
+func (r *ApiKeyPostgres) MarkUsed(id int) error {
+	query := fmt.Sprintf("UPDATE %s SET last_used_at = now() WHERE id = $1;", apiKeysTable)
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *ApiKeyPostgres) GetAllSigningSecrets() ([]core.ApiKeySecret, error) {
+	var secrets []core.ApiKeySecret
+
+	query := fmt.Sprintf("SELECT id, signing_secret FROM %s WHERE signing_secret != '' ORDER BY id;", apiKeysTable)
+	if err := r.db.Select(&secrets, query); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// ReplaceSigningSecret swaps the stored secret only if it still equals
+// oldSecret and reports whether it was replaced.
+func (r *ApiKeyPostgres) ReplaceSigningSecret(id int, oldSecret, newSecret string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET signing_secret = $1 WHERE id = $2 AND signing_secret = $3;", apiKeysTable)
+
+	result, err := r.db.Exec(query, newSecret, id, oldSecret)
+	if err != nil {
+		return false, err
+	}
+
+	replaced, err := result.RowsAffected()
+	return replaced > 0, err
+}
+
+func (r *ApiKeyPostgres) GetAll() ([]core.ApiKeyResponse, error) {
+	var rows []apiKeyRow
+
+	query := fmt.Sprintf("SELECT id, name, scopes, created_at, last_used_at, revoked_at FROM %s ORDER BY id;",
+		apiKeysTable)
+	if err := r.db.Select(&rows, query); err != nil {
+		return nil, err
+	}
+
+	keys := make([]core.ApiKeyResponse, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, core.ApiKeyResponse{
+			Id:         row.Id,
+			Name:       row.Name,
+			Scopes:     strings.Fields(row.Scopes),
+			CreatedAt:  row.CreatedAt,
+			LastUsedAt: row.LastUsedAt,
+			RevokedAt:  row.RevokedAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// Revoke disables the key. It returns sql.ErrNoRows when there is no
+// active key with the id.
+func (r *ApiKeyPostgres) Revoke(id int) error {
+	var revokedId int
+
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL RETURNING id;",
+		apiKeysTable)
+
+	row := r.db.QueryRow(query, id)
+	return row.Scan(&revokedId)
+}
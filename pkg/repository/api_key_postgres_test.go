@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestApiKeyPostgres_Authenticate(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewApiKeyPostgres(db)
+	createdAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+	testTable := []struct {
+		name         string
+		keyHash      string
+		mockBehavior func(keyHash string)
+		want         core.ApiKey
+		wantErr      bool
+	}{
+		{
+			name:    "OK",
+			keyHash: "hash",
+			mockBehavior: func(keyHash string) {
+				rows := sqlmock.NewRows(columns).AddRow(1, "bot", "bot:read parser:ingest", createdAt, createdAt, nil)
+				mock.ExpectQuery("UPDATE api_keys SET last_used_at = now\\(\\) WHERE key_hash = (.+) AND revoked_at IS NULL").
+					WithArgs(keyHash).WillReturnRows(rows)
+			},
+			want: core.ApiKey{Id: 1, Name: "bot", Scopes: []string{core.ScopeBotRead, core.ScopeParserIngest}},
+		},
+		{
+			name:    "Unknown Or Revoked",
+			keyHash: "hash",
+			mockBehavior: func(keyHash string) {
+				mock.ExpectQuery("UPDATE api_keys SET last_used_at = now\\(\\) WHERE key_hash = (.+) AND revoked_at IS NULL").
+					WithArgs(keyHash).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.keyHash)
+
+			got, err := r.Authenticate(testCase.keyHash)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestApiKeyPostgres_GetSigningKey(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewApiKeyPostgres(db)
+	createdAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "scopes", "created_at", "last_used_at", "revoked_at", "signing_secret"}
+
+	testTable := []struct {
+		name         string
+		id           int
+		mockBehavior func(id int)
+		want         core.ApiKey
+		wantSecret   string
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			id:   2,
+			mockBehavior: func(id int) {
+				rows := sqlmock.NewRows(columns).AddRow(2, "parser", "parser:ingest", createdAt, nil, nil, "enc1.k1.key.value")
+				mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE id = (.+) AND revoked_at IS NULL AND signing_secret != ''").
+					WithArgs(id).WillReturnRows(rows)
+			},
+			want:       core.ApiKey{Id: 2, Name: "parser", Scopes: []string{core.ScopeParserIngest}},
+			wantSecret: "enc1.k1.key.value",
+		},
+		{
+			name: "Not Found",
+			id:   2,
+			mockBehavior: func(id int) {
+				mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE id = (.+) AND revoked_at IS NULL AND signing_secret != ''").
+					WithArgs(id).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.id)
+
+			got, gotSecret, err := r.GetSigningKey(testCase.id)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+				assert.Equal(t, testCase.wantSecret, gotSecret)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
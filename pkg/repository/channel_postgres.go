@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	core "github.com/max-sanch/BotFreelancer-core"
 )
@@ -14,19 +18,23 @@ func NewChannelPostgres(db *sqlx.DB) *ChannelPostgres {
 	return &ChannelPostgres{db: db}
 }
 
-func (r *ChannelPostgres) GetByApiId(apiId int) (core.ChannelResponse, error) {
-	var channel core.ChannelResponse
+// GetByApiId returns the channel with settings or core.ErrNotFound.
+func (r *ChannelPostgres) GetByApiId(apiId int) (channel core.ChannelResponse, err error) {
+	defer func() { err = translateError(err) }()
+
 	var settingId int
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE api_id = $1", channelsTable)
+	query := fmt.Sprintf("SELECT id, api_id, name, status, version FROM %s WHERE api_id = $1", channelsTable)
 	if err := r.db.Get(&channel, query, apiId); err != nil {
 		return core.ChannelResponse{}, err
 	}
 
-	query = fmt.Sprintf("SELECT id, is_safe_deal, is_budget, is_term FROM %s WHERE channel_id = $1",
-		channelSettingsTable)
+	query = fmt.Sprintf(`SELECT id, is_safe_deal, is_budget, is_term, delivery_mode, digest_time, template_id,
+		timezone, language FROM %s WHERE channel_id = $1`, channelSettingsTable)
 	row := r.db.QueryRow(query, channel.Id)
-	if err := row.Scan(&settingId, &channel.Setting.IsSafeDeal, &channel.Setting.IsBudget, &channel.Setting.IsTerm); err != nil {
+	if err := row.Scan(&settingId, &channel.Setting.IsSafeDeal, &channel.Setting.IsBudget, &channel.Setting.IsTerm,
+		&channel.Setting.DeliveryMode, &channel.Setting.DigestTime, &channel.Setting.TemplateId,
+		&channel.Timezone, &channel.Language); err != nil {
 		return core.ChannelResponse{}, err
 	}
 
@@ -38,13 +46,22 @@ func (r *ChannelPostgres) GetByApiId(apiId int) (core.ChannelResponse, error) {
 	return channel, nil
 }
 
-func (r *ChannelPostgres) Create(channelInput core.ChannelInput) (int, error) {
+// Create stores a new channel. It returns core.ErrAlreadyExists for a
+// taken api_id and core.ErrInvalidReference for unknown categories or
+// templates.
+func (r *ChannelPostgres) Create(channelInput core.ChannelInput) (_ int, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(channelInput.Setting); err != nil {
+		return 0, err
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return 0, err
 	}
 
-	var channelId, channelSettingId int
+	var channelId int
 	createChannelQuery := fmt.Sprintf("INSERT INTO %s (api_id, api_hash, name) VALUES ($1, $2, $3) RETURNING id;",
 		channelsTable)
 
@@ -56,103 +73,276 @@ func (r *ChannelPostgres) Create(channelInput core.ChannelInput) (int, error) {
 		return 0, err
 	}
 
-	createChannelSettingQuery := fmt.Sprintf("INSERT INTO %s (channel_id, is_safe_deal, is_budget, is_term) VALUES ($1, $2, $3, $4) RETURNING id;",
-		channelSettingsTable)
-
-	if channelInput.Setting.IsSafeDeal == nil || channelInput.Setting.IsBudget == nil || channelInput.Setting.IsTerm == nil {
+	if err := saveChannelSetting(tx, channelId, channelInput, false); err != nil {
 		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
+		return 0, err
 	}
 
-	row = tx.QueryRow(createChannelSettingQuery, channelId, *channelInput.Setting.IsSafeDeal,
-		*channelInput.Setting.IsBudget, *channelInput.Setting.IsTerm)
-	if err := row.Scan(&channelSettingId); err != nil {
+	return channelId, tx.Commit()
+}
+
+// Update replaces the settings of a channel and increments its version.
+// An empty api_hash keeps the stored one. It returns core.ErrNotFound for
+// an unknown api_id, core.ErrConflict when channelInput.Version is set and
+// no longer current, and core.ErrInvalidReference for unknown categories or
+// templates.
+func (r *ChannelPostgres) Update(channelInput core.ChannelInput) (_ int, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(channelInput.Setting); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var channelId int
+	updateChannelQuery := fmt.Sprintf(`UPDATE %s SET api_hash = COALESCE(NULLIF($1, ''), api_hash), name = $2,
+		version = version + 1 WHERE api_id = $3 AND ($4 = 0 OR version = $4) RETURNING id;`, channelsTable)
+
+	row := tx.QueryRow(updateChannelQuery, channelInput.ApiHash, channelInput.Name, channelInput.ApiId,
+		channelInput.Version)
+	if err := row.Scan(&channelId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = staleRecipientError(tx, channelsTable, "api_id", channelInput.ApiId, channelInput.Version)
+		}
 		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
 		return 0, err
 	}
 
-	for _, categoryId := range channelInput.Setting.Categories {
-		createChannelCategoryQuery := fmt.Sprintf("INSERT INTO %s (channel_setting_id, category_id) VALUES ($1, $2);",
-			channelCategoriesTable)
-
-		if _, err := tx.Exec(createChannelCategoryQuery, channelSettingId, categoryId); err != nil {
-			if err := tx.Rollback(); err != nil {
-				return 0, err
-			}
+	if err := saveChannelSetting(tx, channelId, channelInput, true); err != nil {
+		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
+		return 0, err
 	}
 
 	return channelId, tx.Commit()
 }
 
-func (r *ChannelPostgres) Update(channelInput core.ChannelInput) (int, error) {
+// Upsert creates the channel or replaces the settings of an existing one
+// and reports whether the channel was created. An empty api_hash keeps the
+// stored one and a deleted channel is restored. It returns
+// core.ErrConflict when channelInput.Version is set and no longer current
+// or the channel does not exist, core.ErrAlreadyExists when the name
+// belongs to another channel, and core.ErrInvalidReference for unknown
+// categories or templates.
+func (r *ChannelPostgres) Upsert(channelInput core.ChannelInput) (_ int, created bool, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(channelInput.Setting); err != nil {
+		return 0, false, err
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
 
-	var channelId, channelSettingId int
-	updateChannelQuery := fmt.Sprintf("UPDATE %s SET api_hash = $1, name = $2 WHERE api_id = $3 RETURNING id;",
-		channelsTable)
+	var channelId int
+	// xmax is 0 only for rows inserted by the statement.
+	upsertChannelQuery := fmt.Sprintf(`INSERT INTO %[1]s (api_id, api_hash, name) VALUES ($1, $2, $3)
+		ON CONFLICT (api_id) DO UPDATE SET api_hash = COALESCE(NULLIF(EXCLUDED.api_hash, ''), %[1]s.api_hash),
+		name = EXCLUDED.name, version = %[1]s.version + 1,
+		status = CASE WHEN %[1]s.status = '%[2]s' THEN '%[3]s' ELSE %[1]s.status END,
+		failures = CASE WHEN %[1]s.status = '%[2]s' THEN 0 ELSE %[1]s.failures END, deleted_at = NULL
+		WHERE $4 = 0 OR %[1]s.version = $4 RETURNING id, xmax = 0;`, channelsTable, core.StatusDeleted, core.StatusActive)
 
-	row := tx.QueryRow(updateChannelQuery, channelInput.ApiHash, channelInput.Name, channelInput.ApiId)
-	if err := row.Scan(&channelId); err != nil {
+	row := tx.QueryRow(upsertChannelQuery, channelInput.ApiId, channelInput.ApiHash, channelInput.Name,
+		channelInput.Version)
+	if err := row.Scan(&channelId, &created); err != nil {
+		// Only a stale version skips the row of an existing channel.
+		if errors.Is(err, sql.ErrNoRows) {
+			err = typedError(core.ErrConflict, nil)
+		}
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
-		return 0, err
+		return 0, false, err
 	}
 
-	updateChannelSettingQuery := fmt.Sprintf("UPDATE %s SET is_safe_deal = $1, is_budget = $2, is_term = $3 WHERE channel_id = $4 RETURNING id;",
-		channelSettingsTable)
-
-	if channelInput.Setting.IsSafeDeal == nil || channelInput.Setting.IsBudget == nil || channelInput.Setting.IsTerm == nil {
+	// A version can only match an existing channel.
+	if created && channelInput.Version != 0 {
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
+		return 0, false, typedError(core.ErrConflict, nil)
 	}
 
-	row = tx.QueryRow(updateChannelSettingQuery, *channelInput.Setting.IsSafeDeal, *channelInput.Setting.IsBudget, *channelInput.Setting.IsTerm, channelId)
-	if err := row.Scan(&channelSettingId); err != nil {
+	if err := saveChannelSetting(tx, channelId, channelInput, !created); err != nil {
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
-		return 0, err
+		return 0, false, err
 	}
 
-	deleteChannelCategoryQuery := fmt.Sprintf("DELETE FROM %s WHERE channel_setting_id = $1;", channelCategoriesTable)
+	return channelId, created, tx.Commit()
+}
 
-	if _, err := tx.Exec(deleteChannelCategoryQuery, channelSettingId); err != nil {
-		if err := tx.Rollback(); err != nil {
-			return 0, err
-		}
-		return 0, err
+// saveChannelSetting stores the settings and categories of the channel.
+// With replace the existing settings and categories are overwritten.
+func saveChannelSetting(tx *sql.Tx, channelId int, channelInput core.ChannelInput, replace bool) error {
+	var channelSettingId int
+	saveChannelSettingQuery := fmt.Sprintf(`INSERT INTO %s (channel_id, is_safe_deal, is_budget, is_term,
+		delivery_mode, digest_time, template_id, timezone, language) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (channel_id) DO UPDATE SET is_safe_deal = EXCLUDED.is_safe_deal, is_budget = EXCLUDED.is_budget,
+		is_term = EXCLUDED.is_term, delivery_mode = EXCLUDED.delivery_mode, digest_time = EXCLUDED.digest_time,
+		template_id = EXCLUDED.template_id, timezone = EXCLUDED.timezone, language = EXCLUDED.language
+		RETURNING id;`,
+		channelSettingsTable)
+
+	row := tx.QueryRow(saveChannelSettingQuery, channelId, *channelInput.Setting.IsSafeDeal,
+		*channelInput.Setting.IsBudget, *channelInput.Setting.IsTerm, channelInput.Setting.DeliveryMode,
+		channelInput.Setting.DigestTime, channelInput.Setting.TemplateId, channelInput.Timezone, channelInput.Language)
+	if err := row.Scan(&channelSettingId); err != nil {
+		return err
 	}
 
-	for _, categoryId := range channelInput.Setting.Categories {
-		createChannelCategoryQuery := fmt.Sprintf("INSERT INTO %s (channel_setting_id, category_id) VALUES ($1, $2);",
-			channelCategoriesTable)
+	return saveRecipientCategories(tx, channelCategoriesTable, "channel_setting_id", channelSettingId,
+		channelInput.Setting.Categories, replace)
+}
 
-		if _, err := tx.Exec(createChannelCategoryQuery, channelSettingId, categoryId); err != nil {
-			if err := tx.Rollback(); err != nil {
-				return 0, err
-			}
-			return 0, err
-		}
+// channelListRow is a listed channel along with the number of all
+// channels matching the filter.
+type channelListRow struct {
+	core.ChannelListItemResponse
+	Total int `db:"total"`
+}
+
+// GetAll returns a page of channels ordered by id, optionally only those
+// with the given status.
+func (r *ChannelPostgres) GetAll(status string, limit, offset int) ([]core.ChannelListItemResponse, int, error) {
+	var rows []channelListRow
+
+	query := fmt.Sprintf(`SELECT api_id, name, status, deleted_at, count(*) OVER () AS total FROM %s
+		WHERE $1 = '' OR status = $1 ORDER BY id LIMIT $2 OFFSET $3;`, channelsTable)
+
+	if err := r.db.Select(&rows, query, status, limit, offset); err != nil {
+		return nil, 0, err
 	}
 
-	return channelId, tx.Commit()
+	channels := make([]core.ChannelListItemResponse, 0, len(rows))
+	total := 0
+	for _, row := range rows {
+		channels = append(channels, row.ChannelListItemResponse)
+		total = row.Total
+	}
+
+	return channels, total, nil
+}
+
+// SoftDelete marks the channel as deleted, which stops its deliveries
+// until it is restored or purged. It returns core.ErrNotFound when there
+// is no channel to delete.
+func (r *ChannelPostgres) SoftDelete(apiId int) error {
+	var id int
+
+	query := fmt.Sprintf(`UPDATE %s SET status = '%s', deleted_at = now(), version = version + 1
+		WHERE api_id = $1 AND status != '%s' RETURNING id;`, channelsTable, core.StatusDeleted, core.StatusDeleted)
+
+	row := r.db.QueryRow(query, apiId)
+	return translateError(row.Scan(&id))
 }
 
-func (r *ChannelPostgres) Delete(apiId int) error {
+// Restore reactivates a channel deleted after the given time. It returns
+// core.ErrNotFound when there is no such channel.
+func (r *ChannelPostgres) Restore(apiId int, deletedAfter time.Time) error {
+	var id int
+
+	query := fmt.Sprintf(`UPDATE %s SET status = '%s', failures = 0, deleted_at = NULL, version = version + 1
+		WHERE api_id = $1 AND status = '%s' AND deleted_at >= $2 RETURNING id;`,
+		channelsTable, core.StatusActive, core.StatusDeleted)
+
+	row := r.db.QueryRow(query, apiId, deletedAfter)
+	return translateError(row.Scan(&id))
+}
+
+// PurgeDeleted removes channels deleted before the given time along with
+// their settings and history.
+func (r *ChannelPostgres) PurgeDeleted(deletedBefore time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE status = '%s' AND deleted_at < $1;", channelsTable, core.StatusDeleted)
+
+	result, err := r.db.Exec(query, deletedBefore)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	count, err := result.RowsAffected()
+	return count, translateError(err)
+}
+
+// Delete removes the channel with its settings and history. It returns
+// core.ErrNotFound when there is no such channel.
+func (r *ChannelPostgres) Delete(apiId int) (err error) {
+	defer func() { err = translateError(err) }()
+
 	query := fmt.Sprintf("DELETE FROM %s WHERE api_id = $1;", channelsTable)
 
-	if _, err := r.db.Exec(query, apiId); err != nil {
+	result, err := r.db.Exec(query, apiId)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
 		return err
 	}
+
+	if count == 0 {
+		return core.NewError(core.ErrNotFound, "channel not found")
+	}
+
 	return nil
 }
+
+// GetCredentials returns the stored, sealed api_hash of the channel or
+// core.ErrNotFound.
+func (r *ChannelPostgres) GetCredentials(apiId int) (core.ChannelCredentialsResponse, error) {
+	var credentials core.ChannelCredentialsResponse
+
+	query := fmt.Sprintf("SELECT api_id, api_hash FROM %s WHERE api_id = $1;", channelsTable)
+	err := r.db.Get(&credentials, query, apiId)
+
+	return credentials, translateError(err)
+}
+
+// GetAllCredentials returns the stored api_hash of every channel.
+func (r *ChannelPostgres) GetAllCredentials() ([]core.ChannelCredentialsResponse, error) {
+	var credentials []core.ChannelCredentialsResponse
+
+	query := fmt.Sprintf("SELECT api_id, api_hash FROM %s ORDER BY id;", channelsTable)
+	if err := r.db.Select(&credentials, query); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// ReplaceCredentials swaps the stored api_hash only if it still equals
+// oldApiHash, so a concurrent update of the channel is not overwritten.
+// It reports whether the value was replaced.
+func (r *ChannelPostgres) ReplaceCredentials(apiId int, oldApiHash, newApiHash string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET api_hash = $1 WHERE api_id = $2 AND api_hash = $3;", channelsTable)
+
+	result, err := r.db.Exec(query, newApiHash, apiId, oldApiHash)
+	if err != nil {
+		return false, err
+	}
+
+	replaced, err := result.RowsAffected()
+	return replaced > 0, err
+}
+
+func (r *ChannelPostgres) SetStatus(apiId int, status string) error {
+	return setRecipientStatus(r.db, channelsTable, "api_id", apiId, status)
+}
+
+func (r *ChannelPostgres) ReportDelivery(apiId int, delivery core.DeliveryInput, maxFailures int) (string, error) {
+	return reportRecipientDelivery(r.db, channelDigestRecipient, apiId, delivery, maxFailures)
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	core "github.com/max-sanch/BotFreelancer-core"
 
@@ -19,6 +20,7 @@ func TestChannelPostgres_GetByApiId(t *testing.T) {
 	defer db.Close()
 
 	r := NewChannelPostgres(db)
+	templateId := 2
 
 	type args struct {
 		apiId int
@@ -39,14 +41,15 @@ func TestChannelPostgres_GetByApiId(t *testing.T) {
 				apiId: 1111,
 			},
 			mockBehavior: func(args args) {
-				rows := sqlmock.NewRows([]string{"id", "api_id", "api_hash", "name"}).
-					AddRow(1, 1111, "hash1111", "channel-1")
+				rows := sqlmock.NewRows([]string{"id", "api_id", "name", "status"}).
+					AddRow(1, 1111, "channel-1", "active")
 
 				mock.ExpectQuery("SELECT (.+) FROM channels WHERE (.+)").
 					WithArgs(args.apiId).WillReturnRows(rows)
 
-				rows = sqlmock.NewRows([]string{"id", "is_safe_deal", "is_budget", "is_term"}).
-					AddRow(1, true, true, true)
+				rows = sqlmock.NewRows([]string{"id", "is_safe_deal", "is_budget", "is_term",
+					"delivery_mode", "digest_time", "template_id", "timezone", "language"}).
+					AddRow(1, true, true, true, "daily", "09:00", 2, "Europe/Kiev", "uk")
 
 				mock.ExpectQuery("SELECT (.+) FROM channel_settings WHERE (.+)").
 					WithArgs(1).WillReturnRows(rows)
@@ -57,15 +60,20 @@ func TestChannelPostgres_GetByApiId(t *testing.T) {
 					WithArgs(1).WillReturnRows(rows)
 			},
 			want: core.ChannelResponse{
-				Id:      1,
-				ApiId:   1111,
-				ApiHash: "hash1111",
-				Name:    "channel-1",
+				Id:       1,
+				ApiId:    1111,
+				Name:     "channel-1",
+				Status:   "active",
+				Timezone: "Europe/Kiev",
+				Language: "uk",
 				Setting: core.SettingResponse{
-					IsSafeDeal: true,
-					IsBudget:   true,
-					IsTerm:     true,
-					Categories: []int{1, 2},
+					IsSafeDeal:   true,
+					IsBudget:     true,
+					IsTerm:       true,
+					Categories:   []int{1, 2},
+					DeliveryMode: "daily",
+					DigestTime:   "09:00",
+					TemplateId:   &templateId,
 				},
 			},
 		},
@@ -75,7 +83,7 @@ func TestChannelPostgres_GetByApiId(t *testing.T) {
 				apiId: 1111,
 			},
 			mockBehavior: func(args args) {
-				rows := sqlmock.NewRows([]string{"id", "api_id", "api_hash", "name"})
+				rows := sqlmock.NewRows([]string{"id", "api_id", "name", "status"})
 
 				mock.ExpectQuery("SELECT (.+) FROM channels WHERE (.+)").
 					WithArgs(args.apiId).WillReturnRows(rows)
@@ -150,7 +158,8 @@ func TestChannelPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(channelSettingId)
 				mock.ExpectQuery("INSERT INTO channel_settings").WithArgs(
 					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm).WillReturnRows(rows)
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				for _, categoryId := range args.channel.Setting.Categories {
 					mock.ExpectExec("INSERT INTO channel_categories").WithArgs(
@@ -212,7 +221,8 @@ func TestChannelPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
 				mock.ExpectQuery("INSERT INTO channel_settings").WithArgs(
 					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm).WillReturnRows(rows)
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -245,7 +255,8 @@ func TestChannelPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(channelSettingId)
 				mock.ExpectQuery("INSERT INTO channel_settings").WithArgs(
 					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm).WillReturnRows(rows)
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectExec("INSERT INTO channel_categories").
 					WithArgs(channelSettingId, args.channel.Setting.Categories[0]).
@@ -316,13 +327,14 @@ func TestChannelPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE channels SET (.+) WHERE (.+)").WithArgs(
-					args.channel.ApiHash, args.channel.Name, args.channel.ApiId).WillReturnRows(rows)
+					args.channel.ApiHash, args.channel.Name, args.channel.ApiId, args.channel.Version).WillReturnRows(rows)
 
 				channelSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(channelSettingId)
-				mock.ExpectQuery("UPDATE channel_settings SET (.+) WHERE (.+)").WithArgs(
-					args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM channel_categories WHERE (.+)").WithArgs(
 					channelSettingId).WillReturnResult(sqlmock.NewResult(0, 1))
@@ -356,7 +368,7 @@ func TestChannelPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
 				mock.ExpectQuery("UPDATE channels SET (.+) WHERE (.+)").WithArgs(
-					args.channel.ApiHash, args.channel.Name, args.channel.ApiId).WillReturnRows(rows)
+					args.channel.ApiHash, args.channel.Name, args.channel.ApiId, args.channel.Version).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -383,12 +395,13 @@ func TestChannelPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE channels SET (.+) WHERE (.+)").WithArgs(
-					args.channel.ApiHash, args.channel.Name, args.channel.ApiId).WillReturnRows(rows)
+					args.channel.ApiHash, args.channel.Name, args.channel.ApiId, args.channel.Version).WillReturnRows(rows)
 
 				rows = sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
-				mock.ExpectQuery("UPDATE channel_settings SET (.+) WHERE (.+)").WithArgs(
-					args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -415,13 +428,14 @@ func TestChannelPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE channels SET (.+) WHERE (.+)").WithArgs(
-					args.channel.ApiHash, args.channel.Name, args.channel.ApiId).WillReturnRows(rows)
+					args.channel.ApiHash, args.channel.Name, args.channel.ApiId, args.channel.Version).WillReturnRows(rows)
 
 				channelSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(channelSettingId)
-				mock.ExpectQuery("UPDATE channel_settings SET (.+) WHERE (.+)").WithArgs(
-					args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM channel_categories WHERE (.+)").WithArgs(
 					channelSettingId).WillReturnError(sql.ErrNoRows)
@@ -451,13 +465,14 @@ func TestChannelPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE channels SET (.+) WHERE (.+)").WithArgs(
-					args.channel.ApiHash, args.channel.Name, args.channel.ApiId).WillReturnRows(rows)
+					args.channel.ApiHash, args.channel.Name, args.channel.ApiId, args.channel.Version).WillReturnRows(rows)
 
 				channelSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(channelSettingId)
-				mock.ExpectQuery("UPDATE channel_settings SET (.+) WHERE (.+)").WithArgs(
-					args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
-					args.channel.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.channel.Setting.IsSafeDeal, args.channel.Setting.IsBudget,
+					args.channel.Setting.IsTerm, args.channel.Setting.DeliveryMode, args.channel.Setting.DigestTime,
+					args.channel.Setting.TemplateId, args.channel.Timezone, args.channel.Language).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM channel_categories WHERE (.+)").WithArgs(
 					channelSettingId).WillReturnResult(sqlmock.NewResult(0, 1))
@@ -487,6 +502,143 @@ func TestChannelPostgres_Update(t *testing.T) {
 	}
 }
 
+func TestChannelPostgres_Upsert(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewChannelPostgres(db)
+	isFalse := false
+
+	channel := core.ChannelInput{
+		ApiId:   1111,
+		ApiHash: "hash1111",
+		Name:    "channel-1",
+		Setting: core.SettingInput{
+			IsSafeDeal: &isFalse,
+			IsBudget:   &isFalse,
+			IsTerm:     &isFalse,
+			Categories: []int{1, 2},
+		},
+	}
+
+	type mockBehavior func(channel core.ChannelInput)
+
+	testTable := []struct {
+		name         string
+		channel      core.ChannelInput
+		mockBehavior mockBehavior
+		created      bool
+		wantErrIs    error
+	}{
+		{
+			name:    "Created",
+			channel: channel,
+			mockBehavior: func(channel core.ChannelInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, true)
+				mock.ExpectQuery("INSERT INTO channels (.+) ON CONFLICT \\(api_id\\) DO UPDATE").
+					WithArgs(channel.ApiId, channel.ApiHash, channel.Name, channel.Version).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT \\(channel_id\\) DO UPDATE").WithArgs(
+					2, channel.Setting.IsSafeDeal, channel.Setting.IsBudget, channel.Setting.IsTerm,
+					channel.Setting.DeliveryMode, channel.Setting.DigestTime, channel.Setting.TemplateId,
+					channel.Timezone, channel.Language).WillReturnRows(rows)
+
+				for _, categoryId := range channel.Setting.Categories {
+					mock.ExpectExec("INSERT INTO channel_categories").WithArgs(
+						3, categoryId).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				mock.ExpectCommit()
+			},
+			created: true,
+		},
+		{
+			name:    "Updated Restores Deleted",
+			channel: channel,
+			mockBehavior: func(channel core.ChannelInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, false)
+				mock.ExpectQuery("INSERT INTO channels (.+) ON CONFLICT \\(api_id\\) DO UPDATE (.+) "+
+					"status = CASE WHEN channels.status = 'deleted' THEN 'active' (.+) deleted_at = NULL").
+					WithArgs(channel.ApiId, channel.ApiHash, channel.Name, channel.Version).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO channel_settings (.+) ON CONFLICT \\(channel_id\\) DO UPDATE").WithArgs(
+					2, channel.Setting.IsSafeDeal, channel.Setting.IsBudget, channel.Setting.IsTerm,
+					channel.Setting.DeliveryMode, channel.Setting.DigestTime, channel.Setting.TemplateId,
+					channel.Timezone, channel.Language).WillReturnRows(rows)
+
+				mock.ExpectExec("DELETE FROM channel_categories WHERE (.+)").WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				for _, categoryId := range channel.Setting.Categories {
+					mock.ExpectExec("INSERT INTO channel_categories").WithArgs(
+						3, categoryId).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Stale Version",
+			channel: func() core.ChannelInput { c := channel; c.Version = 4; return c }(),
+			mockBehavior: func(channel core.ChannelInput) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("INSERT INTO channels (.+) ON CONFLICT \\(api_id\\) DO UPDATE").
+					WithArgs(channel.ApiId, channel.ApiHash, channel.Name, channel.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created"}))
+
+				mock.ExpectRollback()
+			},
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name:    "Missing With Version",
+			channel: func() core.ChannelInput { c := channel; c.Version = 4; return c }(),
+			mockBehavior: func(channel core.ChannelInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, true)
+				mock.ExpectQuery("INSERT INTO channels (.+) ON CONFLICT \\(api_id\\) DO UPDATE").
+					WithArgs(channel.ApiId, channel.ApiHash, channel.Name, channel.Version).WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name:         "Missing Flags",
+			channel:      func() core.ChannelInput { c := channel; c.Setting.IsTerm = nil; return c }(),
+			mockBehavior: func(channel core.ChannelInput) {},
+			wantErrIs:    core.ErrInvalidInput,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.channel)
+
+			got, created, err := r.Upsert(testCase.channel)
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, got)
+				assert.Equal(t, testCase.created, created)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestChannelPostgres_Delete(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 	if err != nil {
@@ -525,7 +677,7 @@ func TestChannelPostgres_Delete(t *testing.T) {
 			},
 			mockBehavior: func(args args) {
 				mock.ExpectExec("DELETE FROM channels WHERE (.+)").
-					WithArgs(args.apiId).WillReturnError(sql.ErrNoRows)
+					WithArgs(args.apiId).WillReturnResult(sqlmock.NewResult(0, 0))
 			},
 			wantErr: true,
 		},
@@ -536,6 +688,68 @@ func TestChannelPostgres_Delete(t *testing.T) {
 			testCase.mockBehavior(testCase.args)
 
 			err := r.Delete(testCase.args.apiId)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, core.ErrNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestChannelPostgres_Restore(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewChannelPostgres(db)
+	deletedAfter := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		apiId int
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				apiId: 1111,
+			},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("UPDATE channels SET status = 'active', failures = 0, deleted_at = NULL, (.+)").
+					WithArgs(args.apiId, deletedAfter).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Window Passed",
+			args: args{
+				apiId: 1111,
+			},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("UPDATE channels SET status = 'active', failures = 0, deleted_at = NULL, (.+)").
+					WithArgs(args.apiId, deletedAfter).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.Restore(testCase.args.apiId, deletedAfter)
 			if testCase.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -545,3 +759,97 @@ func TestChannelPostgres_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestChannelPostgres_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewChannelPostgres(db)
+	deletedAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"api_id", "name", "status", "deleted_at", "total"}).
+		AddRow(1111, "first", "active", nil, 3).
+		AddRow(2222, "second", "deleted", deletedAt, 3)
+	mock.ExpectQuery("SELECT api_id, name, status, deleted_at, count(.+) FROM channels (.+) LIMIT \\$2 OFFSET \\$3").
+		WithArgs("", 2, 0).WillReturnRows(rows)
+
+	got, total, err := r.GetAll("", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []core.ChannelListItemResponse{
+		{ApiId: 1111, Name: "first", Status: "active"},
+		{ApiId: 2222, Name: "second", Status: "deleted", DeletedAt: &deletedAt},
+	}, got)
+	assert.Equal(t, 3, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChannelPostgres_ReplaceCredentials(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewChannelPostgres(db)
+
+	type args struct {
+		apiId      int
+		oldApiHash string
+		newApiHash string
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				apiId:      1111,
+				oldApiHash: "hash1111",
+				newApiHash: "enc1.k1.key.value",
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectExec("UPDATE channels SET api_hash = (.+) WHERE api_id = (.+) AND api_hash = (.+)").
+					WithArgs(args.newApiHash, args.apiId, args.oldApiHash).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: true,
+		},
+		{
+			name: "Changed Concurrently",
+			args: args{
+				apiId:      1111,
+				oldApiHash: "hash1111",
+				newApiHash: "enc1.k1.key.value",
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectExec("UPDATE channels SET api_hash = (.+) WHERE api_id = (.+) AND api_hash = (.+)").
+					WithArgs(args.newApiHash, args.apiId, args.oldApiHash).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			got, err := r.ReplaceCredentials(testCase.args.apiId, testCase.args.oldApiHash, testCase.args.newApiHash)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
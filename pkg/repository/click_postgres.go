@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ClickPostgres struct {
+	db *sqlx.DB
+}
+
+func NewClickPostgres(db *sqlx.DB) *ClickPostgres {
+	return &ClickPostgres{db: db}
+}
+
+func (r *ClickPostgres) CreateUserLink(tgId int, task core.Task) (int, error) {
+	return r.createLink(userDigestRecipient, tgId, task)
+}
+
+func (r *ClickPostgres) CreateChannelLink(apiId int, task core.Task) (int, error) {
+	return r.createLink(channelDigestRecipient, apiId, task)
+}
+
+// GetUserLink returns the id of the user's link to the task url. It
+// returns sql.ErrNoRows when no link was created.
+func (r *ClickPostgres) GetUserLink(tgId int, url string) (int, error) {
+	return r.getLink(userDigestRecipient, tgId, url)
+}
+
+func (r *ClickPostgres) GetChannelLink(apiId int, url string) (int, error) {
+	return r.getLink(channelDigestRecipient, apiId, url)
+}
+
+// LogClick records a click on the link and returns the original task url.
+// It returns sql.ErrNoRows when the link does not exist.
+func (r *ClickPostgres) LogClick(linkId int) (string, error) {
+	var url string
+
+	query := fmt.Sprintf(`WITH click AS (
+			INSERT INTO %s (link_id) SELECT id FROM %s WHERE id = $1 RETURNING link_id)
+		SELECT tl.task_url FROM %s tl INNER JOIN click ON click.link_id = tl.id;`,
+		clicksTable, trackedLinksTable, trackedLinksTable)
+
+	row := r.db.QueryRow(query, linkId)
+	if err := row.Scan(&url); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// GetCategoryRates counts links issued in [from, to) and clicks on them per
+// category.
+func (r *ClickPostgres) GetCategoryRates(from, to time.Time) ([]core.ClickRateResponse, error) {
+	var rates []core.ClickRateResponse
+
+	query := fmt.Sprintf(`SELECT tl.category_id, c.name AS category, count(DISTINCT tl.id) AS links,
+		count(DISTINCT cl.link_id) AS clicked, count(cl.id) AS clicks FROM %s tl
+		INNER JOIN %s c ON c.id = tl.category_id
+		LEFT JOIN %s cl ON cl.link_id = tl.id
+		WHERE tl.created_at >= $1 AND tl.created_at < $2
+		GROUP BY tl.category_id, c.name ORDER BY links DESC, tl.category_id;`,
+		trackedLinksTable, categoriesTable, clicksTable)
+
+	if err := r.db.Select(&rates, query, from, to); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// GetSourceRates counts links issued in [from, to) and clicks on them per
+// freelance site.
+func (r *ClickPostgres) GetSourceRates(from, to time.Time) ([]core.ClickRateResponse, error) {
+	var rates []core.ClickRateResponse
+
+	query := fmt.Sprintf(`SELECT tl.fl_name AS source, count(DISTINCT tl.id) AS links,
+		count(DISTINCT cl.link_id) AS clicked, count(cl.id) AS clicks FROM %s tl
+		LEFT JOIN %s cl ON cl.link_id = tl.id
+		WHERE tl.created_at >= $1 AND tl.created_at < $2
+		GROUP BY tl.fl_name ORDER BY links DESC, tl.fl_name;`,
+		trackedLinksTable, clicksTable)
+
+	if err := r.db.Select(&rates, query, from, to); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// createLink returns the id of the recipient's link to the task, creating
+// it when there is none yet. It returns sql.ErrNoRows when the recipient
+// does not exist.
+func (r *ClickPostgres) createLink(recipient digestRecipient, key int, task core.Task) (int, error) {
+	var id int
+
+	query := fmt.Sprintf(`WITH existing AS (
+			SELECT tl.id FROM %s tl INNER JOIN %s r ON r.id = tl.%s WHERE r.%s = $1 AND tl.task_url = $2),
+		created AS (
+			INSERT INTO %s (%s, task_url, fl_name, category_id)
+			SELECT id, $2, $3, $4 FROM %s WHERE %s = $1 AND NOT EXISTS (SELECT 1 FROM existing)
+			ON CONFLICT (%s, task_url) DO NOTHING RETURNING id)
+		SELECT id FROM existing UNION ALL SELECT id FROM created;`,
+		trackedLinksTable, recipient.table, recipient.refColumn, recipient.keyColumn,
+		trackedLinksTable, recipient.refColumn, recipient.table, recipient.keyColumn, recipient.refColumn)
+
+	row := r.db.QueryRow(query, key, task.Url, task.FLName, task.CategoryId)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *ClickPostgres) getLink(recipient digestRecipient, key int, url string) (int, error) {
+	var id int
+
+	query := fmt.Sprintf(`SELECT tl.id FROM %s tl INNER JOIN %s r ON r.id = tl.%s
+		WHERE r.%s = $1 AND tl.task_url = $2;`,
+		trackedLinksTable, recipient.table, recipient.refColumn, recipient.keyColumn)
+
+	if err := r.db.Get(&id, query, key, url); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
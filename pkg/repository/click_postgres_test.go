@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestClickPostgres_LogClick(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewClickPostgres(db)
+
+	type mockBehavior func(linkId int)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		linkId       int
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:   "OK",
+			linkId: 1,
+			mockBehavior: func(linkId int) {
+				rows := sqlmock.NewRows([]string{"task_url"}).AddRow("https://example.com/task/1")
+				mock.ExpectQuery("WITH click AS (.+) INSERT INTO clicks (.+) SELECT tl.task_url FROM tracked_links tl").
+					WithArgs(linkId).WillReturnRows(rows)
+			},
+			want: "https://example.com/task/1",
+		},
+		{
+			name:   "Link Not Found",
+			linkId: 2,
+			mockBehavior: func(linkId int) {
+				rows := sqlmock.NewRows([]string{"task_url"})
+				mock.ExpectQuery("WITH click AS (.+) INSERT INTO clicks (.+) SELECT tl.task_url FROM tracked_links tl").
+					WithArgs(linkId).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.linkId)
+
+			got, err := r.LogClick(testCase.linkId)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClickPostgres_GetUserLink(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewClickPostgres(db)
+
+	type args struct {
+		tgId int
+		url  string
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		want         int
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{tgId: 1111, url: "https://example.com/task/1"},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("SELECT tl.id FROM tracked_links tl INNER JOIN users r (.+)").
+					WithArgs(args.tgId, args.url).WillReturnRows(rows)
+			},
+			want: 3,
+		},
+		{
+			name: "No Link",
+			args: args{tgId: 1111, url: "https://example.com/task/2"},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("SELECT tl.id FROM tracked_links tl INNER JOIN users r (.+)").
+					WithArgs(args.tgId, args.url).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			got, err := r.GetUserLink(testCase.args.tgId, testCase.args.url)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"fmt"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DigestPostgres struct {
+	db *sqlx.DB
+}
+
+func NewDigestPostgres(db *sqlx.DB) *DigestPostgres {
+	return &DigestPostgres{db: db}
+}
+
+// digestRecipient describes how users and channels are stored so that the
+// digest queries can be shared between them.
+type digestRecipient struct {
+	table         string
+	keyColumn     string
+	refColumn     string
+	settingsTable string
+	quietColumns  string
+	// heldTable keeps tasks during quiet hours, only users have one.
+	heldTable string
+}
+
+var (
+	userDigestRecipient = digestRecipient{
+		table:         usersTable,
+		keyColumn:     "tg_id",
+		refColumn:     "user_id",
+		settingsTable: userSettingsTable,
+		quietColumns:  "s.quiet_from, s.quiet_to, s.is_quiet_digest",
+		heldTable:     heldTasksTable,
+	}
+	channelDigestRecipient = digestRecipient{
+		table:         channelsTable,
+		keyColumn:     "api_id",
+		refColumn:     "channel_id",
+		settingsTable: channelSettingsTable,
+		quietColumns:  "'' AS quiet_from, '' AS quiet_to, false AS is_quiet_digest",
+	}
+)
+
+func (r *DigestPostgres) GetDigestUsers() ([]int, error) {
+	return r.getSubscribers(userDigestRecipient)
+}
+
+func (r *DigestPostgres) GetDigestChannels() ([]int, error) {
+	return r.getSubscribers(channelDigestRecipient)
+}
+
+func (r *DigestPostgres) QueueUserTasks(tgId int, tasks []core.UserTaskResponse) error {
+	queuedTasks := make([]core.QueuedTaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		queuedTasks = append(queuedTasks, core.QueuedTaskResponse{Title: task.Title, Body: task.Body, Url: task.Url})
+	}
+
+	return r.queueTasks(userDigestRecipient, tgId, queuedTasks)
+}
+
+func (r *DigestPostgres) QueueChannelTasks(apiId int, tasks []core.ChannelTaskResponse) error {
+	queuedTasks := make([]core.QueuedTaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		queuedTasks = append(queuedTasks, core.QueuedTaskResponse{Title: task.Title, Body: task.Body, Url: task.Url})
+	}
+
+	return r.queueTasks(channelDigestRecipient, apiId, queuedTasks)
+}
+
+func (r *DigestPostgres) GetUserSchedules() ([]core.DigestScheduleResponse, error) {
+	return r.getSchedules(userDigestRecipient)
+}
+
+func (r *DigestPostgres) GetChannelSchedules() ([]core.DigestScheduleResponse, error) {
+	return r.getSchedules(channelDigestRecipient)
+}
+
+func (r *DigestPostgres) GetUserQueue(tgId int) ([]core.QueuedTaskResponse, error) {
+	return r.getQueue(userDigestRecipient, tgId)
+}
+
+func (r *DigestPostgres) GetChannelQueue(apiId int) ([]core.QueuedTaskResponse, error) {
+	return r.getQueue(channelDigestRecipient, apiId)
+}
+
+func (r *DigestPostgres) CreateUserDigest(tgId int, digest core.DigestInput, lastTaskId int) error {
+	return r.createDigest(userDigestRecipient, digestTasksTable, tgId, digest, lastTaskId)
+}
+
+func (r *DigestPostgres) CreateChannelDigest(apiId int, digest core.DigestInput, lastTaskId int) error {
+	return r.createDigest(channelDigestRecipient, digestTasksTable, apiId, digest, lastTaskId)
+}
+
+// GetHeldQueue returns the tasks held for the user during quiet hours.
+func (r *DigestPostgres) GetHeldQueue(tgId int) ([]core.QueuedTaskResponse, error) {
+	var tasks []core.QueuedTaskResponse
+
+	query := fmt.Sprintf(`SELECT ht.id, ht.title, ht.body, ht.task_url FROM %s ht
+		INNER JOIN %s u ON u.id = ht.user_id WHERE u.tg_id = $1 ORDER BY ht.id;`,
+		heldTasksTable, usersTable)
+
+	if err := r.db.Select(&tasks, query, tgId); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// CreateQuietDigest stores a digest of the tasks held for the user and
+// releases them.
+func (r *DigestPostgres) CreateQuietDigest(tgId int, digest core.DigestInput, lastHeldId int) error {
+	return r.createDigest(userDigestRecipient, heldTasksTable, tgId, digest, lastHeldId)
+}
+
+// GetUserDigests returns the digests of active users that are not served
+// yet. Digests are served by reporting their delivery.
+func (r *DigestPostgres) GetUserDigests() ([]core.UserDigestResponse, error) {
+	var digests []core.UserDigestResponse
+
+	query := fmt.Sprintf(`SELECT d.id, u.tg_id, d.title, d.body, d.tasks_count FROM %s d
+		INNER JOIN %s u ON d.user_id = u.id
+		WHERE d.served_at IS NULL AND u.status = '%s' ORDER BY d.id;`,
+		digestsTable, usersTable, core.StatusActive)
+
+	if err := r.db.Select(&digests, query); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+func (r *DigestPostgres) GetChannelDigests() ([]core.ChannelDigestResponse, error) {
+	var digests []core.ChannelDigestResponse
+
+	query := fmt.Sprintf(`SELECT d.id, ch.api_id, d.title, d.body, d.tasks_count FROM %s d
+		INNER JOIN %s ch ON d.channel_id = ch.id
+		WHERE d.served_at IS NULL AND ch.status = '%s' ORDER BY d.id;`,
+		digestsTable, channelsTable, core.StatusActive)
+
+	if err := r.db.Select(&digests, query); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+func (r *DigestPostgres) getSubscribers(recipient digestRecipient) ([]int, error) {
+	var keys []int
+
+	query := fmt.Sprintf(`SELECT r.%s FROM %s r INNER JOIN %s s ON r.id = s.%s
+		WHERE s.delivery_mode != '%s';`,
+		recipient.keyColumn, recipient.table, recipient.settingsTable, recipient.refColumn, core.DeliveryInstant)
+
+	if err := r.db.Select(&keys, query); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *DigestPostgres) queueTasks(recipient digestRecipient, key int, tasks []core.QueuedTaskResponse) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		queueTaskQuery := fmt.Sprintf(`INSERT INTO %s (%s, task_url, title, body)
+			SELECT id, $2, $3, $4 FROM %s WHERE %s = $1 ON CONFLICT DO NOTHING;`,
+			digestTasksTable, recipient.refColumn, recipient.table, recipient.keyColumn)
+
+		if _, err := tx.Exec(queueTaskQuery, key, task.Url, task.Title, task.Body); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *DigestPostgres) getSchedules(recipient digestRecipient) ([]core.DigestScheduleResponse, error) {
+	var schedules []core.DigestScheduleResponse
+
+	// The queue is aggregated on its own so that the recipient and settings
+	// columns do not have to be grouped by.
+	query := fmt.Sprintf(`SELECT r.%s AS key, s.timezone, s.delivery_mode, s.digest_time, s.language, %s,
+		dt.queued_at FROM (SELECT %s, min(queued_at) AS queued_at FROM %s GROUP BY %s) dt
+		INNER JOIN %s r ON r.id = dt.%s
+		INNER JOIN %s s ON s.%s = r.id
+		WHERE r.status = '%s';`,
+		recipient.keyColumn, recipient.quietColumns, recipient.refColumn, digestTasksTable, recipient.refColumn,
+		recipient.table, recipient.refColumn, recipient.settingsTable, recipient.refColumn, core.StatusActive)
+
+	if err := r.db.Select(&schedules, query); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (r *DigestPostgres) getQueue(recipient digestRecipient, key int) ([]core.QueuedTaskResponse, error) {
+	var tasks []core.QueuedTaskResponse
+
+	query := fmt.Sprintf(`SELECT dt.id, dt.title, dt.body, dt.task_url FROM %s dt
+		INNER JOIN %s r ON r.id = dt.%s WHERE r.%s = $1 ORDER BY dt.id;`,
+		digestTasksTable, recipient.table, recipient.refColumn, recipient.keyColumn)
+
+	if err := r.db.Select(&tasks, query, key); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// createDigest stores the rendered digest and removes the tasks it was
+// built from queueTable, leaving tasks queued after lastTaskId for the next
+// digest.
+func (r *DigestPostgres) createDigest(recipient digestRecipient, queueTable string, key int,
+	digest core.DigestInput, lastTaskId int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var recipientId int
+	createDigestQuery := fmt.Sprintf(`INSERT INTO %s (%s, title, body, tasks_count)
+		SELECT id, $2, $3, $4 FROM %s WHERE %s = $1 RETURNING %s;`,
+		digestsTable, recipient.refColumn, recipient.table, recipient.keyColumn, recipient.refColumn)
+
+	row := tx.QueryRow(createDigestQuery, key, digest.Title, digest.Body, digest.Count)
+	if err := row.Scan(&recipientId); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	deleteQueueQuery := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND id <= $2;",
+		queueTable, recipient.refColumn)
+
+	if _, err := tx.Exec(deleteQueueQuery, recipientId, lastTaskId); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
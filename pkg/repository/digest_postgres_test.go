@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestDigestPostgres_CreateUserDigest(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewDigestPostgres(db)
+
+	type args struct {
+		tgId       int
+		digest     core.DigestInput
+		lastTaskId int
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				tgId:       1111,
+				digest:     core.DigestInput{Title: "digest", Body: "digest-body", Count: 2},
+				lastTaskId: 7,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"user_id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO digests").
+					WithArgs(args.tgId, args.digest.Title, args.digest.Body, args.digest.Count).
+					WillReturnRows(rows)
+
+				mock.ExpectExec("DELETE FROM digest_tasks WHERE (.+)").
+					WithArgs(1, args.lastTaskId).WillReturnResult(sqlmock.NewResult(0, 2))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Unknown User",
+			args: args{
+				tgId:       1111,
+				digest:     core.DigestInput{Title: "digest", Body: "digest-body", Count: 2},
+				lastTaskId: 7,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"user_id"})
+				mock.ExpectQuery("INSERT INTO digests").
+					WithArgs(args.tgId, args.digest.Title, args.digest.Body, args.digest.Count).
+					WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Queue Failure",
+			args: args{
+				tgId:       1111,
+				digest:     core.DigestInput{Title: "digest", Body: "digest-body", Count: 2},
+				lastTaskId: 7,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"user_id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO digests").
+					WithArgs(args.tgId, args.digest.Title, args.digest.Body, args.digest.Count).
+					WillReturnRows(rows)
+
+				mock.ExpectExec("DELETE FROM digest_tasks WHERE (.+)").
+					WithArgs(1, args.lastTaskId).WillReturnError(errors.New("some error"))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.CreateUserDigest(testCase.args.tgId, testCase.args.digest, testCase.args.lastTaskId)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDigestPostgres_GetChannelDigests(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewDigestPostgres(db)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		want         []core.ChannelDigestResponse
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func() {
+				rows := sqlmock.NewRows([]string{"id", "api_id", "title", "body", "tasks_count"}).
+					AddRow(7, 1111, "digest", "digest-body", 3)
+				mock.ExpectQuery("SELECT (.+) FROM digests d INNER JOIN channels ch (.+) WHERE d.served_at IS NULL").
+					WillReturnRows(rows)
+			},
+			want: []core.ChannelDigestResponse{
+				{
+					DigestId: 7,
+					ApiId:    1111,
+					Title:    "digest",
+					Body:     "digest-body",
+					Count:    3,
+				},
+			},
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				rows := sqlmock.NewRows([]string{"id", "api_id", "title", "body", "tasks_count"})
+				mock.ExpectQuery("SELECT (.+) FROM digests d INNER JOIN channels ch (.+) WHERE d.served_at IS NULL").
+					WillReturnRows(rows)
+			},
+			want: []core.ChannelDigestResponse(nil),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			got, err := r.GetChannelDigests()
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx"
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// PostgreSQL error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// translateError replaces sql.ErrNoRows and the pgx errors of violated
+// constraints and failed transactions with typed core errors that wrap the
+// original one. Other errors are returned as they are.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var typed *core.Error
+	if errors.As(err, &typed) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return typedError(core.ErrNotFound, err)
+	}
+
+	var pgErr pgx.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return typedError(core.ErrAlreadyExists, err)
+		case foreignKeyViolation:
+			return typedError(core.ErrInvalidReference, err)
+		case serializationFailure, deadlockDetected:
+			return typedError(core.ErrConflict, err)
+		}
+	}
+
+	return err
+}
+
+func typedError(kind, err error) error {
+	return &core.Error{Kind: kind, Message: kind.Error(), Err: err}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	someErr := errors.New("some error")
+
+	testTable := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "No Rows", err: sql.ErrNoRows, wantErr: core.ErrNotFound},
+		{name: "Unique Violation", err: pgx.PgError{Code: "23505"}, wantErr: core.ErrAlreadyExists},
+		{name: "Foreign Key Violation", err: pgx.PgError{Code: "23503"}, wantErr: core.ErrInvalidReference},
+		{name: "Serialization Failure", err: pgx.PgError{Code: "40001"}, wantErr: core.ErrConflict},
+		{name: "Other Code", err: pgx.PgError{Code: "42P01"}, wantErr: pgx.PgError{Code: "42P01"}},
+		{name: "Other Error", err: someErr, wantErr: someErr},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := translateError(testCase.err)
+
+			assert.ErrorIs(t, err, testCase.wantErr)
+			assert.ErrorIs(t, err, testCase.err)
+		})
+	}
+
+	assert.NoError(t, translateError(nil))
+	assert.Equal(t, "record already exists", translateError(pgx.PgError{Code: "23505", Message: "duplicate key"}).Error())
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type FeedbackPostgres struct {
+	db *sqlx.DB
+}
+
+func NewFeedbackPostgres(db *sqlx.DB) *FeedbackPostgres {
+	return &FeedbackPostgres{db: db}
+}
+
+// MuteCategory removes the category from the user's settings and bumps
+// the user's version.
+func (r *FeedbackPostgres) MuteCategory(tgId, categoryId int) error {
+	query := fmt.Sprintf(`WITH muted AS (
+			DELETE FROM %s uc USING %s us, %s u
+			WHERE uc.user_setting_id = us.id AND us.user_id = u.id AND u.tg_id = $1 AND uc.category_id = $2
+			RETURNING u.id)
+		UPDATE %s SET version = version + 1 WHERE id IN (SELECT id FROM muted);`,
+		userCategoriesTable, userSettingsTable, usersTable, usersTable)
+
+	if _, err := r.db.Exec(query, tgId, categoryId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveTask adds the task to the user's saved tasks. It returns
+// sql.ErrNoRows when the user or the task does not exist.
+func (r *FeedbackPostgres) SaveTask(tgId, taskId int) error {
+	var id int
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, task_url, title)
+		SELECT u.id, flt.task_url, flt.title FROM %s u, %s flt WHERE u.tg_id = $1 AND flt.id = $2
+		ON CONFLICT (user_id, task_url) DO UPDATE SET saved_at = %s.saved_at RETURNING id;`,
+		savedTasksTable, usersTable, freelanceTasksTable, savedTasksTable)
+
+	row := r.db.QueryRow(query, tgId, taskId)
+	return row.Scan(&id)
+}
+
+func (r *FeedbackPostgres) GetSavedTasks(tgId int) ([]core.SavedTaskResponse, error) {
+	var tasks []core.SavedTaskResponse
+
+	query := fmt.Sprintf(`SELECT st.task_url, st.title, st.saved_at FROM %s st
+		INNER JOIN %s u ON u.id = st.user_id WHERE u.tg_id = $1 ORDER BY st.saved_at DESC, st.id DESC;`,
+		savedTasksTable, usersTable)
+
+	if err := r.db.Select(&tasks, query, tgId); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// NotInterested records the mark and hides the task from the user's feed.
+// It returns sql.ErrNoRows when the user or the task does not exist.
+func (r *FeedbackPostgres) NotInterested(tgId, taskId int, reason string) error {
+	var id int
+
+	query := fmt.Sprintf(`WITH task AS (
+			SELECT u.id AS user_id, flt.task_url, flt.title, flt.category_id FROM %s u, %s flt
+			WHERE u.tg_id = $1 AND flt.id = $2),
+		hidden AS (
+			INSERT INTO %s (user_id, task_url) SELECT user_id, task_url FROM task
+			ON CONFLICT (user_id, task_url) DO NOTHING)
+		INSERT INTO %s (user_id, task_url, title, category_id, reason)
+		SELECT user_id, task_url, title, category_id, $3 FROM task
+		ON CONFLICT (user_id, task_url) DO UPDATE SET reason = EXCLUDED.reason RETURNING id;`,
+		usersTable, freelanceTasksTable, hiddenTasksTable, notInterestedTable)
+
+	row := r.db.QueryRow(query, tgId, taskId, reason)
+	return row.Scan(&id)
+}
+
+// GetCategoryMarks counts marks since the given time per category the user
+// is still subscribed to, keeping categories marked at least minCount times.
+func (r *FeedbackPostgres) GetCategoryMarks(tgId int, since time.Time, minCount int) ([]core.ExclusionRuleResponse, error) {
+	var rules []core.ExclusionRuleResponse
+
+	query := fmt.Sprintf(`SELECT '%s' AS type, c.id AS category_id, c.name AS category, count(*) AS count FROM %s ni
+		INNER JOIN %s u ON u.id = ni.user_id
+		INNER JOIN %s us ON us.user_id = u.id
+		INNER JOIN %s uc ON uc.user_setting_id = us.id AND uc.category_id = ni.category_id
+		INNER JOIN %s c ON c.id = ni.category_id
+		WHERE u.tg_id = $1 AND ni.created_at >= $2
+		GROUP BY c.id, c.name HAVING count(*) >= $3 ORDER BY count DESC, c.id;`,
+		core.RuleCategory, notInterestedTable, usersTable, userSettingsTable, userCategoriesTable, categoriesTable)
+
+	if err := r.db.Select(&rules, query, tgId, since, minCount); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetMarkedTitles returns titles of tasks marked since the given time.
+func (r *FeedbackPostgres) GetMarkedTitles(tgId int, since time.Time) ([]string, error) {
+	var titles []string
+
+	query := fmt.Sprintf(`SELECT ni.title FROM %s ni INNER JOIN %s u ON u.id = ni.user_id
+		WHERE u.tg_id = $1 AND ni.created_at >= $2 ORDER BY ni.id;`, notInterestedTable, usersTable)
+
+	if err := r.db.Select(&titles, query, tgId, since); err != nil {
+		return nil, err
+	}
+
+	return titles, nil
+}
+
+// ExcludeKeyword stops matching tasks containing keyword for the user and
+// bumps the user's version. It returns sql.ErrNoRows when the user does not
+// exist.
+func (r *FeedbackPostgres) ExcludeKeyword(tgId int, keyword string) error {
+	var id int
+
+	query := fmt.Sprintf(`WITH excluded AS (
+			INSERT INTO %s (user_setting_id, keyword)
+			SELECT us.id, $2 FROM %s us INNER JOIN %s u ON u.id = us.user_id WHERE u.tg_id = $1
+			ON CONFLICT (user_setting_id, keyword) DO UPDATE SET keyword = EXCLUDED.keyword
+			RETURNING user_setting_id)
+		UPDATE %s u SET version = u.version + 1 FROM %s us, excluded e
+		WHERE us.id = e.user_setting_id AND u.id = us.user_id RETURNING u.id;`,
+		excludedKeywordsTable, userSettingsTable, usersTable, usersTable, userSettingsTable)
+
+	row := r.db.QueryRow(query, tgId, keyword)
+	return row.Scan(&id)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestFeedbackPostgres_NotInterested(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewFeedbackPostgres(db)
+
+	type args struct {
+		tgId   int
+		taskId int
+		reason string
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				tgId:   1111,
+				taskId: 1,
+				reason: "too cheap",
+			},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("WITH task AS (.+) INSERT INTO not_interested_tasks (.+) SELECT (.+) FROM task").
+					WithArgs(args.tgId, args.taskId, args.reason).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Task Not Found",
+			args: args{
+				tgId:   1111,
+				taskId: 2,
+			},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("WITH task AS (.+) INSERT INTO not_interested_tasks (.+) SELECT (.+) FROM task").
+					WithArgs(args.tgId, args.taskId, args.reason).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.NotInterested(testCase.args.tgId, testCase.args.taskId, testCase.args.reason)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -8,17 +8,45 @@ import (
 )
 
 const (
-	usersTable             = "users"
-	userSettingsTable      = "user_settings"
-	userCategoriesTable    = "user_categories"
-	channelsTable          = "channels"
-	channelSettingsTable   = "channel_settings"
-	channelCategoriesTable = "channel_categories"
-	categoriesTable        = "categories"
-	freelanceTasksTable    = "freelance_tasks"
-	lastParsedTasksTable   = "last_parsed_tasks"
+	usersTable                 = "users"
+	userSettingsTable          = "user_settings"
+	userCategoriesTable        = "user_categories"
+	channelsTable              = "channels"
+	channelSettingsTable       = "channel_settings"
+	channelCategoriesTable     = "channel_categories"
+	categoriesTable            = "categories"
+	freelanceTasksTable        = "freelance_tasks"
+	freelanceTasksArchiveTable = "freelance_tasks_archive"
+	lastParsedTasksTable       = "last_parsed_tasks"
+	deliveriesTable            = "deliveries"
+	heldTasksTable             = "held_tasks"
+	digestTasksTable           = "digest_tasks"
+	digestsTable               = "digests"
+	templatesTable             = "templates"
+	hiddenTasksTable           = "hidden_tasks"
+	savedTasksTable            = "saved_tasks"
+	notInterestedTable         = "not_interested_tasks"
+	excludedKeywordsTable      = "user_excluded_keywords"
+	trackedLinksTable          = "tracked_links"
+	clicksTable                = "clicks"
+	matchesTable               = "matches"
+	erasuresTable              = "erasures"
+	apiKeysTable               = "api_keys"
 )
 
+// taskColumns selects core.Task from freelance_tasks flt joined with
+// categories c.
+const taskColumns = `flt.id, flt.fl_name, flt.fl_url, flt.task_url, flt.category_id, c.name AS category,
+	flt.title, flt.description, flt.budget, flt.is_budget_per_hour, flt.term, flt.is_safe_deal, flt.datetime`
+
+// archiveColumns are copied from freelance_tasks to freelance_tasks_archive.
+const archiveColumns = `id, task_url, title, category_id, is_budget, is_term, is_safe_deal, fl_name, fl_url,
+	description, budget, is_budget_per_hour, term, datetime, ingested_at`
+
+// searchColumns are selected from both task tables for searches.
+const searchColumns = `id, fl_name, fl_url, task_url, category_id, title, description, budget, is_budget_per_hour,
+	term, is_safe_deal, datetime, ingested_at, search_vector`
+
 type Config struct {
 	Host     string
 	Port     string
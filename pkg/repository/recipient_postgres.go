@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Users and channels are both task recipients and share the status and
+// delivery bookkeeping below. table is the recipient table, keyColumn its
+// telegram identifier and deliveryColumn the reference in deliveries.
+
+// setRecipientStatus changes the status of a recipient that is not
+// deleted. A recipient in one of the kept statuses is left as it is and
+// reported with core.ErrConflict. It returns core.ErrNotFound when there is
+// no such recipient.
+func setRecipientStatus(db *sqlx.DB, table, keyColumn string, key int, status string, kept ...string) (err error) {
+	defer func() { err = translateError(err) }()
+
+	excluded := append([]string{core.StatusDeleted}, kept...)
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, failures = 0, version = version + 1
+		WHERE %s = $2 AND status NOT IN ('%s');`, table, keyColumn, strings.Join(excluded, "', '"))
+
+	result, err := db.Exec(query, status, key)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		var current string
+
+		query := fmt.Sprintf("SELECT status FROM %s WHERE %s = $1;", table, keyColumn)
+		if err := db.QueryRow(query, key).Scan(&current); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		for _, keptStatus := range kept {
+			if current == keptStatus {
+				return core.NewError(core.ErrConflict, fmt.Sprintf("recipient is %s", current))
+			}
+		}
+
+		return core.NewError(core.ErrNotFound, "recipient not found")
+	}
+
+	return nil
+}
+
+// checkRecipientSetting rejects settings without the filter flags, which
+// have no column defaults.
+func checkRecipientSetting(setting core.SettingInput) error {
+	if setting.IsSafeDeal == nil || setting.IsBudget == nil || setting.IsTerm == nil {
+		return core.NewError(core.ErrInvalidInput, "is_safe_deal, is_budget and is_term are required")
+	}
+
+	return nil
+}
+
+// saveRecipientCategories stores the categories of a recipient setting.
+// With replace the stored categories are deleted first.
+func saveRecipientCategories(tx *sql.Tx, table, settingColumn string, settingId int, categories []int,
+	replace bool) error {
+	if replace {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1;", table, settingColumn)
+		if _, err := tx.Exec(query, settingId); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, category_id) VALUES ($1, $2);", table, settingColumn)
+	for _, categoryId := range categories {
+		if _, err := tx.Exec(query, settingId, categoryId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// staleRecipientError tells why an update of a recipient matched no row:
+// core.ErrConflict when the recipient exists with another version than
+// the expected one, sql.ErrNoRows when there is no such recipient.
+func staleRecipientError(tx *sql.Tx, table, keyColumn string, key, version int) error {
+	if version == 0 {
+		return sql.ErrNoRows
+	}
+
+	var exists bool
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s = $1);", table, keyColumn)
+	if err := tx.QueryRow(query, key).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return typedError(core.ErrConflict, nil)
+	}
+
+	return sql.ErrNoRows
+}
+
+// reportRecipientDelivery records the delivery and updates the failure
+// count of the recipient. A delivered task is released if it was held, a
+// delivered digest is marked served. It returns core.ErrNotFound when
+// there is no such recipient.
+func reportRecipientDelivery(db *sqlx.DB, recipient digestRecipient, key int, delivery core.DeliveryInput,
+	maxFailures int) (_ string, err error) {
+	defer func() { err = translateError(err) }()
+
+	table, keyColumn, deliveryColumn := recipient.table, recipient.keyColumn, recipient.refColumn
+	isDelivered := delivery.IsDelivered != nil && *delivery.IsDelivered
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	var recipientId int
+	var status string
+	var row *sql.Row
+
+	switch {
+	case isDelivered:
+		query := fmt.Sprintf("UPDATE %s SET failures = 0 WHERE %s = $1 RETURNING id, status;", table, keyColumn)
+		row = tx.QueryRow(query, key)
+	case delivery.IsPermanent:
+		// Deleted and admin-deactivated recipients keep their status.
+		query := fmt.Sprintf(`UPDATE %[1]s SET failures = failures + 1,
+			status = CASE WHEN failures + 1 >= $2 AND status NOT IN ('%[2]s', '%[3]s') THEN '%[4]s' ELSE status END,
+			version = CASE WHEN failures + 1 >= $2 AND status NOT IN ('%[2]s', '%[3]s', '%[4]s') THEN version + 1
+			ELSE version END
+			WHERE %[5]s = $1 RETURNING id, status;`, table, core.StatusDeleted, core.StatusInactive, core.StatusBlocked,
+			keyColumn)
+		row = tx.QueryRow(query, key, maxFailures)
+	default:
+		query := fmt.Sprintf("SELECT id, status FROM %s WHERE %s = $1;", table, keyColumn)
+		row = tx.QueryRow(query, key)
+	}
+
+	if err := row.Scan(&recipientId, &status); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return "", err
+		}
+		return "", err
+	}
+
+	createDeliveryQuery := fmt.Sprintf(`INSERT INTO %s (%s, task_url, is_delivered, is_permanent, reason, task_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0));`, deliveriesTable, deliveryColumn)
+
+	if _, err := tx.Exec(createDeliveryQuery, recipientId, delivery.Url, isDelivered, delivery.IsPermanent,
+		delivery.Reason, delivery.TaskId); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return "", err
+		}
+		return "", err
+	}
+
+	if isDelivered && delivery.TaskId != 0 && recipient.heldTable != "" {
+		releaseTaskQuery := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND task_id = $2;",
+			recipient.heldTable, deliveryColumn)
+
+		if _, err := tx.Exec(releaseTaskQuery, recipientId, delivery.TaskId); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return "", err
+			}
+			return "", err
+		}
+	}
+
+	if isDelivered && delivery.DigestId != 0 {
+		serveDigestQuery := fmt.Sprintf("UPDATE %s SET served_at = now() WHERE id = $1 AND %s = $2;",
+			digestsTable, deliveryColumn)
+
+		if _, err := tx.Exec(serveDigestQuery, delivery.DigestId, recipientId); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return "", err
+			}
+			return "", err
+		}
+	}
+
+	return status, tx.Commit()
+}
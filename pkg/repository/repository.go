@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	core "github.com/max-sanch/BotFreelancer-core"
 )
@@ -9,35 +11,135 @@ type Channel interface {
 	GetByApiId(apiId int) (core.ChannelResponse, error)
 	Create(channelInput core.ChannelInput) (int, error)
 	Update(channelInput core.ChannelInput) (int, error)
+	Upsert(channelInput core.ChannelInput) (int, bool, error)
 	Delete(apiID int) error
+	GetAll(status string, limit, offset int) ([]core.ChannelListItemResponse, int, error)
+	SoftDelete(apiId int) error
+	Restore(apiId int, deletedAfter time.Time) error
+	PurgeDeleted(deletedBefore time.Time) (int64, error)
+	GetCredentials(apiId int) (core.ChannelCredentialsResponse, error)
+	GetAllCredentials() ([]core.ChannelCredentialsResponse, error)
+	ReplaceCredentials(apiId int, oldApiHash, newApiHash string) (bool, error)
+	SetStatus(apiId int, status string) error
+	ReportDelivery(apiId int, delivery core.DeliveryInput, maxFailures int) (string, error)
 }
 
 type User interface {
 	GetByTgId(tgId int) (core.UserResponse, error)
 	Create(userInput core.UserInput) (int, error)
 	Update(userInput core.UserInput) (int, error)
+	Upsert(userInput core.UserInput) (int, bool, error)
+	GetAll(status string, limit, offset int) ([]core.UserListItemResponse, int, error)
+	SoftDelete(tgId int) error
+	Erase(tgId int, subjectHash string) error
+	GetSubscriptions(tgId int) ([]core.SubscriptionResponse, error)
+	GetDeliveries(tgId int) ([]core.DeliveryRecordResponse, error)
+	GetActivity(tgId int) (core.UserActivityResponse, error)
+	SetStatus(tgId int, status string, kept ...string) error
+	ReportDelivery(tgId int, delivery core.DeliveryInput, maxFailures int) (string, error)
+	GetSchedules() ([]core.UserScheduleResponse, error)
 }
 
 type Task interface {
 	GetOrCreateCategoryByName(name string) (int, error)
 	GetLastParseTime() (string, error)
 	SetLastParseTime() error
-	GetAllForChannels() ([]core.ChannelTaskResponse, error)
-	GetAllForUsers() ([]core.UserTaskResponse, error)
+	GetAllForChannels() ([]core.ChannelTask, error)
+	GetAllForUsers() ([]core.UserTask, error)
 	AddTasks(tasksInput core.TasksInput) error
-	DeleteAll() error
+	ArchiveTasks(before time.Time) (int64, error)
+	Search(filter core.TaskSearchFilter) ([]core.Task, int, error)
+	HoldUserTasks(tgId int, tasks []core.UserTaskResponse) error
+	GetHeldTasks() ([]core.UserTaskResponse, error)
+}
+
+type Digest interface {
+	GetDigestUsers() ([]int, error)
+	GetDigestChannels() ([]int, error)
+	QueueUserTasks(tgId int, tasks []core.UserTaskResponse) error
+	QueueChannelTasks(apiId int, tasks []core.ChannelTaskResponse) error
+	GetUserSchedules() ([]core.DigestScheduleResponse, error)
+	GetChannelSchedules() ([]core.DigestScheduleResponse, error)
+	GetUserQueue(tgId int) ([]core.QueuedTaskResponse, error)
+	GetChannelQueue(apiId int) ([]core.QueuedTaskResponse, error)
+	CreateUserDigest(tgId int, digest core.DigestInput, lastTaskId int) error
+	CreateChannelDigest(apiId int, digest core.DigestInput, lastTaskId int) error
+	GetHeldQueue(tgId int) ([]core.QueuedTaskResponse, error)
+	CreateQuietDigest(tgId int, digest core.DigestInput, lastHeldId int) error
+	GetUserDigests() ([]core.UserDigestResponse, error)
+	GetChannelDigests() ([]core.ChannelDigestResponse, error)
+}
+
+type Template interface {
+	GetAll() ([]core.TemplateResponse, error)
+	Create(templateInput core.TemplateInput) (int, error)
+	Update(templateInput core.TemplateUpdateInput) (int, error)
+	Delete(id int) error
+}
+
+type Feedback interface {
+	MuteCategory(tgId, categoryId int) error
+	SaveTask(tgId, taskId int) error
+	GetSavedTasks(tgId int) ([]core.SavedTaskResponse, error)
+	NotInterested(tgId, taskId int, reason string) error
+	GetCategoryMarks(tgId int, since time.Time, minCount int) ([]core.ExclusionRuleResponse, error)
+	GetMarkedTitles(tgId int, since time.Time) ([]string, error)
+	ExcludeKeyword(tgId int, keyword string) error
+}
+
+type Click interface {
+	CreateUserLink(tgId int, task core.Task) (int, error)
+	CreateChannelLink(apiId int, task core.Task) (int, error)
+	GetUserLink(tgId int, url string) (int, error)
+	GetChannelLink(apiId int, url string) (int, error)
+	LogClick(linkId int) (string, error)
+	GetCategoryRates(from, to time.Time) ([]core.ClickRateResponse, error)
+	GetSourceRates(from, to time.Time) ([]core.ClickRateResponse, error)
+}
+
+type Stats interface {
+	AddUserMatches(matches []core.MatchInput) error
+	AddChannelMatches(matches []core.MatchInput) error
+	GetTaskCounts(from, to time.Time) ([]core.TaskCountResponse, error)
+	GetDeliveryCounts(from, to time.Time) ([]core.DeliveryCountResponse, error)
+	GetSubscriberCounts() (core.SubscriberCountResponse, error)
+	GetDeliveryLatency(from, to time.Time) (core.LatencyResponse, error)
+	GetTopCategories(from, to time.Time, limit int) ([]core.CategoryCountResponse, error)
+}
+
+type ApiKey interface {
+	Create(name, keyHash, signingSecret string, scopes []string) (int, error)
+	Authenticate(keyHash string) (core.ApiKey, error)
+	GetSigningKey(id int) (core.ApiKey, string, error)
+	MarkUsed(id int) error
+	GetAllSigningSecrets() ([]core.ApiKeySecret, error)
+	ReplaceSigningSecret(id int, oldSecret, newSecret string) (bool, error)
+	GetAll() ([]core.ApiKeyResponse, error)
+	Revoke(id int) error
 }
 
 type Repository struct {
 	Channel
 	User
 	Task
+	Digest
+	Template
+	Feedback
+	Click
+	Stats
+	ApiKey
 }
 
 func NewPostgresRepos(db *sqlx.DB) *Repository {
 	return &Repository{
-		Channel: NewChannelPostgres(db),
-		User:    NewUserPostgres(db),
-		Task:    NewTaskPostgres(db),
+		Channel:  NewChannelPostgres(db),
+		User:     NewUserPostgres(db),
+		Task:     NewTaskPostgres(db),
+		Digest:   NewDigestPostgres(db),
+		Template: NewTemplatePostgres(db),
+		Feedback: NewFeedbackPostgres(db),
+		Click:    NewClickPostgres(db),
+		Stats:    NewStatsPostgres(db),
+		ApiKey:   NewApiKeyPostgres(db),
 	}
 }
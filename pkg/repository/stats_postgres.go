@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ingestedTasksQuery lists stored and archived tasks for ingestion counts.
+var ingestedTasksQuery = fmt.Sprintf(`SELECT fl_name, category_id, ingested_at FROM %s
+	UNION ALL SELECT fl_name, category_id, ingested_at FROM %s`, freelanceTasksTable, freelanceTasksArchiveTable)
+
+type StatsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewStatsPostgres(db *sqlx.DB) *StatsPostgres {
+	return &StatsPostgres{db: db}
+}
+
+func (r *StatsPostgres) AddUserMatches(matches []core.MatchInput) error {
+	return r.addMatches(userDigestRecipient, matches)
+}
+
+func (r *StatsPostgres) AddChannelMatches(matches []core.MatchInput) error {
+	return r.addMatches(channelDigestRecipient, matches)
+}
+
+// GetTaskCounts counts tasks ingested in [from, to) per day, source and
+// category.
+func (r *StatsPostgres) GetTaskCounts(from, to time.Time) ([]core.TaskCountResponse, error) {
+	var counts []core.TaskCountResponse
+
+	query := fmt.Sprintf(`SELECT to_char(flt.ingested_at, 'YYYY-MM-DD') AS day, flt.fl_name AS source,
+		c.name AS category, count(*) AS tasks FROM (%s) flt
+		INNER JOIN %s c ON c.id = flt.category_id
+		WHERE flt.ingested_at >= $1 AND flt.ingested_at < $2
+		GROUP BY day, source, category ORDER BY day, source, category;`,
+		ingestedTasksQuery, categoriesTable)
+
+	if err := r.db.Select(&counts, query, from, to); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetDeliveryCounts counts matched tasks and delivery reports in [from, to)
+// for every user and channel.
+func (r *StatsPostgres) GetDeliveryCounts(from, to time.Time) ([]core.DeliveryCountResponse, error) {
+	var counts []core.DeliveryCountResponse
+
+	query := fmt.Sprintf(`%s UNION ALL %s ORDER BY type DESC, key;`,
+		deliveryCountsQuery("user", userDigestRecipient), deliveryCountsQuery("channel", channelDigestRecipient))
+
+	if err := r.db.Select(&counts, query, from, to); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *StatsPostgres) GetSubscriberCounts() (core.SubscriberCountResponse, error) {
+	var counts core.SubscriberCountResponse
+
+	query := fmt.Sprintf(`SELECT
+		(SELECT count(*) FROM %s WHERE status = '%s') AS active_users,
+		(SELECT count(*) FROM %s) AS users,
+		(SELECT count(*) FROM %s WHERE status = '%s') AS active_channels,
+		(SELECT count(*) FROM %s) AS channels;`,
+		usersTable, core.StatusActive, usersTable, channelsTable, core.StatusActive, channelsTable)
+
+	err := r.db.Get(&counts, query)
+	return counts, err
+}
+
+// GetDeliveryLatency returns the median time between publication of a task
+// and its successful delivery for deliveries reported in [from, to).
+func (r *StatsPostgres) GetDeliveryLatency(from, to time.Time) (core.LatencyResponse, error) {
+	var latency core.LatencyResponse
+
+	query := fmt.Sprintf(`SELECT count(*) AS deliveries,
+		coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY extract(epoch FROM d.created_at - m.published_at)), 0)
+		AS median_seconds FROM %s d
+		INNER JOIN %s m ON m.task_url = d.task_url AND (m.user_id = d.user_id OR m.channel_id = d.channel_id)
+		WHERE d.is_delivered AND m.published_at IS NOT NULL AND d.created_at >= $1 AND d.created_at < $2;`,
+		deliveriesTable, matchesTable)
+
+	err := r.db.Get(&latency, query, from, to)
+	return latency, err
+}
+
+// GetTopCategories returns up to limit categories with the most matches in
+// [from, to), along with the number of tasks ingested in them.
+func (r *StatsPostgres) GetTopCategories(from, to time.Time, limit int) ([]core.CategoryCountResponse, error) {
+	var counts []core.CategoryCountResponse
+
+	query := fmt.Sprintf(`SELECT c.id AS category_id, c.name AS category,
+		(SELECT count(*) FROM (%s) flt WHERE flt.category_id = c.id
+			AND flt.ingested_at >= $1 AND flt.ingested_at < $2) AS tasks,
+		(SELECT count(*) FROM %s m WHERE m.category_id = c.id
+			AND m.matched_at >= $1 AND m.matched_at < $2) AS matches
+		FROM %s c ORDER BY matches DESC, tasks DESC, c.id LIMIT $3;`,
+		ingestedTasksQuery, matchesTable, categoriesTable)
+
+	if err := r.db.Select(&counts, query, from, to, limit); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *StatsPostgres) addMatches(recipient digestRecipient, matches []core.MatchInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		addMatchQuery := fmt.Sprintf(`INSERT INTO %s (%s, task_url, fl_name, category_id, published_at)
+			SELECT id, $2, $3, $4, $5 FROM %s WHERE %s = $1 ON CONFLICT DO NOTHING;`,
+			matchesTable, recipient.refColumn, recipient.table, recipient.keyColumn)
+
+		if _, err := tx.Exec(addMatchQuery, match.Key, match.Url, match.FLName, match.CategoryId,
+			match.PublishedAt); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func deliveryCountsQuery(recipientType string, recipient digestRecipient) string {
+	return fmt.Sprintf(`SELECT '%s' AS type, r.%s AS key,
+		(SELECT count(*) FROM %s m WHERE m.%s = r.id AND m.matched_at >= $1 AND m.matched_at < $2) AS matches,
+		count(d.id) FILTER (WHERE d.is_delivered) AS delivered,
+		count(d.id) FILTER (WHERE NOT d.is_delivered) AS failed
+		FROM %s r LEFT JOIN %s d ON d.%s = r.id AND d.created_at >= $1 AND d.created_at < $2
+		GROUP BY r.id, r.%s`,
+		recipientType, recipient.keyColumn, matchesTable, recipient.refColumn,
+		recipient.table, deliveriesTable, recipient.refColumn, recipient.keyColumn)
+}
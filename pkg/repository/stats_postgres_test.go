@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestStatsPostgres_AddUserMatches(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewStatsPostgres(db)
+	published := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	type mockBehavior func(matches []core.MatchInput)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		matches      []core.MatchInput
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			matches: []core.MatchInput{
+				{Key: 1111, Url: "https://example.com/task/1", FLName: "Habr Freelance", CategoryId: 1, PublishedAt: &published},
+				{Key: 1111, Url: "https://example.com/task/2", FLName: "Habr Freelance", CategoryId: 2},
+			},
+			mockBehavior: func(matches []core.MatchInput) {
+				mock.ExpectBegin()
+				for _, match := range matches {
+					mock.ExpectExec("INSERT INTO matches (.+) SELECT (.+) FROM users WHERE tg_id = (.+)").
+						WithArgs(match.Key, match.Url, match.FLName, match.CategoryId, match.PublishedAt).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Failed Insert",
+			matches: []core.MatchInput{
+				{Key: 1111, Url: "https://example.com/task/1", FLName: "Habr Freelance", CategoryId: 1},
+			},
+			mockBehavior: func(matches []core.MatchInput) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO matches (.+) SELECT (.+) FROM users WHERE tg_id = (.+)").
+					WithArgs(matches[0].Key, matches[0].Url, matches[0].FLName, matches[0].CategoryId, matches[0].PublishedAt).
+					WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.matches)
+
+			err := r.AddUserMatches(testCase.matches)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStatsPostgres_GetTaskCounts(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewStatsPostgres(db)
+	from := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"day", "source", "category", "tasks"}).
+		AddRow("2021-10-01", "Habr Freelance", "Backend", 12).
+		AddRow("2021-10-02", "FL.ru", "Design", 3)
+	mock.ExpectQuery("SELECT (.+) FROM freelance_tasks UNION ALL SELECT (.+) FROM freelance_tasks_archive(.+) GROUP BY day, source, category").
+		WithArgs(from, to).WillReturnRows(rows)
+
+	got, err := r.GetTaskCounts(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []core.TaskCountResponse{
+		{Day: "2021-10-01", Source: "Habr Freelance", Category: "Backend", Tasks: 12},
+		{Day: "2021-10-02", Source: "FL.ru", Category: "Design", Tasks: 3},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +12,10 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// latestBatchCondition keeps the tasks of the last parse in feeds now that
+// earlier batches stay in freelance_tasks.
+var latestBatchCondition = fmt.Sprintf("flt.ingested_at = (SELECT max(ingested_at) FROM %s)", freelanceTasksTable)
+
 type TaskPostgres struct {
 	db *sqlx.DB
 }
@@ -18,16 +24,26 @@ func NewTaskPostgres(db *sqlx.DB) *TaskPostgres {
 	return &TaskPostgres{db: db}
 }
 
-func (r *TaskPostgres) GetOrCreateCategoryByName(name string) (int, error) {
-	var id int
+// GetOrCreateCategoryByName returns the id of the category, creating it
+// when needed. A category created by a concurrent call is selected again
+// instead of failing the batch.
+func (r *TaskPostgres) GetOrCreateCategoryByName(name string) (id int, err error) {
+	defer func() { err = translateError(err) }()
 
-	getQuery := fmt.Sprintf("SELECT id FROM %s WHERE lower(name) = $1;", categoriesTable)
+	getQuery := fmt.Sprintf("SELECT id FROM %s WHERE lower(name) = $1 ORDER BY id LIMIT 1;", categoriesTable)
 
 	row := r.db.QueryRow(getQuery, strings.ToLower(name))
 	if err := row.Scan(&id); err != nil {
-		createQuery := fmt.Sprintf("INSERT INTO %s (name) VALUES ($1) RETURNING id;", categoriesTable)
+		createQuery := fmt.Sprintf("INSERT INTO %s (name) VALUES ($1) ON CONFLICT (name) DO NOTHING RETURNING id;",
+			categoriesTable)
 		row = r.db.QueryRow(createQuery, name)
-		if err := row.Scan(&id); err != nil {
+
+		err = row.Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			row = r.db.QueryRow(getQuery, strings.ToLower(name))
+			err = row.Scan(&id)
+		}
+		if err != nil {
 			return 0, err
 		}
 	}
@@ -65,16 +81,22 @@ func (r *TaskPostgres) SetLastParseTime() error {
 	return nil
 }
 
-func (r *TaskPostgres) GetAllForChannels() ([]core.ChannelTaskResponse, error) {
-	var tasks []core.ChannelTaskResponse
+// GetAllForChannels returns the tasks of the latest batch that match active
+// channels and were not delivered to them yet.
+func (r *TaskPostgres) GetAllForChannels() ([]core.ChannelTask, error) {
+	var tasks []core.ChannelTask
 
-	query := fmt.Sprintf(`SELECT ch.api_id, ch.api_hash, flt.title, flt.body, flt.task_url FROM %s ch
+	query := fmt.Sprintf(`SELECT ch.api_id, chs.template_id, chs.language, %s FROM %s ch
 		INNER JOIN %s chs ON ch.id = chs.channel_id
 		INNER JOIN %s flt ON flt.is_budget = chs.is_budget AND flt.is_term = chs.is_term AND
 		flt.is_safe_deal = chs.is_safe_deal AND
 		flt.category_id in (SELECT category_id FROM %s WHERE channel_setting_id = chs.id)
+		INNER JOIN %s c ON c.id = flt.category_id
+		WHERE ch.status = '%s' AND %s AND NOT EXISTS (SELECT 1 FROM %s d
+		WHERE d.channel_id = ch.id AND d.task_id = flt.id AND d.is_delivered)
 		ORDER BY ch.id, flt.id;`,
-		channelsTable, channelSettingsTable, freelanceTasksTable, channelCategoriesTable)
+		taskColumns, channelsTable, channelSettingsTable, freelanceTasksTable, channelCategoriesTable,
+		categoriesTable, core.StatusActive, latestBatchCondition, deliveriesTable)
 
 	if err := r.db.Select(&tasks, query); err != nil {
 		return nil, err
@@ -83,16 +105,25 @@ func (r *TaskPostgres) GetAllForChannels() ([]core.ChannelTaskResponse, error) {
 	return tasks, nil
 }
 
-func (r *TaskPostgres) GetAllForUsers() ([]core.UserTaskResponse, error) {
-	var tasks []core.UserTaskResponse
+// GetAllForUsers returns the tasks of the latest batch that match active
+// users and were not delivered to them yet.
+func (r *TaskPostgres) GetAllForUsers() ([]core.UserTask, error) {
+	var tasks []core.UserTask
 
-	query := fmt.Sprintf(`SELECT u.tg_id, flt.title, flt.body, flt.task_url FROM %s u
+	query := fmt.Sprintf(`SELECT u.tg_id, us.template_id, us.language, %s FROM %s u
 		INNER JOIN %s us ON u.id = us.user_id
 		INNER JOIN %s flt ON flt.is_budget = us.is_budget AND flt.is_term = us.is_term AND
 		flt.is_safe_deal = us.is_safe_deal AND
 		flt.category_id in (SELECT category_id FROM %s WHERE user_setting_id = us.id)
+		INNER JOIN %s c ON c.id = flt.category_id
+		WHERE u.status = '%s' AND %s AND NOT EXISTS (SELECT 1 FROM %s WHERE user_id = u.id AND task_url = flt.task_url)
+		AND NOT EXISTS (SELECT 1 FROM %s ek WHERE ek.user_setting_id = us.id AND
+		strpos(lower(flt.title || ' ' || flt.description), ek.keyword) > 0)
+		AND NOT EXISTS (SELECT 1 FROM %s d WHERE d.user_id = u.id AND d.task_id = flt.id AND d.is_delivered)
 		ORDER BY u.id, flt.id;`,
-		usersTable, userSettingsTable, freelanceTasksTable, userCategoriesTable)
+		taskColumns, usersTable, userSettingsTable, freelanceTasksTable, userCategoriesTable,
+		categoriesTable, core.StatusActive, latestBatchCondition, hiddenTasksTable, excludedKeywordsTable,
+		deliveriesTable)
 
 	if err := r.db.Select(&tasks, query); err != nil {
 		return nil, err
@@ -101,7 +132,12 @@ func (r *TaskPostgres) GetAllForUsers() ([]core.UserTaskResponse, error) {
 	return tasks, nil
 }
 
-func (r *TaskPostgres) AddTasks(tasksInput core.TasksInput) error {
+// AddTasks stores a parsed batch. All tasks of the batch share ingested_at,
+// tasks that were already stored are updated and moved to the batch. It
+// returns core.ErrInvalidReference when a category is missing.
+func (r *TaskPostgres) AddTasks(tasksInput core.TasksInput) (err error) {
+	defer func() { err = translateError(err) }()
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
@@ -116,16 +152,107 @@ func (r *TaskPostgres) AddTasks(tasksInput core.TasksInput) error {
 			return err
 		}
 
-		body, err := getTaskBody(task, isBudget, isTerm)
-		if err != nil {
+		createTaskQuery := fmt.Sprintf(`INSERT INTO %s (task_url, title, category_id, is_budget, is_term, is_safe_deal,
+			fl_name, fl_url, description, budget, is_budget_per_hour, term, datetime)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (task_url) DO UPDATE SET title = EXCLUDED.title, category_id = EXCLUDED.category_id,
+			is_budget = EXCLUDED.is_budget, is_term = EXCLUDED.is_term, is_safe_deal = EXCLUDED.is_safe_deal,
+			fl_name = EXCLUDED.fl_name, fl_url = EXCLUDED.fl_url, description = EXCLUDED.description,
+			budget = EXCLUDED.budget, is_budget_per_hour = EXCLUDED.is_budget_per_hour, term = EXCLUDED.term,
+			datetime = EXCLUDED.datetime, ingested_at = EXCLUDED.ingested_at;`, freelanceTasksTable)
+
+		if _, err := tx.Exec(createTaskQuery, task.TaskUrl, task.Title, categoryId, isBudget, isTerm, task.IsSafeDeal,
+			task.FLName, task.FLUrl, task.Description, task.Budget, task.IsBudgetPerHour, task.Term,
+			task.DateTime); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
 			return err
 		}
+	}
+
+	return tx.Commit()
+}
+
+// ArchiveTasks moves tasks ingested before the given time, except for the
+// latest batch, to the archive and returns how many were moved. Monthly
+// archive partitions are created as needed.
+func (r *TaskPostgres) ArchiveTasks(before time.Time) (int64, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	var months []time.Time
+
+	monthsQuery := fmt.Sprintf(`SELECT DISTINCT date_trunc('month', ingested_at AT TIME ZONE 'UTC') FROM %s
+		WHERE ingested_at < $1 AND ingested_at < (SELECT max(ingested_at) FROM %s);`,
+		freelanceTasksTable, freelanceTasksTable)
+
+	if err := tx.Select(&months, monthsQuery, before); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	for _, month := range months {
+		month = month.UTC()
+		partitionQuery := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_%s PARTITION OF %s
+			FOR VALUES FROM ('%s') TO ('%s');`,
+			freelanceTasksArchiveTable, month.Format("2006_01"), freelanceTasksArchiveTable,
+			month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))
+
+		if _, err := tx.Exec(partitionQuery); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return 0, err
+			}
+			return 0, err
+		}
+	}
+
+	archiveQuery := fmt.Sprintf(`WITH archived AS (
+			DELETE FROM %s WHERE ingested_at < $1 AND ingested_at < (SELECT max(ingested_at) FROM %s)
+			RETURNING %s)
+		INSERT INTO %s (%s) SELECT %s FROM archived;`,
+		freelanceTasksTable, freelanceTasksTable, archiveColumns,
+		freelanceTasksArchiveTable, archiveColumns, archiveColumns)
+
+	result, err := tx.Exec(archiveQuery, before)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	return count, tx.Commit()
+}
+
+// HoldUserTasks keeps tasks for the user while they are in their quiet
+// hours. Held tasks are released by delivery reports or by a quiet digest.
+func (r *TaskPostgres) HoldUserTasks(tgId int, tasks []core.UserTaskResponse) (err error) {
+	defer func() { err = translateError(err) }()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
 
-		createTaskQuery := fmt.Sprintf(`INSERT INTO %s (task_url, title, body, category_id, is_budget, is_term, is_safe_deal)
-			VALUES ($1, $2, $3, $4, $5, $6, $7);`, freelanceTasksTable)
+	for _, task := range tasks {
+		holdTaskQuery := fmt.Sprintf(`INSERT INTO %s (user_id, task_url, title, body, task_id)
+			SELECT id, $2, $3, $4, NULLIF($5, 0) FROM %s WHERE tg_id = $1
+			ON CONFLICT (user_id, task_url) DO NOTHING;`, heldTasksTable, usersTable)
 
-		if _, err := tx.Exec(createTaskQuery, task.TaskUrl, task.Title, body,
-			categoryId, isBudget, isTerm, task.IsSafeDeal); err != nil {
+		if _, err := tx.Exec(holdTaskQuery, tgId, task.Url, task.Title, task.Body, task.TaskId); err != nil {
 			if err := tx.Rollback(); err != nil {
 				return err
 			}
@@ -136,40 +263,99 @@ func (r *TaskPostgres) AddTasks(tasksInput core.TasksInput) error {
 	return tx.Commit()
 }
 
-func (r *TaskPostgres) DeleteAll() error {
-	query := fmt.Sprintf("DELETE FROM %s;", freelanceTasksTable)
-	if _, err := r.db.Exec(query); err != nil {
-		return err
+// GetHeldTasks returns the tasks held for active users in the order they
+// were held.
+func (r *TaskPostgres) GetHeldTasks() ([]core.UserTaskResponse, error) {
+	var tasks []core.UserTaskResponse
+
+	query := fmt.Sprintf(`SELECT u.tg_id, coalesce(ht.task_id, 0) AS task_id, ht.title, ht.body, ht.task_url
+		FROM %s ht INNER JOIN %s u ON u.id = ht.user_id
+		WHERE u.status = '%s' ORDER BY ht.id;`, heldTasksTable, usersTable, core.StatusActive)
+
+	if err := r.db.Select(&tasks, query); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return tasks, nil
 }
 
-func getTaskBody(task core.TaskDataInput, isBudget, isTerm bool) (string, error) {
-	var body string
-	var budget string
-	term := "не указаны"
-	safeDeal := ""
+// taskSearchRow is a found task along with the number of all matches.
+type taskSearchRow struct {
+	core.Task
+	Total int `db:"total"`
+}
 
-	if isBudget {
-		budget = fmt.Sprintf("%d ", task.Budget)
-		if task.IsBudgetPerHour {
-			budget += "в час"
-		}
-	} else {
-		budget = "не указан"
+// Search looks for stored and archived tasks. Tasks are ranked by the full
+// text match of q when it is set and by ingestion time otherwise.
+func (r *TaskPostgres) Search(filter core.TaskSearchFilter) ([]core.Task, int, error) {
+	var rows []taskSearchRow
+
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	orderBy := "flt.ingested_at DESC, flt.id DESC"
+	if filter.Query != "" {
+		conditions = append(conditions, fmt.Sprintf("flt.search_vector @@ websearch_to_tsquery('russian', $%d)", argId))
+		orderBy = fmt.Sprintf("ts_rank(flt.search_vector, websearch_to_tsquery('russian', $%d)) DESC, %s",
+			argId, orderBy)
+		args = append(args, filter.Query)
+		argId++
+	}
+
+	if filter.Category != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(c.name) = lower($%d)", argId))
+		args = append(args, filter.Category)
+		argId++
+	}
+
+	if filter.MinBudget > 0 {
+		conditions = append(conditions, fmt.Sprintf("flt.budget >= $%d", argId))
+		args = append(args, filter.MinBudget)
+		argId++
+	}
+
+	if filter.Source != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(flt.fl_name) = lower($%d)", argId))
+		args = append(args, filter.Source)
+		argId++
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("flt.ingested_at >= $%d", argId))
+		args = append(args, filter.From)
+		argId++
 	}
 
-	if isTerm {
-		term = task.Term
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("flt.ingested_at < $%d", argId))
+		args = append(args, filter.To)
+		argId++
 	}
 
-	if task.IsSafeDeal {
-		safeDeal = "Безопасная сделка!\n"
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	body = fmt.Sprintf("Заказ с %s\n\nКатегория: %s\n\nОписание:\n%s\n\n%sБюджет: %s\nСроки: %s\nВремя публикации: %s",
-		task.FLName, task.Category, task.Description, safeDeal, budget, term, task.DateTime)
+	query := fmt.Sprintf(`SELECT %s, count(*) OVER () AS total FROM (
+			SELECT %s FROM %s UNION ALL SELECT %s FROM %s) flt
+		INNER JOIN %s c ON c.id = flt.category_id
+		%s ORDER BY %s LIMIT $%d OFFSET $%d;`,
+		taskColumns, searchColumns, freelanceTasksTable, searchColumns, freelanceTasksArchiveTable,
+		categoriesTable, where, orderBy, argId, argId+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	tasks := make([]core.Task, 0, len(rows))
+	total := 0
+	for _, row := range rows {
+		tasks = append(tasks, row.Task)
+		total = row.Total
+	}
 
-	return body, nil
+	return tasks, total, nil
 }
@@ -2,8 +2,8 @@ package repository
 
 import (
 	"errors"
-	"fmt"
 	"testing"
+	"time"
 
 	core "github.com/max-sanch/BotFreelancer-core"
 
@@ -33,6 +33,20 @@ func TestTaskPostgres_GetOrCreateCategoryByName(t *testing.T) {
 		id           int
 		wantErr      bool
 	}{
+		{
+			name: "Created Concurrently",
+			args: args{
+				name: "Category",
+			},
+			id: 3,
+			mockBehavior: func(args args, id int) {
+				mock.ExpectQuery("INSERT INTO categories (.+) ON CONFLICT \\(name\\) DO NOTHING").
+					WithArgs(args.name).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
+				mock.ExpectQuery("SELECT id FROM categories WHERE (.+)").WithArgs("category").WillReturnRows(rows)
+			},
+		},
 		{
 			name: "OK",
 			args: args{
@@ -122,6 +136,20 @@ func TestTaskPostgres_GetLastParseTime(t *testing.T) {
 	}
 }
 
+var testTask = core.Task{
+	Id:          1,
+	FLName:      "fl",
+	FLUrl:       "fl-url",
+	Url:         "test-url",
+	CategoryId:  1,
+	Category:    "category",
+	Title:       "test",
+	Description: "test-description",
+	Budget:      1000,
+	IsSafeDeal:  true,
+	DateTime:    "test-datetime",
+}
+
 func TestTaskPostgres_GetAllForChannels(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 	if err != nil {
@@ -130,55 +158,49 @@ func TestTaskPostgres_GetAllForChannels(t *testing.T) {
 	defer db.Close()
 
 	r := NewTaskPostgres(db)
+	templateId := 2
+	columns := []string{"api_id", "template_id", "language", "id", "fl_name", "fl_url", "task_url", "category_id",
+		"category", "title", "description", "budget", "is_budget_per_hour", "term", "is_safe_deal", "datetime"}
 
 	testTable := []struct {
 		name         string
 		mockBehavior func()
-		want         []core.ChannelTaskResponse
+		want         []core.ChannelTask
 		wantErr      bool
 	}{
 		{
 			name: "OK",
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"api_id", "api_hash", "title", "body", "task_url"}).
-					AddRow(1111, "hash1111", "test", "test-body", "test-url").
-					AddRow(1111, "hash1111", "test2", "test-body2", "test-url2").
-					AddRow(3333, "hash3333", "test", "test-body", "test-url")
+				rows := sqlmock.NewRows(columns).
+					AddRow(1111, nil, "ru", 1, "fl", "fl-url", "test-url", 1, "category", "test",
+						"test-description", 1000, false, "", true, "test-datetime").
+					AddRow(3333, templateId, "en", 1, "fl", "fl-url", "test-url", 1, "category", "test",
+						"test-description", 1000, false, "", true, "test-datetime")
 				mock.ExpectQuery("SELECT (.+) FROM channels ch INNER JOIN channel_settings chs ON (.+) INNER JOIN freelance_tasks flt ON (.+)").
 					WillReturnRows(rows)
 			},
-			want: []core.ChannelTaskResponse{
-				{
-					ApiId:   1111,
-					ApiHash: "hash1111",
-					Title:   "test",
-					Body:    "test-body",
-					Url:     "test-url",
-				},
+			want: []core.ChannelTask{
 				{
-					ApiId:   1111,
-					ApiHash: "hash1111",
-					Title:   "test2",
-					Body:    "test-body2",
-					Url:     "test-url2",
+					ApiId:    1111,
+					Language: "ru",
+					Task:     testTask,
 				},
 				{
-					ApiId:   3333,
-					ApiHash: "hash3333",
-					Title:   "test",
-					Body:    "test-body",
-					Url:     "test-url",
+					ApiId:      3333,
+					Language:   "en",
+					TemplateId: &templateId,
+					Task:       testTask,
 				},
 			},
 		},
 		{
 			name: "Not Found",
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"api_id", "api_hash", "title", "body", "task_url"})
+				rows := sqlmock.NewRows(columns)
 				mock.ExpectQuery("SELECT (.+) FROM channels ch INNER JOIN channel_settings chs ON (.+) INNER JOIN freelance_tasks flt ON (.+)").
 					WillReturnRows(rows)
 			},
-			want: []core.ChannelTaskResponse(nil),
+			want: []core.ChannelTask(nil),
 		},
 	}
 
@@ -206,52 +228,47 @@ func TestTaskPostgres_GetAllForUsers(t *testing.T) {
 	defer db.Close()
 
 	r := NewTaskPostgres(db)
+	columns := []string{"tg_id", "template_id", "language", "id", "fl_name", "fl_url", "task_url", "category_id",
+		"category", "title", "description", "budget", "is_budget_per_hour", "term", "is_safe_deal", "datetime"}
 
 	testTable := []struct {
 		name         string
 		mockBehavior func()
-		want         []core.UserTaskResponse
+		want         []core.UserTask
 		wantErr      bool
 	}{
 		{
 			name: "OK",
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"tg_id", "title", "body", "task_url"}).
-					AddRow(1111, "test", "test-body", "test-url").
-					AddRow(1111, "test2", "test-body2", "test-url2").
-					AddRow(3333, "test", "test-body", "test-url")
+				rows := sqlmock.NewRows(columns).
+					AddRow(1111, nil, "ru", 1, "fl", "fl-url", "test-url", 1, "category", "test",
+						"test-description", 1000, false, "", true, "test-datetime").
+					AddRow(3333, nil, "ru", 1, "fl", "fl-url", "test-url", 1, "category", "test",
+						"test-description", 1000, false, "", true, "test-datetime")
 				mock.ExpectQuery("SELECT (.+) FROM users u INNER JOIN user_settings us ON (.+) INNER JOIN freelance_tasks flt ON (.+)").
 					WillReturnRows(rows)
 			},
-			want: []core.UserTaskResponse{
+			want: []core.UserTask{
 				{
-					TgId:  1111,
-					Title: "test",
-					Body:  "test-body",
-					Url:   "test-url",
+					TgId:     1111,
+					Language: "ru",
+					Task:     testTask,
 				},
 				{
-					TgId:  1111,
-					Title: "test2",
-					Body:  "test-body2",
-					Url:   "test-url2",
-				},
-				{
-					TgId:  3333,
-					Title: "test",
-					Body:  "test-body",
-					Url:   "test-url",
+					TgId:     3333,
+					Language: "ru",
+					Task:     testTask,
 				},
 			},
 		},
 		{
 			name: "Not Found",
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"tg_id", "title", "body", "task_url"})
+				rows := sqlmock.NewRows(columns)
 				mock.ExpectQuery("SELECT (.+) FROM users u INNER JOIN user_settings us ON (.+) INNER JOIN freelance_tasks flt ON (.+)").
 					WillReturnRows(rows)
 			},
-			want: []core.UserTaskResponse(nil),
+			want: []core.UserTask(nil),
 		},
 	}
 
@@ -334,19 +351,10 @@ func TestTaskPostgres_AddTasks(t *testing.T) {
 					mock.ExpectQuery("INSERT INTO categories").
 						WillReturnRows(rows)
 
-					budget := fmt.Sprintf("%d ", task.Budget)
-					term := "не указаны"
-					safeDeal := ""
-
-					if task.IsSafeDeal {
-						safeDeal = "Безопасная сделка!\n"
-					}
-
-					body := fmt.Sprintf("Заказ с %s\n\nКатегория: %s\n\nОписание:\n%s\n\n%sБюджет: %s\nСроки: %s\nВремя публикации: %s",
-						task.FLName, task.Category, task.Description, safeDeal, budget, term, task.DateTime)
-
 					mock.ExpectExec("INSERT INTO freelance_tasks").
-						WithArgs(task.TaskUrl, task.Title, body, args.categoryId, true, false, task.IsSafeDeal).
+						WithArgs(task.TaskUrl, task.Title, args.categoryId, true, false, task.IsSafeDeal,
+							task.FLName, task.FLUrl, task.Description, task.Budget, task.IsBudgetPerHour, task.Term,
+							task.DateTime).
 						WillReturnResult(sqlmock.NewResult(1, 1))
 				}
 
@@ -382,19 +390,10 @@ func TestTaskPostgres_AddTasks(t *testing.T) {
 					mock.ExpectQuery("INSERT INTO categories").
 						WillReturnRows(rows)
 
-					budget := fmt.Sprintf("%d ", task.Budget)
-					term := "не указаны"
-					safeDeal := ""
-
-					if task.IsSafeDeal {
-						safeDeal = "Безопасная сделка!\n"
-					}
-
-					body := fmt.Sprintf("Заказ с %s\n\nКатегория: %s\n\nОписание:\n%s\n\n%sБюджет: %s\nСроки: %s\nВремя публикации: %s",
-						task.FLName, task.Category, task.Description, safeDeal, budget, term, task.DateTime)
-
 					mock.ExpectExec("INSERT INTO freelance_tasks").
-						WithArgs(task.TaskUrl, task.Title, body, args.categoryId, true, false, task.IsSafeDeal).
+						WithArgs(task.TaskUrl, task.Title, args.categoryId, true, false, task.IsSafeDeal,
+							task.FLName, task.FLUrl, task.Description, task.Budget, task.IsBudgetPerHour, task.Term,
+							task.DateTime).
 						WillReturnError(errors.New("some error"))
 				}
 
@@ -418,3 +417,217 @@ func TestTaskPostgres_AddTasks(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskPostgres_GetHeldTasks(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewTaskPostgres(db)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		want         []core.UserTaskResponse
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func() {
+				rows := sqlmock.NewRows([]string{"tg_id", "task_id", "title", "body", "task_url"}).
+					AddRow(1111, 5, "test", "test-body", "test-url")
+				mock.ExpectQuery("SELECT (.+) FROM held_tasks ht INNER JOIN users u (.+)").WillReturnRows(rows)
+			},
+			want: []core.UserTaskResponse{
+				{
+					TgId:   1111,
+					TaskId: 5,
+					Title:  "test",
+					Body:   "test-body",
+					Url:    "test-url",
+				},
+			},
+		},
+		{
+			name: "Nothing Held",
+			mockBehavior: func() {
+				rows := sqlmock.NewRows([]string{"tg_id", "task_id", "title", "body", "task_url"})
+				mock.ExpectQuery("SELECT (.+) FROM held_tasks ht INNER JOIN users u (.+)").WillReturnRows(rows)
+			},
+			want: []core.UserTaskResponse(nil),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			got, err := r.GetHeldTasks()
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskPostgres_ArchiveTasks(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewTaskPostgres(db)
+	before := time.Date(2021, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func(before time.Time)
+		want         int64
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(before time.Time) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"date_trunc"}).
+					AddRow(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)).
+					AddRow(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC))
+				mock.ExpectQuery("SELECT DISTINCT (.+) FROM freelance_tasks WHERE ingested_at < (.+)").
+					WithArgs(before).WillReturnRows(rows)
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS freelance_tasks_archive_2021_10 PARTITION OF " +
+					"freelance_tasks_archive FOR VALUES FROM \\('2021-10-01T00:00:00Z'\\) TO \\('2021-11-01T00:00:00Z'\\)").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS freelance_tasks_archive_2021_11 PARTITION OF " +
+					"freelance_tasks_archive FOR VALUES FROM \\('2021-11-01T00:00:00Z'\\) TO \\('2021-12-01T00:00:00Z'\\)").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("WITH archived AS \\( DELETE FROM freelance_tasks (.+)\\) INSERT INTO freelance_tasks_archive").
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 42))
+				mock.ExpectCommit()
+			},
+			want: 42,
+		},
+		{
+			name: "Nothing To Archive",
+			mockBehavior: func(before time.Time) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"date_trunc"})
+				mock.ExpectQuery("SELECT DISTINCT (.+) FROM freelance_tasks WHERE ingested_at < (.+)").
+					WithArgs(before).WillReturnRows(rows)
+				mock.ExpectExec("WITH archived AS \\( DELETE FROM freelance_tasks (.+)\\) INSERT INTO freelance_tasks_archive").
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Failed Partition",
+			mockBehavior: func(before time.Time) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"date_trunc"}).AddRow(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+				mock.ExpectQuery("SELECT DISTINCT (.+) FROM freelance_tasks WHERE ingested_at < (.+)").
+					WithArgs(before).WillReturnRows(rows)
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS freelance_tasks_archive_2021_10").
+					WillReturnError(errors.New("partition error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(before)
+
+			got, err := r.ArchiveTasks(before)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskPostgres_Search(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewTaskPostgres(db)
+
+	columns := []string{"id", "fl_name", "fl_url", "task_url", "category_id", "category", "title", "description",
+		"budget", "is_budget_per_hour", "term", "is_safe_deal", "datetime", "total"}
+
+	testTable := []struct {
+		name         string
+		filter       core.TaskSearchFilter
+		mockBehavior func(filter core.TaskSearchFilter)
+		want         []core.Task
+		wantTotal    int
+		wantErr      bool
+	}{
+		{
+			name: "Full Text",
+			filter: core.TaskSearchFilter{
+				Query:     "golang",
+				MinBudget: 10000,
+				Source:    "FL.ru",
+				Limit:     20,
+			},
+			mockBehavior: func(filter core.TaskSearchFilter) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(7, "FL.ru", "https://fl.ru", "https://fl.ru/7", 1, "Backend", "Golang API", "REST API",
+						15000, false, "", true, "2021-10-02 10:00:00", 2)
+				mock.ExpectQuery("SELECT (.+) FROM freelance_tasks UNION ALL SELECT (.+) FROM freelance_tasks_archive(.+) "+
+					"WHERE flt.search_vector @@ websearch_to_tsquery\\('russian', \\$1\\) AND flt.budget >= \\$2 "+
+					"AND lower\\(flt.fl_name\\) = lower\\(\\$3\\) ORDER BY ts_rank(.+) LIMIT \\$4 OFFSET \\$5").
+					WithArgs(filter.Query, filter.MinBudget, filter.Source, filter.Limit, filter.Offset).WillReturnRows(rows)
+			},
+			want: []core.Task{
+				{Id: 7, FLName: "FL.ru", FLUrl: "https://fl.ru", Url: "https://fl.ru/7", CategoryId: 1, Category: "Backend",
+					Title: "Golang API", Description: "REST API", Budget: 15000, IsSafeDeal: true, DateTime: "2021-10-02 10:00:00"},
+			},
+			wantTotal: 2,
+		},
+		{
+			name: "No Filters",
+			filter: core.TaskSearchFilter{
+				Limit:  20,
+				Offset: 40,
+			},
+			mockBehavior: func(filter core.TaskSearchFilter) {
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery("SELECT (.+) FROM freelance_tasks UNION ALL SELECT (.+) FROM freelance_tasks_archive(.+) "+
+					"ORDER BY flt.ingested_at DESC, flt.id DESC LIMIT \\$1 OFFSET \\$2").
+					WithArgs(filter.Limit, filter.Offset).WillReturnRows(rows)
+			},
+			want: []core.Task{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.filter)
+
+			got, total, err := r.Search(testCase.filter)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+				assert.Equal(t, testCase.wantTotal, total)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
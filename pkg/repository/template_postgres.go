@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TemplatePostgres struct {
+	db *sqlx.DB
+}
+
+func NewTemplatePostgres(db *sqlx.DB) *TemplatePostgres {
+	return &TemplatePostgres{db: db}
+}
+
+func (r *TemplatePostgres) GetAll() ([]core.TemplateResponse, error) {
+	var templates []core.TemplateResponse
+
+	query := fmt.Sprintf("SELECT id, name, body FROM %s ORDER BY id;", templatesTable)
+	if err := r.db.Select(&templates, query); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (r *TemplatePostgres) Create(templateInput core.TemplateInput) (int, error) {
+	var id int
+
+	query := fmt.Sprintf("INSERT INTO %s (name, body) VALUES ($1, $2) RETURNING id;", templatesTable)
+	row := r.db.QueryRow(query, templateInput.Name, templateInput.Body)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *TemplatePostgres) Update(templateInput core.TemplateUpdateInput) (int, error) {
+	var id int
+
+	query := fmt.Sprintf("UPDATE %s SET name = $1, body = $2 WHERE id = $3 RETURNING id;", templatesTable)
+	row := r.db.QueryRow(query, templateInput.Name, templateInput.Body, templateInput.Id)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *TemplatePostgres) Delete(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1;", templatesTable)
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestTemplatePostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewTemplatePostgres(db)
+
+	type args struct {
+		template core.TemplateInput
+	}
+
+	type mockBehavior func(args args, id int)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		id           int
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				template: core.TemplateInput{
+					Name: "short",
+					Body: "{{.Title}}",
+				},
+			},
+			id: 1,
+			mockBehavior: func(args args, id int) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
+				mock.ExpectQuery("INSERT INTO templates").
+					WithArgs(args.template.Name, args.template.Body).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Duplicate Name",
+			args: args{
+				template: core.TemplateInput{
+					Name: "short",
+					Body: "{{.Title}}",
+				},
+			},
+			mockBehavior: func(args args, id int) {
+				mock.ExpectQuery("INSERT INTO templates").
+					WithArgs(args.template.Name, args.template.Body).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args, testCase.id)
+
+			got, err := r.Create(testCase.args.template)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.id, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+
 	"github.com/jmoiron/sqlx"
 	core "github.com/max-sanch/BotFreelancer-core"
 )
@@ -14,37 +17,60 @@ func NewUserPostgres(db *sqlx.DB) *UserPostgres {
 	return &UserPostgres{db: db}
 }
 
-func (r *UserPostgres) GetByTgId(tgId int) (core.UserResponse, error) {
-	var user core.UserResponse
+// GetByTgId returns the user with settings or core.ErrNotFound.
+func (r *UserPostgres) GetByTgId(tgId int) (user core.UserResponse, err error) {
+	defer func() { err = translateError(err) }()
+
 	var settingId int
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE tg_id = $1", usersTable)
+	query := fmt.Sprintf("SELECT id, tg_id, username, status, version FROM %s WHERE tg_id = $1", usersTable)
 	if err := r.db.Get(&user, query, tgId); err != nil {
 		return core.UserResponse{}, err
 	}
 
-	query = fmt.Sprintf("SELECT id, is_safe_deal, is_budget, is_term FROM %s WHERE user_id = $1",
-		userSettingsTable)
+	var quietHours core.QuietHours
+
+	query = fmt.Sprintf(`SELECT id, is_safe_deal, is_budget, is_term, delivery_mode, digest_time, template_id,
+		timezone, language, quiet_from, quiet_to, is_quiet_digest FROM %s WHERE user_id = $1`, userSettingsTable)
 	row := r.db.QueryRow(query, user.Id)
-	if err := row.Scan(&settingId, &user.Setting.IsSafeDeal, &user.Setting.IsBudget, &user.Setting.IsTerm); err != nil {
+	if err := row.Scan(&settingId, &user.Setting.IsSafeDeal, &user.Setting.IsBudget, &user.Setting.IsTerm,
+		&user.Setting.DeliveryMode, &user.Setting.DigestTime, &user.Setting.TemplateId,
+		&user.Timezone, &user.Language, &quietHours.From, &quietHours.To, &quietHours.IsDigest); err != nil {
 		return core.UserResponse{}, err
 	}
 
+	if quietHours.From != "" {
+		user.QuietHours = &quietHours
+	}
+
 	query = fmt.Sprintf("SELECT category_id FROM %s WHERE user_setting_id = $1", userCategoriesTable)
 	if err := r.db.Select(&user.Setting.Categories, query, settingId); err != nil {
 		return core.UserResponse{}, err
 	}
 
+	query = fmt.Sprintf("SELECT keyword FROM %s WHERE user_setting_id = $1 ORDER BY keyword", excludedKeywordsTable)
+	if err := r.db.Select(&user.Setting.ExcludedKeywords, query, settingId); err != nil {
+		return core.UserResponse{}, err
+	}
+
 	return user, nil
 }
 
-func (r *UserPostgres) Create(userInput core.UserInput) (int, error) {
+// Create stores a new user. It returns core.ErrAlreadyExists for a taken tg_id
+// and core.ErrInvalidReference for unknown categories or templates.
+func (r *UserPostgres) Create(userInput core.UserInput) (_ int, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(userInput.Setting); err != nil {
+		return 0, err
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return 0, err
 	}
 
-	var userId, userSettingId int
+	var userId int
 	createUserQuery := fmt.Sprintf("INSERT INTO %s (tg_id, username) VALUES ($1, $2) RETURNING id;", usersTable)
 
 	row := tx.QueryRow(createUserQuery, userInput.TgId, userInput.Username)
@@ -55,92 +81,369 @@ func (r *UserPostgres) Create(userInput core.UserInput) (int, error) {
 		return 0, err
 	}
 
-	if userInput.Setting.IsSafeDeal == nil || userInput.Setting.IsBudget == nil || userInput.Setting.IsTerm == nil {
+	if err := saveUserSetting(tx, userId, userInput, false); err != nil {
 		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
+		return 0, err
 	}
 
-	createUserSettingQuery := fmt.Sprintf("INSERT INTO %s (user_id, is_safe_deal, is_budget, is_term) VALUES ($1, $2, $3, $4) RETURNING id;",
-		userSettingsTable)
+	return userId, tx.Commit()
+}
 
-	row = tx.QueryRow(createUserSettingQuery, userId, *userInput.Setting.IsSafeDeal,
-		*userInput.Setting.IsBudget, *userInput.Setting.IsTerm)
-	if err := row.Scan(&userSettingId); err != nil {
+// Update replaces the settings of a user and increments its version. It
+// returns core.ErrNotFound for an unknown tg_id, core.ErrConflict when
+// userInput.Version is set and no longer current, and
+// core.ErrInvalidReference for unknown categories or templates.
+func (r *UserPostgres) Update(userInput core.UserInput) (_ int, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(userInput.Setting); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var userId int
+	updateUserQuery := fmt.Sprintf(`UPDATE %s SET username = $1, version = version + 1
+		WHERE tg_id = $2 AND ($3 = 0 OR version = $3) RETURNING id;`, usersTable)
+
+	row := tx.QueryRow(updateUserQuery, userInput.Username, userInput.TgId, userInput.Version)
+	if err := row.Scan(&userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = staleRecipientError(tx, usersTable, "tg_id", userInput.TgId, userInput.Version)
+		}
 		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
 		return 0, err
 	}
 
-	for _, categoryId := range userInput.Setting.Categories {
-		createUserCategoryQuery := fmt.Sprintf("INSERT INTO %s (user_setting_id, category_id) VALUES ($1, $2);",
-			userCategoriesTable)
-
-		if _, err := tx.Exec(createUserCategoryQuery, userSettingId, categoryId); err != nil {
-			if err := tx.Rollback(); err != nil {
-				return 0, err
-			}
+	if err := saveUserSetting(tx, userId, userInput, true); err != nil {
+		if err := tx.Rollback(); err != nil {
 			return 0, err
 		}
+		return 0, err
 	}
 
 	return userId, tx.Commit()
 }
 
-func (r *UserPostgres) Update(userInput core.UserInput) (int, error) {
+// Upsert creates the user or replaces the settings of an existing one and
+// reports whether the user was created. Excluded keywords of an existing
+// user are kept and a deleted user is reactivated. It returns
+// core.ErrConflict when userInput.Version is set and no longer current or
+// the user does not exist, and core.ErrInvalidReference for unknown
+// categories or templates.
+func (r *UserPostgres) Upsert(userInput core.UserInput) (_ int, created bool, err error) {
+	defer func() { err = translateError(err) }()
+
+	if err := checkRecipientSetting(userInput.Setting); err != nil {
+		return 0, false, err
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
 
-	var userId, userSettingId int
-	updateUserQuery := fmt.Sprintf("UPDATE %s SET username = $1 WHERE tg_id = $2 RETURNING id;", usersTable)
+	var userId int
+	// xmax is 0 only for rows inserted by the statement.
+	upsertUserQuery := fmt.Sprintf(`INSERT INTO %[1]s (tg_id, username) VALUES ($1, $2)
+		ON CONFLICT (tg_id) DO UPDATE SET username = EXCLUDED.username, version = %[1]s.version + 1,
+		status = CASE WHEN %[1]s.status = '%[2]s' THEN '%[3]s' ELSE %[1]s.status END,
+		failures = CASE WHEN %[1]s.status = '%[2]s' THEN 0 ELSE %[1]s.failures END
+		WHERE $3 = 0 OR %[1]s.version = $3 RETURNING id, xmax = 0;`, usersTable, core.StatusDeleted, core.StatusActive)
 
-	row := tx.QueryRow(updateUserQuery, userInput.Username, userInput.TgId)
-	if err := row.Scan(&userId); err != nil {
+	row := tx.QueryRow(upsertUserQuery, userInput.TgId, userInput.Username, userInput.Version)
+	if err := row.Scan(&userId, &created); err != nil {
+		// Only a stale version skips the row of an existing user.
+		if errors.Is(err, sql.ErrNoRows) {
+			err = typedError(core.ErrConflict, nil)
+		}
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
-		return 0, err
+		return 0, false, err
 	}
 
-	if userInput.Setting.IsSafeDeal == nil || userInput.Setting.IsBudget == nil || userInput.Setting.IsTerm == nil {
+	// A version can only match an existing user.
+	if created && userInput.Version != 0 {
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
+		return 0, false, typedError(core.ErrConflict, nil)
 	}
 
-	updateUserSettingQuery := fmt.Sprintf("UPDATE %s SET is_safe_deal = $1, is_budget = $2, is_term = $3 WHERE user_id = $4 RETURNING id;",
+	if err := saveUserSetting(tx, userId, userInput, !created); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, false, err
+		}
+		return 0, false, err
+	}
+
+	return userId, created, tx.Commit()
+}
+
+// saveUserSetting stores the settings and categories of the user. With
+// replace the existing settings and categories are overwritten.
+func saveUserSetting(tx *sql.Tx, userId int, userInput core.UserInput, replace bool) error {
+	var quietHours core.QuietHours
+	if userInput.QuietHours != nil {
+		quietHours = *userInput.QuietHours
+	}
+
+	var userSettingId int
+	saveUserSettingQuery := fmt.Sprintf(`INSERT INTO %s (user_id, is_safe_deal, is_budget, is_term,
+		delivery_mode, digest_time, template_id, timezone, language, quiet_from, quiet_to, is_quiet_digest)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id) DO UPDATE SET is_safe_deal = EXCLUDED.is_safe_deal, is_budget = EXCLUDED.is_budget,
+		is_term = EXCLUDED.is_term, delivery_mode = EXCLUDED.delivery_mode, digest_time = EXCLUDED.digest_time,
+		template_id = EXCLUDED.template_id, timezone = EXCLUDED.timezone, language = EXCLUDED.language,
+		quiet_from = EXCLUDED.quiet_from, quiet_to = EXCLUDED.quiet_to, is_quiet_digest = EXCLUDED.is_quiet_digest
+		RETURNING id;`,
 		userSettingsTable)
 
-	row = tx.QueryRow(updateUserSettingQuery, *userInput.Setting.IsSafeDeal, *userInput.Setting.IsBudget, *userInput.Setting.IsTerm, userId)
+	row := tx.QueryRow(saveUserSettingQuery, userId, *userInput.Setting.IsSafeDeal,
+		*userInput.Setting.IsBudget, *userInput.Setting.IsTerm, userInput.Setting.DeliveryMode,
+		userInput.Setting.DigestTime, userInput.Setting.TemplateId, userInput.Timezone, userInput.Language,
+		quietHours.From, quietHours.To, quietHours.IsDigest)
 	if err := row.Scan(&userSettingId); err != nil {
+		return err
+	}
+
+	return saveRecipientCategories(tx, userCategoriesTable, "user_setting_id", userSettingId,
+		userInput.Setting.Categories, replace)
+}
+
+// userListRow is a listed user along with the number of all users
+// matching the filter.
+type userListRow struct {
+	core.UserListItemResponse
+	Total int `db:"total"`
+}
+
+// GetAll returns a page of users ordered by id, optionally only those with
+// the given status.
+func (r *UserPostgres) GetAll(status string, limit, offset int) ([]core.UserListItemResponse, int, error) {
+	var rows []userListRow
+
+	query := fmt.Sprintf(`SELECT tg_id, username, status, count(*) OVER () AS total FROM %s
+		WHERE $1 = '' OR status = $1 ORDER BY id LIMIT $2 OFFSET $3;`, usersTable)
+
+	if err := r.db.Select(&rows, query, status, limit, offset); err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]core.UserListItemResponse, 0, len(rows))
+	total := 0
+	for _, row := range rows {
+		users = append(users, row.UserListItemResponse)
+		total = row.Total
+	}
+
+	return users, total, nil
+}
+
+// SoftDelete marks the user as deleted, which stops their deliveries until
+// they are upserted again or erased. It returns core.ErrNotFound when there
+// is no user to delete.
+func (r *UserPostgres) SoftDelete(tgId int) error {
+	var id int
+
+	query := fmt.Sprintf(`UPDATE %s SET status = '%s', version = version + 1
+		WHERE tg_id = $1 AND status != '%s' RETURNING id;`, usersTable, core.StatusDeleted, core.StatusDeleted)
+
+	row := r.db.QueryRow(query, tgId)
+	return translateError(row.Scan(&id))
+}
+
+// Erase removes the user with everything stored about them and records
+// the erasure under subjectHash in one transaction. It returns
+// core.ErrNotFound when the user does not exist.
+func (r *UserPostgres) Erase(tgId int, subjectHash string) (err error) {
+	defer func() { err = translateError(err) }()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var userId int
+	getUserQuery := fmt.Sprintf("SELECT id FROM %s WHERE tg_id = $1 FOR UPDATE;", usersTable)
+
+	row := tx.QueryRow(getUserQuery, tgId)
+	if err := row.Scan(&userId); err != nil {
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return err
 		}
-		return 0, err
+		return err
 	}
 
-	deleteUserCategoryQuery := fmt.Sprintf("DELETE FROM %s WHERE user_setting_id = $1;", userCategoriesTable)
+	deleteClicksQuery := fmt.Sprintf("DELETE FROM %s cl USING %s tl WHERE cl.link_id = tl.id AND tl.user_id = $1;",
+		clicksTable, trackedLinksTable)
 
-	if _, err := tx.Exec(deleteUserCategoryQuery, userSettingId); err != nil {
+	result, err := tx.Exec(deleteClicksQuery, userId)
+	if err != nil {
 		if err := tx.Rollback(); err != nil {
-			return 0, err
+			return err
 		}
-		return 0, err
+		return err
 	}
 
-	for _, categoryId := range userInput.Setting.Categories {
-		createUserCategoryQuery := fmt.Sprintf("INSERT INTO %s (user_setting_id, category_id) VALUES ($1, $2);",
-			userCategoriesTable)
+	clicks, err := result.RowsAffected()
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
 
-		if _, err := tx.Exec(createUserCategoryQuery, userSettingId, categoryId); err != nil {
-			if err := tx.Rollback(); err != nil {
-				return 0, err
-			}
-			return 0, err
+	deleteDeliveriesQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1;", deliveriesTable)
+
+	result, err = tx.Exec(deleteDeliveriesQuery, userId)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
 		}
+		return err
 	}
-	return userId, tx.Commit()
+
+	deliveries, err := result.RowsAffected()
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	// Settings, categories, links, matches, queues and feedback go with
+	// the user through on delete cascade.
+	deleteUserQuery := fmt.Sprintf("DELETE FROM %s WHERE id = $1;", usersTable)
+
+	if _, err := tx.Exec(deleteUserQuery, userId); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	createErasureQuery := fmt.Sprintf("INSERT INTO %s (subject_hash, deliveries, clicks) VALUES ($1, $2, $3);",
+		erasuresTable)
+
+	if _, err := tx.Exec(createErasureQuery, subjectHash, deliveries, clicks); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSubscriptions returns the categories the user is subscribed to.
+func (r *UserPostgres) GetSubscriptions(tgId int) ([]core.SubscriptionResponse, error) {
+	var subscriptions []core.SubscriptionResponse
+
+	query := fmt.Sprintf(`SELECT c.id AS category_id, c.name AS category FROM %s uc
+		INNER JOIN %s us ON us.id = uc.user_setting_id
+		INNER JOIN %s u ON u.id = us.user_id
+		INNER JOIN %s c ON c.id = uc.category_id
+		WHERE u.tg_id = $1 ORDER BY c.id;`,
+		userCategoriesTable, userSettingsTable, usersTable, categoriesTable)
+
+	if err := r.db.Select(&subscriptions, query, tgId); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetDeliveries returns the delivery reports received for the user.
+func (r *UserPostgres) GetDeliveries(tgId int) ([]core.DeliveryRecordResponse, error) {
+	var deliveries []core.DeliveryRecordResponse
+
+	query := fmt.Sprintf(`SELECT d.task_url, d.is_delivered, d.is_permanent, d.reason, d.created_at FROM %s d
+		INNER JOIN %s u ON u.id = d.user_id WHERE u.tg_id = $1 ORDER BY d.created_at, d.id;`,
+		deliveriesTable, usersTable)
+
+	if err := r.db.Select(&deliveries, query, tgId); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetActivity collects what was recorded while serving the user, for the
+// export of their data.
+func (r *UserPostgres) GetActivity(tgId int) (core.UserActivityResponse, error) {
+	var activity core.UserActivityResponse
+
+	sections := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&activity.NotInterested, fmt.Sprintf(`SELECT ni.task_url, ni.title, ni.category_id, ni.reason,
+			ni.created_at FROM %s ni INNER JOIN %s u ON u.id = ni.user_id WHERE u.tg_id = $1 ORDER BY ni.id;`,
+			notInterestedTable, usersTable)},
+		{&activity.HiddenTasks, fmt.Sprintf(`SELECT ht.task_url, ht.hidden_at FROM %s ht
+			INNER JOIN %s u ON u.id = ht.user_id WHERE u.tg_id = $1 ORDER BY ht.id;`,
+			hiddenTasksTable, usersTable)},
+		{&activity.HeldTasks, fmt.Sprintf(`SELECT ht.task_url, ht.title, ht.held_at AS added_at FROM %s ht
+			INNER JOIN %s u ON u.id = ht.user_id WHERE u.tg_id = $1 ORDER BY ht.id;`,
+			heldTasksTable, usersTable)},
+		{&activity.DigestQueue, fmt.Sprintf(`SELECT dt.task_url, dt.title, dt.queued_at AS added_at FROM %s dt
+			INNER JOIN %s u ON u.id = dt.user_id WHERE u.tg_id = $1 ORDER BY dt.id;`,
+			digestTasksTable, usersTable)},
+		{&activity.Digests, fmt.Sprintf(`SELECT d.title, d.tasks_count, d.created_at, d.served_at FROM %s d
+			INNER JOIN %s u ON u.id = d.user_id WHERE u.tg_id = $1 ORDER BY d.id;`,
+			digestsTable, usersTable)},
+		{&activity.Matches, fmt.Sprintf(`SELECT m.task_url, m.fl_name, m.category_id, m.published_at,
+			m.matched_at FROM %s m INNER JOIN %s u ON u.id = m.user_id WHERE u.tg_id = $1 ORDER BY m.id;`,
+			matchesTable, usersTable)},
+		{&activity.Links, fmt.Sprintf(`SELECT tl.task_url, tl.fl_name, tl.category_id, tl.created_at FROM %s tl
+			INNER JOIN %s u ON u.id = tl.user_id WHERE u.tg_id = $1 ORDER BY tl.id;`,
+			trackedLinksTable, usersTable)},
+		{&activity.Clicks, fmt.Sprintf(`SELECT tl.task_url, cl.clicked_at FROM %s cl
+			INNER JOIN %s tl ON tl.id = cl.link_id INNER JOIN %s u ON u.id = tl.user_id
+			WHERE u.tg_id = $1 ORDER BY cl.id;`, clicksTable, trackedLinksTable, usersTable)},
+	}
+
+	for _, section := range sections {
+		if err := r.db.Select(section.dest, section.query, tgId); err != nil {
+			return core.UserActivityResponse{}, err
+		}
+	}
+
+	return activity, nil
+}
+
+// SetStatus changes the status of the user unless it is deleted or one of
+// the kept statuses.
+func (r *UserPostgres) SetStatus(tgId int, status string, kept ...string) error {
+	return setRecipientStatus(r.db, usersTable, "tg_id", tgId, status, kept...)
+}
+
+func (r *UserPostgres) ReportDelivery(tgId int, delivery core.DeliveryInput, maxFailures int) (string, error) {
+	return reportRecipientDelivery(r.db, userDigestRecipient, tgId, delivery, maxFailures)
+}
+
+// GetSchedules returns quiet hours of active users, including users who
+// still have held tasks after their quiet hours were switched off.
+func (r *UserPostgres) GetSchedules() ([]core.UserScheduleResponse, error) {
+	var schedules []core.UserScheduleResponse
+
+	query := fmt.Sprintf(`SELECT u.tg_id, us.timezone, us.language, us.quiet_from, us.quiet_to, us.is_quiet_digest FROM %s u
+		INNER JOIN %s us ON u.id = us.user_id
+		WHERE u.status = '%s' AND (us.quiet_from != '' OR EXISTS (SELECT 1 FROM %s WHERE user_id = u.id));`,
+		usersTable, userSettingsTable, core.StatusActive, heldTasksTable)
+
+	if err := r.db.Select(&schedules, query); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
 }
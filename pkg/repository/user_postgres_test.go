@@ -4,9 +4,11 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	core "github.com/max-sanch/BotFreelancer-core"
 
+	"github.com/jackc/pgx"
 	"github.com/stretchr/testify/assert"
 	sqlmock "github.com/zhashkevych/go-sqlxmock"
 )
@@ -39,14 +41,15 @@ func TestUserPostgres_GetByTgId(t *testing.T) {
 				tgId: 1111,
 			},
 			mockBehavior: func(args args) {
-				rows := sqlmock.NewRows([]string{"id", "tg_id", "username"}).
-					AddRow(1, 1111, "user-1")
+				rows := sqlmock.NewRows([]string{"id", "tg_id", "username", "status"}).
+					AddRow(1, 1111, "user-1", "active")
 
 				mock.ExpectQuery("SELECT (.+) FROM users WHERE (.+)").
 					WithArgs(args.tgId).WillReturnRows(rows)
 
-				rows = sqlmock.NewRows([]string{"id", "is_safe_deal", "is_budget", "is_term"}).
-					AddRow(1, true, true, true)
+				rows = sqlmock.NewRows([]string{"id", "is_safe_deal", "is_budget", "is_term", "delivery_mode",
+					"digest_time", "template_id", "timezone", "language", "quiet_from", "quiet_to", "is_quiet_digest"}).
+					AddRow(1, true, true, true, "hourly", "", nil, "Europe/Moscow", "en", "23:00", "07:00", true)
 
 				mock.ExpectQuery("SELECT (.+) FROM user_settings WHERE (.+)").
 					WithArgs(1).WillReturnRows(rows)
@@ -55,16 +58,31 @@ func TestUserPostgres_GetByTgId(t *testing.T) {
 
 				mock.ExpectQuery("SELECT (.+) FROM user_categories WHERE (.+)").
 					WithArgs(1).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"keyword"}).AddRow("wordpress")
+
+				mock.ExpectQuery("SELECT (.+) FROM user_excluded_keywords WHERE (.+)").
+					WithArgs(1).WillReturnRows(rows)
 			},
 			want: core.UserResponse{
 				Id:       1,
 				TgId:     1111,
 				Username: "user-1",
+				Status:   "active",
+				Timezone: "Europe/Moscow",
+				Language: "en",
+				QuietHours: &core.QuietHours{
+					From:     "23:00",
+					To:       "07:00",
+					IsDigest: true,
+				},
 				Setting: core.SettingResponse{
-					IsSafeDeal: true,
-					IsBudget:   true,
-					IsTerm:     true,
-					Categories: []int{1, 2},
+					IsSafeDeal:       true,
+					IsBudget:         true,
+					IsTerm:           true,
+					Categories:       []int{1, 2},
+					DeliveryMode:     "hourly",
+					ExcludedKeywords: []string{"wordpress"},
 				},
 			},
 		},
@@ -74,7 +92,7 @@ func TestUserPostgres_GetByTgId(t *testing.T) {
 				tgId: 1111,
 			},
 			mockBehavior: func(args args) {
-				rows := sqlmock.NewRows([]string{"id", "tg_id", "username"})
+				rows := sqlmock.NewRows([]string{"id", "tg_id", "username", "status"})
 
 				mock.ExpectQuery("SELECT (.+) FROM users WHERE (.+)").
 					WithArgs(args.tgId).WillReturnRows(rows)
@@ -121,7 +139,32 @@ func TestUserPostgres_Create(t *testing.T) {
 		args         args
 		id           int
 		wantErr      bool
+		wantErrIs    error
 	}{
+		{
+			name: "Already Exists",
+			args: args{
+				user: core.UserInput{
+					TgId:     1111,
+					Username: "user-1",
+					Setting: core.SettingInput{
+						IsSafeDeal: &isFalse,
+						IsBudget:   &isFalse,
+						IsTerm:     &isFalse,
+					},
+				},
+			},
+			mockBehavior: func(args args, id int) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("INSERT INTO users").WithArgs(
+					args.user.TgId, args.user.Username).WillReturnError(pgx.PgError{Code: "23505"})
+
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: core.ErrAlreadyExists,
+		},
 		{
 			name: "OK",
 			args: args{
@@ -148,7 +191,8 @@ func TestUserPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(userSettingId)
 				mock.ExpectQuery("INSERT INTO user_settings").WithArgs(
 					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm).WillReturnRows(rows)
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				for _, categoryId := range args.user.Setting.Categories {
 					mock.ExpectExec("INSERT INTO user_categories").WithArgs(
@@ -208,7 +252,8 @@ func TestUserPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
 				mock.ExpectQuery("INSERT INTO user_settings").WithArgs(
 					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm).WillReturnRows(rows)
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -240,7 +285,8 @@ func TestUserPostgres_Create(t *testing.T) {
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(userSettingId)
 				mock.ExpectQuery("INSERT INTO user_settings").WithArgs(
 					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm).WillReturnRows(rows)
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectExec("INSERT INTO user_categories").
 					WithArgs(userSettingId, args.user.Setting.Categories[0]).
@@ -257,7 +303,9 @@ func TestUserPostgres_Create(t *testing.T) {
 			testCase.mockBehavior(testCase.args, testCase.id)
 
 			got, err := r.Create(testCase.args.user)
-			if testCase.wantErr {
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else if testCase.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
@@ -289,7 +337,68 @@ func TestUserPostgres_Update(t *testing.T) {
 		args         args
 		id           int
 		wantErr      bool
+		wantErrIs    error
 	}{
+		{
+			name: "Stale Version",
+			args: args{
+				user: core.UserInput{
+					TgId:     1111,
+					Username: "user-1",
+					Setting: core.SettingInput{
+						IsSafeDeal: &isFalse,
+						IsBudget:   &isFalse,
+						IsTerm:     &isFalse,
+						Categories: []int{1, 2},
+					},
+					Version: 3,
+				},
+			},
+			mockBehavior: func(args args, id int) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM users WHERE tg_id = (.+)").
+					WithArgs(args.user.TgId).WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name: "Missing User",
+			args: args{
+				user: core.UserInput{
+					TgId:     1111,
+					Username: "user-1",
+					Setting: core.SettingInput{
+						IsSafeDeal: &isFalse,
+						IsBudget:   &isFalse,
+						IsTerm:     &isFalse,
+						Categories: []int{1, 2},
+					},
+					Version: 3,
+				},
+			},
+			mockBehavior: func(args args, id int) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM users WHERE tg_id = (.+)").
+					WithArgs(args.user.TgId).WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: core.ErrNotFound,
+		},
 		{
 			name: "OK",
 			args: args{
@@ -310,13 +419,14 @@ func TestUserPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
-					args.user.Username, args.user.TgId).WillReturnRows(rows)
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(rows)
 
 				userSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(userSettingId)
-				mock.ExpectQuery("UPDATE user_settings SET (.+) WHERE (.+)").WithArgs(
-					args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM user_categories WHERE (.+)").WithArgs(
 					userSettingId).WillReturnResult(sqlmock.NewResult(0, 1))
@@ -349,7 +459,7 @@ func TestUserPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
 				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
-					args.user.Username, args.user.TgId).WillReturnRows(rows)
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -375,12 +485,13 @@ func TestUserPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
-					args.user.Username, args.user.TgId).WillReturnRows(rows)
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(rows)
 
 				rows = sqlmock.NewRows([]string{"id"}).RowError(1, errors.New("some error"))
-				mock.ExpectQuery("UPDATE user_settings SET (.+) WHERE (.+)").WithArgs(
-					args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectRollback()
 			},
@@ -406,13 +517,14 @@ func TestUserPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
-					args.user.Username, args.user.TgId).WillReturnRows(rows)
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(rows)
 
 				userSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(userSettingId)
-				mock.ExpectQuery("UPDATE user_settings SET (.+) WHERE (.+)").WithArgs(
-					args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM user_categories WHERE (.+)").WithArgs(
 					userSettingId).WillReturnError(sql.ErrNoRows)
@@ -441,13 +553,14 @@ func TestUserPostgres_Update(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("UPDATE users SET (.+) WHERE (.+)").WithArgs(
-					args.user.Username, args.user.TgId).WillReturnRows(rows)
+					args.user.Username, args.user.TgId, args.user.Version).WillReturnRows(rows)
 
 				userSettingId := 3
 				rows = sqlmock.NewRows([]string{"id"}).AddRow(userSettingId)
-				mock.ExpectQuery("UPDATE user_settings SET (.+) WHERE (.+)").WithArgs(
-					args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
-					args.user.Setting.IsTerm, id).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT (.+)").WithArgs(
+					id, args.user.Setting.IsSafeDeal, args.user.Setting.IsBudget,
+					args.user.Setting.IsTerm, args.user.Setting.DeliveryMode, args.user.Setting.DigestTime,
+					args.user.Setting.TemplateId, args.user.Timezone, args.user.Language, "", "", false).WillReturnRows(rows)
 
 				mock.ExpectExec("DELETE FROM user_categories WHERE (.+)").WithArgs(
 					userSettingId).WillReturnResult(sqlmock.NewResult(0, 1))
@@ -467,7 +580,9 @@ func TestUserPostgres_Update(t *testing.T) {
 			testCase.mockBehavior(testCase.args, testCase.id)
 
 			got, err := r.Update(testCase.args.user)
-			if testCase.wantErr {
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else if testCase.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
@@ -476,3 +591,586 @@ func TestUserPostgres_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestUserPostgres_Upsert(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+	isFalse := false
+
+	user := core.UserInput{
+		TgId:     1111,
+		Username: "user-1",
+		Setting: core.SettingInput{
+			IsSafeDeal: &isFalse,
+			IsBudget:   &isFalse,
+			IsTerm:     &isFalse,
+			Categories: []int{1, 2},
+		},
+	}
+
+	type mockBehavior func(user core.UserInput)
+
+	testTable := []struct {
+		name         string
+		user         core.UserInput
+		mockBehavior mockBehavior
+		created      bool
+		wantErrIs    error
+	}{
+		{
+			name: "Created",
+			user: user,
+			mockBehavior: func(user core.UserInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, true)
+				mock.ExpectQuery("INSERT INTO users (.+) ON CONFLICT \\(tg_id\\) DO UPDATE").
+					WithArgs(user.TgId, user.Username, user.Version).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT \\(user_id\\) DO UPDATE").WithArgs(
+					2, user.Setting.IsSafeDeal, user.Setting.IsBudget, user.Setting.IsTerm, user.Setting.DeliveryMode,
+					user.Setting.DigestTime, user.Setting.TemplateId, user.Timezone, user.Language, "", "", false).
+					WillReturnRows(rows)
+
+				for _, categoryId := range user.Setting.Categories {
+					mock.ExpectExec("INSERT INTO user_categories").WithArgs(
+						3, categoryId).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				mock.ExpectCommit()
+			},
+			created: true,
+		},
+		{
+			name: "Updated",
+			user: user,
+			mockBehavior: func(user core.UserInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, false)
+				mock.ExpectQuery("INSERT INTO users (.+) ON CONFLICT \\(tg_id\\) DO UPDATE (.+) "+
+					"status = CASE WHEN users.status = 'deleted' THEN 'active'").
+					WithArgs(user.TgId, user.Username, user.Version).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT \\(user_id\\) DO UPDATE").WithArgs(
+					2, user.Setting.IsSafeDeal, user.Setting.IsBudget, user.Setting.IsTerm, user.Setting.DeliveryMode,
+					user.Setting.DigestTime, user.Setting.TemplateId, user.Timezone, user.Language, "", "", false).
+					WillReturnRows(rows)
+
+				mock.ExpectExec("DELETE FROM user_categories WHERE (.+)").WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				for _, categoryId := range user.Setting.Categories {
+					mock.ExpectExec("INSERT INTO user_categories").WithArgs(
+						3, categoryId).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Stale Version",
+			user: func() core.UserInput { u := user; u.Version = 4; return u }(),
+			mockBehavior: func(user core.UserInput) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("INSERT INTO users (.+) ON CONFLICT \\(tg_id\\) DO UPDATE").
+					WithArgs(user.TgId, user.Username, user.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created"}))
+
+				mock.ExpectRollback()
+			},
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name: "Missing With Version",
+			user: func() core.UserInput { u := user; u.Version = 4; return u }(),
+			mockBehavior: func(user core.UserInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, true)
+				mock.ExpectQuery("INSERT INTO users (.+) ON CONFLICT \\(tg_id\\) DO UPDATE").
+					WithArgs(user.TgId, user.Username, user.Version).WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name: "Unknown Category",
+			user: user,
+			mockBehavior: func(user core.UserInput) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "created"}).AddRow(2, true)
+				mock.ExpectQuery("INSERT INTO users (.+) ON CONFLICT \\(tg_id\\) DO UPDATE").
+					WithArgs(user.TgId, user.Username, user.Version).WillReturnRows(rows)
+
+				rows = sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO user_settings (.+) ON CONFLICT \\(user_id\\) DO UPDATE").WithArgs(
+					2, user.Setting.IsSafeDeal, user.Setting.IsBudget, user.Setting.IsTerm, user.Setting.DeliveryMode,
+					user.Setting.DigestTime, user.Setting.TemplateId, user.Timezone, user.Language, "", "", false).
+					WillReturnRows(rows)
+
+				mock.ExpectExec("INSERT INTO user_categories").WithArgs(3, 1).
+					WillReturnError(pgx.PgError{Code: "23503"})
+
+				mock.ExpectRollback()
+			},
+			wantErrIs: core.ErrInvalidReference,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.user)
+
+			got, created, err := r.Upsert(testCase.user)
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, got)
+				assert.Equal(t, testCase.created, created)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_ReportDelivery(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+	isTrue, isFalse := true, false
+
+	type args struct {
+		tgId        int
+		delivery    core.DeliveryInput
+		maxFailures int
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		want         string
+		wantErr      bool
+	}{
+		{
+			name: "Delivered",
+			args: args{
+				tgId:        1111,
+				delivery:    core.DeliveryInput{Url: "test-url", IsDelivered: &isTrue, TaskId: 5},
+				maxFailures: 3,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "active")
+				mock.ExpectQuery("UPDATE users SET failures = 0 WHERE (.+)").
+					WithArgs(args.tgId).WillReturnRows(rows)
+
+				mock.ExpectExec("INSERT INTO deliveries").
+					WithArgs(1, "test-url", true, false, "", 5).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec("DELETE FROM held_tasks WHERE (.+)").
+					WithArgs(1, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectCommit()
+			},
+			want: "active",
+		},
+		{
+			name: "Digest Delivered",
+			args: args{
+				tgId:        1111,
+				delivery:    core.DeliveryInput{IsDelivered: &isTrue, DigestId: 7},
+				maxFailures: 3,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "active")
+				mock.ExpectQuery("UPDATE users SET failures = 0 WHERE (.+)").
+					WithArgs(args.tgId).WillReturnRows(rows)
+
+				mock.ExpectExec("INSERT INTO deliveries").
+					WithArgs(1, "", true, false, "", 0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec("UPDATE digests SET served_at = now\\(\\) WHERE (.+)").
+					WithArgs(7, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectCommit()
+			},
+			want: "active",
+		},
+		{
+			name: "Permanent Failure",
+			args: args{
+				tgId:        1111,
+				delivery:    core.DeliveryInput{Url: "test-url", IsDelivered: &isFalse, IsPermanent: true, Reason: "blocked"},
+				maxFailures: 3,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "blocked")
+				mock.ExpectQuery("UPDATE users SET failures = failures \\+ 1, (.+)").
+					WithArgs(args.tgId, args.maxFailures).WillReturnRows(rows)
+
+				mock.ExpectExec("INSERT INTO deliveries").
+					WithArgs(1, "test-url", false, true, "blocked", 0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			want: "blocked",
+		},
+		{
+			name: "Temporary Failure",
+			args: args{
+				tgId:        1111,
+				delivery:    core.DeliveryInput{Url: "test-url", IsDelivered: &isFalse},
+				maxFailures: 3,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "active")
+				mock.ExpectQuery("SELECT id, status FROM users WHERE (.+)").
+					WithArgs(args.tgId).WillReturnRows(rows)
+
+				mock.ExpectExec("INSERT INTO deliveries").
+					WithArgs(1, "test-url", false, false, "", 0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			want: "active",
+		},
+		{
+			name: "Not Found",
+			args: args{
+				tgId:        1111,
+				delivery:    core.DeliveryInput{Url: "test-url", IsDelivered: &isTrue},
+				maxFailures: 3,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id", "status"})
+				mock.ExpectQuery("UPDATE users SET failures = 0 WHERE (.+)").
+					WithArgs(args.tgId).WillReturnRows(rows)
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			got, err := r.ReportDelivery(testCase.args.tgId, testCase.args.delivery, testCase.args.maxFailures)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_SetStatus(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		wantErrIs    error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET status = (.+) WHERE tg_id = (.+) AND status NOT IN \\('deleted', 'inactive'\\)").
+					WithArgs(core.StatusActive, 1111).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Inactive",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET status = (.+)").
+					WithArgs(core.StatusActive, 1111).WillReturnResult(sqlmock.NewResult(0, 0))
+
+				rows := sqlmock.NewRows([]string{"status"}).AddRow(core.StatusInactive)
+				mock.ExpectQuery("SELECT status FROM users WHERE tg_id = (.+)").WithArgs(1111).WillReturnRows(rows)
+			},
+			wantErrIs: core.ErrConflict,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET status = (.+)").
+					WithArgs(core.StatusActive, 1111).WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectQuery("SELECT status FROM users WHERE tg_id = (.+)").WithArgs(1111).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}))
+			},
+			wantErrIs: core.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			err := r.SetStatus(1111, core.StatusActive, core.StatusInactive)
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_SoftDelete(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func(tgId int)
+		tgId         int
+		wantErrIs    error
+	}{
+		{
+			name: "OK",
+			tgId: 1111,
+			mockBehavior: func(tgId int) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(2)
+				mock.ExpectQuery("UPDATE users SET status = 'deleted', version = version \\+ 1 WHERE tg_id = (.+) AND status != 'deleted'").
+					WithArgs(tgId).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Not Found",
+			tgId: 1111,
+			mockBehavior: func(tgId int) {
+				mock.ExpectQuery("UPDATE users SET status = 'deleted'").
+					WithArgs(tgId).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErrIs: core.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.tgId)
+
+			err := r.SoftDelete(testCase.tgId)
+			if testCase.wantErrIs != nil {
+				assert.ErrorIs(t, err, testCase.wantErrIs)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_Erase(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+
+	type args struct {
+		tgId        int
+		subjectHash string
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				tgId:        1111,
+				subjectHash: "hash",
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("SELECT id FROM users WHERE tg_id = (.+) FOR UPDATE").
+					WithArgs(args.tgId).WillReturnRows(rows)
+				mock.ExpectExec("DELETE FROM clicks cl USING tracked_links tl (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM deliveries WHERE user_id = (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 5))
+				mock.ExpectExec("DELETE FROM users WHERE id = (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO erasures").
+					WithArgs(args.subjectHash, int64(5), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "User Not Found",
+			args: args{
+				tgId:        2222,
+				subjectHash: "hash",
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM users WHERE tg_id = (.+) FOR UPDATE").
+					WithArgs(args.tgId).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed Audit",
+			args: args{
+				tgId:        1111,
+				subjectHash: "hash",
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("SELECT id FROM users WHERE tg_id = (.+) FOR UPDATE").
+					WithArgs(args.tgId).WillReturnRows(rows)
+				mock.ExpectExec("DELETE FROM clicks cl USING tracked_links tl (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM deliveries WHERE user_id = (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM users WHERE id = (.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO erasures").
+					WithArgs(args.subjectHash, int64(0), int64(0)).WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.Erase(testCase.args.tgId, testCase.args.subjectHash)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_GetDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+	createdAt := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"task_url", "is_delivered", "is_permanent", "reason", "created_at"}).
+		AddRow("https://fl.ru/7", true, false, "", createdAt).
+		AddRow("https://fl.ru/8", false, true, "bot was blocked by the user", createdAt)
+	mock.ExpectQuery("SELECT (.+) FROM deliveries d INNER JOIN users u (.+) WHERE u.tg_id = (.+)").
+		WithArgs(1111).WillReturnRows(rows)
+
+	got, err := r.GetDeliveries(1111)
+	assert.NoError(t, err)
+	assert.Equal(t, []core.DeliveryRecordResponse{
+		{Url: "https://fl.ru/7", IsDelivered: true, CreatedAt: createdAt},
+		{Url: "https://fl.ru/8", IsPermanent: true, Reason: "bot was blocked by the user", CreatedAt: createdAt},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPostgres_GetActivity(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewUserPostgres(db)
+	createdAt := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM not_interested_tasks ni INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "title", "category_id", "reason", "created_at"}).
+			AddRow("https://fl.ru/7", "Golang API", 1, "too cheap", createdAt))
+	mock.ExpectQuery("SELECT (.+) FROM hidden_tasks ht INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "hidden_at"}))
+	mock.ExpectQuery("SELECT (.+) FROM held_tasks ht INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "title", "added_at"}))
+	mock.ExpectQuery("SELECT (.+) FROM digest_tasks dt INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "title", "added_at"}))
+	mock.ExpectQuery("SELECT (.+) FROM digests d INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "tasks_count", "created_at", "served_at"}).
+			AddRow("Digest", 2, createdAt, nil))
+	mock.ExpectQuery("SELECT (.+) FROM matches m INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "fl_name", "category_id", "published_at", "matched_at"}))
+	mock.ExpectQuery("SELECT (.+) FROM tracked_links tl INNER JOIN users u (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "fl_name", "category_id", "created_at"}))
+	mock.ExpectQuery("SELECT (.+) FROM clicks cl INNER JOIN tracked_links tl (.+)").WithArgs(1111).
+		WillReturnRows(sqlmock.NewRows([]string{"task_url", "clicked_at"}).
+			AddRow("https://fl.ru/7", createdAt))
+
+	got, err := r.GetActivity(1111)
+	assert.NoError(t, err)
+	assert.Equal(t, core.UserActivityResponse{
+		NotInterested: []core.NotInterestedRecordResponse{
+			{Url: "https://fl.ru/7", Title: "Golang API", CategoryId: 1, Reason: "too cheap", CreatedAt: createdAt},
+		},
+		Digests: []core.DigestRecordResponse{{Title: "Digest", TasksCount: 2, CreatedAt: createdAt}},
+		Clicks:  []core.ClickRecordResponse{{Url: "https://fl.ru/7", ClickedAt: createdAt}},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduler runs periodic background jobs of the service layer.
+type Scheduler struct {
+	services *service.Service
+	interval time.Duration
+}
+
+func NewScheduler(services *service.Service, interval time.Duration) *Scheduler {
+	return &Scheduler{services: services, interval: interval}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.services.User.ApplyQuietHours(now); err != nil {
+				logrus.Errorf("error occured while applying quiet hours: %s", err.Error())
+			}
+
+			if err := s.services.Digest.Build(now); err != nil {
+				logrus.Errorf("error occured while building digests: %s", err.Error())
+			}
+
+			archived, err := s.services.Retention.Archive(now)
+			if err != nil {
+				logrus.Errorf("error occured while archiving tasks: %s", err.Error())
+			} else if archived > 0 {
+				logrus.Infof("archived %d tasks", archived)
+			}
+
+			purged, err := s.services.Channel.PurgeDeleted(now)
+			if err != nil {
+				logrus.Errorf("error occured while purging deleted channels: %s", err.Error())
+			} else if purged > 0 {
+				logrus.Infof("purged %d deleted channels", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// prefix marks values sealed by a Keyring. Values without it are treated
+// as plaintext left from before encryption was enabled.
+const prefix = "enc1"
+
+const dataKeySize = 32
+
+var (
+	ErrNoKeys     = errors.New("encryption keys are not configured")
+	ErrUnknownKey = errors.New("value is sealed with an unknown key")
+	ErrMalformed  = errors.New("malformed sealed value")
+)
+
+// Keyring seals secrets with envelope encryption: every value is encrypted
+// with its own random data key, and the data key is encrypted with the
+// current master key. Older master keys are kept to open values sealed
+// before a rotation.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// ParseKeyring reads master keys from a "id:base64key,id:base64key" list.
+// The first key seals new values, all of them open existing ones. Keys
+// must be 16, 24 or 32 bytes long. An empty list gives a nil Keyring.
+func ParseKeyring(spec string) (*Keyring, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || strings.Contains(parts[0], ".") {
+			return nil, fmt.Errorf("invalid key entry %q, expected id:base64key", entry)
+		}
+
+		if _, ok := k.keys[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", parts[0])
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", parts[0], err)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", parts[0], err)
+		}
+
+		if k.current == "" {
+			k.current = parts[0]
+		}
+		k.keys[parts[0]] = aead
+	}
+
+	return k, nil
+}
+
+// Seal encrypts plaintext under the current master key. The result has the
+// form "enc1.<key id>.<sealed data key>.<sealed value>".
+func (k *Keyring) Seal(plaintext string) (string, error) {
+	if k == nil {
+		return "", ErrNoKeys
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealedKey, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return "", err
+	}
+
+	sealedValue, err := seal(dataAEAD, []byte(plaintext), sealedKey)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		prefix,
+		k.current,
+		base64.RawURLEncoding.EncodeToString(sealedKey),
+		base64.RawURLEncoding.EncodeToString(sealedValue),
+	}, "."), nil
+}
+
+// Open decrypts a value produced by Seal. Plaintext values are returned
+// as they are.
+func (k *Keyring) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+
+	if k == nil {
+		return "", ErrNoKeys
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 4 {
+		return "", ErrMalformed
+	}
+
+	master, ok := k.keys[parts[1]]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+
+	sealedKey, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	sealedValue, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	dataKey, err := open(master, sealedKey, []byte(parts[1]))
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	plaintext, err := open(dataAEAD, sealedValue, sealedKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value is plaintext or sealed with a key
+// other than the current one.
+func (k *Keyring) NeedsRotation(value string) bool {
+	if k == nil {
+		return false
+	}
+
+	return !strings.HasPrefix(value, prefix+"."+k.current+".")
+}
+
+// IsSealed reports whether value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix+".")
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal returns the nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,124 @@
+package secret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oldKey = "old:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	newKey = "new:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func TestKeyring_SealOpen(t *testing.T) {
+	old, err := ParseKeyring(oldKey)
+	assert.NoError(t, err)
+
+	rotated, err := ParseKeyring(newKey + "," + oldKey)
+	assert.NoError(t, err)
+
+	sealed, err := old.Seal("0123456789abcdef")
+	assert.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "0123456789abcdef")
+
+	testTable := []struct {
+		name      string
+		keyring   *Keyring
+		value     string
+		want      string
+		wantErr   error
+		needsWrap bool
+	}{
+		{
+			name:    "OK",
+			keyring: old,
+			value:   sealed,
+			want:    "0123456789abcdef",
+		},
+		{
+			name:      "Rotated",
+			keyring:   rotated,
+			value:     sealed,
+			want:      "0123456789abcdef",
+			needsWrap: true,
+		},
+		{
+			name:      "Plaintext",
+			keyring:   rotated,
+			value:     "0123456789abcdef",
+			want:      "0123456789abcdef",
+			needsWrap: true,
+		},
+		{
+			name:      "Unknown key",
+			keyring:   mustParse(t, newKey),
+			value:     sealed,
+			wantErr:   ErrUnknownKey,
+			needsWrap: true,
+		},
+		{
+			name:    "Tampered",
+			keyring: old,
+			value:   sealed[:len(sealed)-2] + "AA",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "No keys",
+			value:   sealed,
+			wantErr: ErrNoKeys,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.keyring.Open(testCase.value)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+
+			assert.Equal(t, testCase.needsWrap, testCase.keyring.NeedsRotation(testCase.value))
+		})
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	testTable := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "OK", spec: newKey + ", " + oldKey},
+		{name: "Missing id", spec: "MDEyMzQ1Njc4OWFiY2RlZg==", wantErr: "expected id:base64key"},
+		{name: "Bad base64", spec: "k:***", wantErr: "not valid base64"},
+		{name: "Bad size", spec: "k:MDEy", wantErr: "invalid key size"},
+		{name: "Duplicate", spec: oldKey + "," + oldKey, wantErr: "duplicate key id"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseKeyring(testCase.spec)
+			if testCase.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), testCase.wantErr), err.Error())
+		})
+	}
+}
+
+func mustParse(t *testing.T, spec string) *Keyring {
+	k, err := ParseKeyring(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
@@ -0,0 +1,75 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter spaces out messages so that neither the global Bot API limit
+// nor the per-chat limit is exceeded.
+type Limiter struct {
+	mu             sync.Mutex
+	globalInterval time.Duration
+	chatInterval   time.Duration
+	nextGlobal     time.Time
+	nextChat       map[string]time.Time
+}
+
+func NewLimiter(globalRate int, chatInterval time.Duration) *Limiter {
+	var globalInterval time.Duration
+	if globalRate > 0 {
+		globalInterval = time.Second / time.Duration(globalRate)
+	}
+
+	return &Limiter{
+		globalInterval: globalInterval,
+		chatInterval:   chatInterval,
+		nextChat:       make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until a message to chatId may be sent.
+func (l *Limiter) Wait(chatId string) {
+	l.mu.Lock()
+	now := time.Now()
+	at := now
+
+	if l.nextGlobal.After(at) {
+		at = l.nextGlobal
+	}
+
+	if next, ok := l.nextChat[chatId]; ok && next.After(at) {
+		at = next
+	}
+
+	l.nextGlobal = at.Add(l.globalInterval)
+	l.nextChat[chatId] = at.Add(l.chatInterval)
+	l.prune(now)
+	l.mu.Unlock()
+
+	time.Sleep(at.Sub(now))
+}
+
+// Delay postpones all messages to chatId by d. It is used to honour
+// retry_after from the Bot API.
+func (l *Limiter) Delay(chatId string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	at := time.Now().Add(d)
+	if next := l.nextChat[chatId]; next.Before(at) {
+		l.nextChat[chatId] = at
+	}
+}
+
+func (l *Limiter) prune(now time.Time) {
+	if len(l.nextChat) < 1000 {
+		return
+	}
+
+	for chatId, next := range l.nextChat {
+		if next.Before(now) {
+			delete(l.nextChat, chatId)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sender periodically collects matched tasks and posts them through the
+// Telegram Bot API: users receive them by tg_id and channels by @name.
+type Sender struct {
+	services *service.Service
+	bot      *BotClient
+	interval time.Duration
+}
+
+func NewSender(services *service.Service, bot *BotClient, interval time.Duration) *Sender {
+	return &Sender{services: services, bot: bot, interval: interval}
+}
+
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Send(); err != nil {
+			logrus.Errorf("error occured while sending tasks: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send runs one delivery round. Channel tasks are requested first since
+// that is what pulls fresh tasks from the parser. A recipient that can not
+// be served is logged and skipped so that the others still get their tasks.
+func (s *Sender) Send() error {
+	channelErr := s.sendChannels()
+	if err := s.sendUsers(); err != nil {
+		return err
+	}
+
+	return channelErr
+}
+
+func (s *Sender) sendChannels() error {
+	channelTasks, err := s.services.Channel.GetTasks(core.FeedInput{Format: render.FormatPlain})
+	if err != nil {
+		return err
+	}
+
+	channelChats := make(map[int]string)
+	for _, task := range channelTasks {
+		chatId, ok := s.channelChat(channelChats, task.ApiId)
+		if !ok {
+			continue
+		}
+
+		delivery := s.deliver(chatId, task.Url, task.Messages)
+		delivery.TaskId = task.TaskId
+		if _, err := s.services.Channel.ReportDelivery(core.ChannelDeliveryInput{
+			ApiId:         task.ApiId,
+			DeliveryInput: delivery,
+		}); err != nil {
+			logrus.Errorf("error occured while reporting delivery to channel %d: %s", task.ApiId, err.Error())
+		}
+	}
+
+	channelDigests, err := s.services.Channel.GetDigests(render.FormatPlain)
+	if err != nil {
+		return err
+	}
+
+	for _, digest := range channelDigests {
+		chatId, ok := s.channelChat(channelChats, digest.ApiId)
+		if !ok {
+			continue
+		}
+
+		delivery := s.deliver(chatId, "", digest.Messages)
+		delivery.DigestId = digest.DigestId
+		if _, err := s.services.Channel.ReportDelivery(core.ChannelDeliveryInput{
+			ApiId:         digest.ApiId,
+			DeliveryInput: delivery,
+		}); err != nil {
+			logrus.Errorf("error occured while reporting delivery to channel %d: %s", digest.ApiId, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *Sender) sendUsers() error {
+	userTasks, err := s.services.User.GetTasks(core.FeedInput{Format: render.FormatPlain})
+	if err != nil {
+		return err
+	}
+
+	for _, task := range userTasks {
+		delivery := s.deliver(strconv.Itoa(task.TgId), task.Url, task.Messages)
+		delivery.TaskId = task.TaskId
+		if _, err := s.services.User.ReportDelivery(core.UserDeliveryInput{
+			TgId:          task.TgId,
+			DeliveryInput: delivery,
+		}); err != nil {
+			logrus.Errorf("error occured while reporting delivery to user %d: %s", task.TgId, err.Error())
+		}
+	}
+
+	userDigests, err := s.services.User.GetDigests(render.FormatPlain)
+	if err != nil {
+		return err
+	}
+
+	for _, digest := range userDigests {
+		delivery := s.deliver(strconv.Itoa(digest.TgId), "", digest.Messages)
+		delivery.DigestId = digest.DigestId
+		if _, err := s.services.User.ReportDelivery(core.UserDeliveryInput{
+			TgId:          digest.TgId,
+			DeliveryInput: delivery,
+		}); err != nil {
+			logrus.Errorf("error occured while reporting delivery to user %d: %s", digest.TgId, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// channelChat looks up the chat of the channel once per round. The
+// lookup error is logged and the channel is skipped for the rest of the
+// round.
+func (s *Sender) channelChat(channelChats map[int]string, apiId int) (string, bool) {
+	if chatId, ok := channelChats[apiId]; ok {
+		return chatId, chatId != ""
+	}
+
+	channel, err := s.services.Channel.GetByApiId(apiId)
+	if err != nil {
+		logrus.Errorf("error occured while getting channel %d: %s", apiId, err.Error())
+		channelChats[apiId] = ""
+		return "", false
+	}
+
+	chatId := getChannelChatId(channel.Name)
+	channelChats[apiId] = chatId
+	return chatId, true
+}
+
+// deliver sends the messages of a single task and describes the outcome in
+// the form expected by the delivery reports, so that recipients who blocked
+// the bot are eventually excluded from matching.
+func (s *Sender) deliver(chatId, url string, messages []string) core.DeliveryInput {
+	isDelivered := false
+	delivery := core.DeliveryInput{
+		Url:         url,
+		IsDelivered: &isDelivered,
+	}
+
+	var err error
+	for _, text := range messages {
+		if err = s.bot.SendMessage(chatId, text); err != nil {
+			break
+		}
+	}
+
+	switch {
+	case err == nil:
+		isDelivered = true
+	case errors.Is(err, ErrBlocked):
+		logrus.Warnf("recipient %s is unreachable: %s", chatId, err.Error())
+		delivery.IsPermanent = true
+		delivery.Reason = err.Error()
+	default:
+		logrus.Errorf("error occured while sending task to %s: %s", chatId, err.Error())
+		delivery.Reason = err.Error()
+	}
+
+	return delivery
+}
+
+// getChannelChatId addresses a public channel by its username.
+func getChannelChatId(name string) string {
+	if strings.HasPrefix(name, "@") {
+		return name
+	}
+
+	return "@" + name
+}
@@ -0,0 +1,109 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultBaseURL = "https://api.telegram.org"
+
+var ErrBlocked = errors.New("bot was blocked by the recipient")
+
+type Config struct {
+	BaseURL      string
+	Token        string
+	GlobalRate   int
+	ChatInterval time.Duration
+	MaxRetries   int
+}
+
+type BotClient struct {
+	baseURL    string
+	token      string
+	maxRetries int
+	limiter    *Limiter
+	httpClient *http.Client
+}
+
+func NewBotClient(cfg Config) *BotClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &BotClient{
+		baseURL:    baseURL,
+		token:      cfg.Token,
+		maxRetries: cfg.MaxRetries,
+		limiter:    NewLimiter(cfg.GlobalRate, cfg.ChatInterval),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type apiResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// SendMessage delivers text to chatId, waiting out rate limits and
+// retrying when the Bot API answers with 429. ErrBlocked is returned
+// when the recipient has blocked the bot or removed it from the chat.
+func (c *BotClient) SendMessage(chatId string, text string) error {
+	jsonRequest, err := json.Marshal(map[string]string{
+		"chat_id": chatId,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		c.limiter.Wait(chatId)
+
+		resp, err := c.call("sendMessage", jsonRequest)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.Ok:
+			return nil
+		case resp.ErrorCode == http.StatusForbidden:
+			return fmt.Errorf("%w: %s", ErrBlocked, resp.Description)
+		case resp.ErrorCode == http.StatusTooManyRequests && attempt < c.maxRetries:
+			c.limiter.Delay(chatId, time.Duration(resp.Parameters.RetryAfter)*time.Second)
+		default:
+			return fmt.Errorf("telegram api error %d: %s", resp.ErrorCode, resp.Description)
+		}
+	}
+}
+
+func (c *BotClient) call(method string, body []byte) (apiResponse, error) {
+	var result apiResponse
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	resp, err := c.httpClient.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		// Drop the request URL from the error so that the token is not logged.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = fmt.Errorf("telegram %s: %w", method, urlErr.Err)
+		}
+		return result, err
+	}
+
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotClient_SendMessage(t *testing.T) {
+	testTable := []struct {
+		name          string
+		responses     []string
+		expectedCalls int
+		wantErr       bool
+		wantBlocked   bool
+	}{
+		{
+			name:          "OK",
+			responses:     []string{`{"ok":true,"result":{}}`},
+			expectedCalls: 1,
+		},
+		{
+			name: "Retry After",
+			responses: []string{
+				`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`,
+				`{"ok":true,"result":{}}`,
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "Retries Exceeded",
+			responses: []string{
+				`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":0}}`,
+				`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":0}}`,
+				`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":0}}`,
+			},
+			expectedCalls: 3,
+			wantErr:       true,
+		},
+		{
+			name:          "Blocked",
+			responses:     []string{`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`},
+			expectedCalls: 1,
+			wantErr:       true,
+			wantBlocked:   true,
+		},
+		{
+			name:          "Bad Request",
+			responses:     []string{`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`},
+			expectedCalls: 1,
+			wantErr:       true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Fake Bot API
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request map[string]string
+
+				assert.Equal(t, "/bottest-token/sendMessage", r.URL.Path)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				assert.Equal(t, "1111", request["chat_id"])
+				assert.Equal(t, "text", request["text"])
+
+				w.Write([]byte(testCase.responses[calls]))
+				calls++
+			}))
+			defer server.Close()
+
+			client := NewBotClient(Config{
+				BaseURL:      server.URL,
+				Token:        "test-token",
+				GlobalRate:   1000,
+				ChatInterval: time.Millisecond,
+				MaxRetries:   2,
+			})
+
+			err := client.SendMessage("1111", "text")
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.wantBlocked, errors.Is(err, ErrBlocked))
+			assert.Equal(t, testCase.expectedCalls, calls)
+		})
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	limiter := NewLimiter(1000, 50*time.Millisecond)
+
+	start := time.Now()
+	limiter.Wait("1111")
+	limiter.Wait("2222")
+	assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	limiter.Wait("1111")
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+}
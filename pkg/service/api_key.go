@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+	"github.com/max-sanch/BotFreelancer-core/pkg/secret"
+	"github.com/max-sanch/BotFreelancer-core/pkg/signing"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// apiKeyPrefix makes keys easy to recognize, e.g. by secret scanners.
+	apiKeyPrefix = "bfc_"
+	apiKeySize   = 32
+
+	bootstrapKeyName = "bootstrap"
+)
+
+// defaultClockSkew is used when auth.clock_skew is not set.
+const defaultClockSkew = 5 * time.Minute
+
+var (
+	ErrInvalidApiKey    = errors.New("invalid api key")
+	ErrApiKeyNotFound   = core.NewError(core.ErrNotFound, "api key not found")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrStaleRequest     = errors.New("request timestamp is outside the allowed window")
+	ErrReplayedRequest  = errors.New("request nonce was already used")
+)
+
+type ApiKeyService struct {
+	repo         *repository.Repository
+	bootstrapKey string
+	secretKeys   *secret.Keyring
+	nonces       *signing.NonceCache
+}
+
+func NewApiKeyService(repo *repository.Repository, bootstrapKey string, secretKeys *secret.Keyring) *ApiKeyService {
+	return &ApiKeyService{
+		repo:         repo,
+		bootstrapKey: bootstrapKey,
+		secretKeys:   secretKeys,
+		nonces:       signing.NewNonceCache(),
+	}
+}
+
+// Authenticate returns the client the key belongs to. The bootstrap key
+// from the environment is an admin key that is not stored in the database.
+func (s *ApiKeyService) Authenticate(key string) (core.ApiKey, error) {
+	if key == "" {
+		return core.ApiKey{}, ErrInvalidApiKey
+	}
+
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.bootstrapKey)) == 1 {
+		return core.ApiKey{Name: bootstrapKeyName, Scopes: []string{core.ScopeAdmin}}, nil
+	}
+
+	apiKey, err := s.repo.ApiKey.Authenticate(hashApiKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ApiKey{}, ErrInvalidApiKey
+	}
+
+	return apiKey, err
+}
+
+// AuthenticateSigned returns the client that signed the request with the
+// shared secret of its key. The timestamp must be within auth.clock_skew
+// of now and a nonce is accepted only once while the timestamp is valid.
+func (s *ApiKeyService) AuthenticateSigned(input core.SignedRequestInput, now time.Time) (core.ApiKey, error) {
+	unix, err := strconv.ParseInt(input.Timestamp, 10, 64)
+	if err != nil {
+		return core.ApiKey{}, ErrInvalidSignature
+	}
+
+	skew := clockSkew()
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-skew)) || signedAt.After(now.Add(skew)) {
+		return core.ApiKey{}, ErrStaleRequest
+	}
+
+	apiKey, sealedSecret, err := s.repo.ApiKey.GetSigningKey(input.KeyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ApiKey{}, ErrInvalidSignature
+	}
+	if err != nil {
+		return core.ApiKey{}, err
+	}
+
+	signingSecret, err := s.secretKeys.Open(sealedSecret)
+	if err != nil {
+		return core.ApiKey{}, err
+	}
+
+	if !signing.Verify(signingSecret, input.Signature, input.Method, input.Path, input.Timestamp, input.Nonce, input.Body) {
+		return core.ApiKey{}, ErrInvalidSignature
+	}
+
+	if !s.nonces.Add(strconv.Itoa(input.KeyId)+":"+input.Nonce, signedAt.Add(skew), now) {
+		return core.ApiKey{}, ErrReplayedRequest
+	}
+
+	return apiKey, s.repo.ApiKey.MarkUsed(apiKey.Id)
+}
+
+// Create issues a new key. Only its hash is stored, so the key can not be
+// shown again. The signing secret is sealed and shown only once as well.
+func (s *ApiKeyService) Create(input core.ApiKeyInput) (core.ApiKeyCreatedResponse, error) {
+	key, err := randomToken()
+	if err != nil {
+		return core.ApiKeyCreatedResponse{}, err
+	}
+	key = apiKeyPrefix + key
+
+	var signingSecret, sealedSecret string
+	if input.Signing {
+		if signingSecret, err = randomToken(); err != nil {
+			return core.ApiKeyCreatedResponse{}, err
+		}
+
+		if sealedSecret, err = s.secretKeys.Seal(signingSecret); err != nil {
+			return core.ApiKeyCreatedResponse{}, err
+		}
+	}
+
+	id, err := s.repo.ApiKey.Create(input.Name, hashApiKey(key), sealedSecret, input.Scopes)
+	if err != nil {
+		return core.ApiKeyCreatedResponse{}, err
+	}
+
+	return core.ApiKeyCreatedResponse{Id: id, Key: key, Secret: signingSecret}, nil
+}
+
+// RotateSecrets seals with the current key every signing secret sealed
+// with an older one, and returns how many were rewritten.
+func (s *ApiKeyService) RotateSecrets() (int, error) {
+	if s.secretKeys == nil {
+		return 0, secret.ErrNoKeys
+	}
+
+	secrets, err := s.repo.ApiKey.GetAllSigningSecrets()
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, k := range secrets {
+		if !s.secretKeys.NeedsRotation(k.SigningSecret) {
+			continue
+		}
+
+		signingSecret, err := s.secretKeys.Open(k.SigningSecret)
+		if err != nil {
+			return rotated, fmt.Errorf("api key %d: %w", k.Id, err)
+		}
+
+		sealed, err := s.secretKeys.Seal(signingSecret)
+		if err != nil {
+			return rotated, err
+		}
+
+		replaced, err := s.repo.ApiKey.ReplaceSigningSecret(k.Id, k.SigningSecret, sealed)
+		if err != nil {
+			return rotated, err
+		}
+		if replaced {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+func (s *ApiKeyService) GetAll() ([]core.ApiKeyResponse, error) {
+	return s.repo.ApiKey.GetAll()
+}
+
+func (s *ApiKeyService) Revoke(id int) error {
+	err := s.repo.ApiKey.Revoke(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrApiKeyNotFound
+	}
+
+	return err
+}
+
+func clockSkew() time.Duration {
+	skew := viper.GetDuration("auth.clock_skew")
+	if skew <= 0 {
+		return defaultClockSkew
+	}
+
+	return skew
+}
+
+func randomToken() (string, error) {
+	token := make([]byte, apiKeySize)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashApiKey returns the hex SHA-256 of a key. Keys are random, so a
+// plain hash is enough to make a leaked table useless.
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+)
+
+// Callback actions. Payloads use the short codes to stay within the 64
+// bytes Telegram allows for callback data.
+const (
+	ActionHideTask     = "hide_task"
+	ActionMuteCategory = "mute_category"
+	ActionSaveTask     = "save_task"
+)
+
+var callbackActions = map[string]string{
+	"h": ActionHideTask,
+	"m": ActionMuteCategory,
+	"s": ActionSaveTask,
+	"c": ActionSaveTask,
+}
+
+// unboundCallbacks are the codes of channel buttons, which anyone reading
+// the channel may press. Payloads of other codes are signed together with
+// the tg_id of the user they were sent to and only work for that user.
+var unboundCallbacks = map[string]bool{
+	"c": true,
+}
+
+// callbackSignatureSize is the number of HMAC bytes kept in a payload.
+const callbackSignatureSize = 12
+
+var (
+	ErrInvalidCallback = errors.New("invalid callback data")
+	ErrNoSigningKey    = errors.New("signing key is not configured")
+)
+
+// signCallback builds the "<code>.<id>.<signature>" payload of a button
+// sent to the user with tgId. The tg_id is signed but not included in the
+// payload, it comes with the callback.
+func signCallback(key, code string, id, tgId int) string {
+	payload := code + "." + strconv.Itoa(id)
+	return payload + "." + callbackSignature(key, callbackSignedData(payload, code, tgId))
+}
+
+// parseCallback checks the signature of a payload pressed by the user with
+// tgId and returns its action and object id.
+func parseCallback(key, data string, tgId int) (string, int, error) {
+	parts := strings.Split(data, ".")
+	if len(parts) != 3 {
+		return "", 0, ErrInvalidCallback
+	}
+
+	action, ok := callbackActions[parts[0]]
+	if !ok {
+		return "", 0, ErrInvalidCallback
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, ErrInvalidCallback
+	}
+
+	expected := callbackSignature(key, callbackSignedData(parts[0]+"."+parts[1], parts[0], tgId))
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return "", 0, ErrInvalidCallback
+	}
+
+	return action, id, nil
+}
+
+func callbackSignedData(payload, code string, tgId int) string {
+	if unboundCallbacks[code] {
+		return payload
+	}
+
+	return payload + "." + strconv.Itoa(tgId)
+}
+
+func callbackSignature(key, payload string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:callbackSignatureSize])
+}
+
+func userTaskButtons(key, language string, tgId int, task core.Task) [][]core.Button {
+	return [][]core.Button{
+		{
+			{Text: render.T(language, "button_open"), Url: task.Url},
+		},
+		{
+			{Text: render.T(language, "button_hide"), CallbackData: signCallback(key, "h", task.Id, tgId)},
+			{Text: render.T(language, "button_mute"), CallbackData: signCallback(key, "m", task.CategoryId, tgId)},
+		},
+		{
+			{Text: render.T(language, "button_save"), CallbackData: signCallback(key, "s", task.Id, tgId)},
+		},
+	}
+}
+
+// channelTaskButtons leave out hiding and muting since those change the
+// settings of whoever presses them, not of the channel.
+func channelTaskButtons(key, language string, task core.Task) [][]core.Button {
+	return [][]core.Button{
+		{
+			{Text: render.T(language, "button_open"), Url: task.Url},
+			{Text: render.T(language, "button_save"), CallbackData: signCallback(key, "c", task.Id, 0)},
+		},
+	}
+}
@@ -3,23 +3,49 @@ package service
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
 	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+	"github.com/max-sanch/BotFreelancer-core/pkg/secret"
+	"github.com/max-sanch/BotFreelancer-core/pkg/signing"
 
 	"github.com/spf13/viper"
 )
 
+// defaultRestoreWindow is used when channels.restore_window is not set.
+const defaultRestoreWindow = 30 * 24 * time.Hour
+
+var (
+	ErrNotRestorable   = core.NewError(core.ErrConflict, "channel is not deleted or its restore window has passed")
+	ErrChannelNotFound = core.NewError(core.ErrNotFound, "channel not found")
+	ErrChannelExists   = core.NewError(core.ErrAlreadyExists, "channel already exists")
+)
+
 type ChannelService struct {
-	repo *repository.Repository
+	repo            *repository.Repository
+	signingKey      string
+	credentialsKeys *secret.Keyring
+	parserKeyId     string
+	parserSecret    string
 }
 
-func NewChannelService(repo *repository.Repository) *ChannelService {
-	return &ChannelService{repo: repo}
+func NewChannelService(repo *repository.Repository, signingKey string, credentialsKeys *secret.Keyring,
+	parserKeyId, parserSecret string) *ChannelService {
+	return &ChannelService{
+		repo:            repo,
+		signingKey:      signingKey,
+		credentialsKeys: credentialsKeys,
+		parserKeyId:     parserKeyId,
+		parserSecret:    parserSecret,
+	}
 }
 
-func (s *ChannelService) GetTasks() ([]core.ChannelTaskResponse, error) {
+func (s *ChannelService) GetTasks(input core.FeedInput) ([]core.ChannelTaskResponse, error) {
 	var emptyTasks []core.ChannelTaskResponse
 
 	lastParseTime, err := s.repo.Task.GetLastParseTime()
@@ -27,7 +53,7 @@ func (s *ChannelService) GetTasks() ([]core.ChannelTaskResponse, error) {
 		return nil, err
 	}
 
-	parseTasks, err := getParseTasks(lastParseTime)
+	parseTasks, err := s.getParseTasks(lastParseTime)
 	if err != nil {
 		return nil, err
 	}
@@ -40,46 +66,381 @@ func (s *ChannelService) GetTasks() ([]core.ChannelTaskResponse, error) {
 		return emptyTasks, nil
 	}
 
-	if err := s.repo.Task.DeleteAll(); err != nil {
+	if err := s.repo.Task.AddTasks(parseTasks); err != nil {
+		return nil, err
+	}
+
+	if err := dispatchUserBatch(s.repo, s.signingKey, time.Now()); err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.Task.AddTasks(parseTasks); err != nil {
+	channelTasks, err := s.repo.Task.GetAllForChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	renderer, err := newBatchRenderer(s.repo, s.signingKey, input.Buttons)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := renderer.RenderChannelTasks(channelTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Stats.AddChannelMatches(channelMatches(channelTasks, tasks)); err != nil {
+		return nil, err
+	}
+
+	digestChannels, err := s.repo.Digest.GetDigestChannels()
+	if err != nil {
 		return nil, err
 	}
 
-	tasks, err := s.repo.Task.GetAllForChannels()
+	tasks, err = s.queueDigestTasks(tasks, digestChannels)
 	if err != nil {
 		return nil, err
 	}
 
+	for i, task := range tasks {
+		tasks[i].FormattedMessage = formatMessage(input.Format, task.Title, task.Body, task.Url)
+	}
+
 	return tasks, nil
 }
 
+// GetDigests returns the digests that were not delivered yet. A digest is
+// served once a delivery report with its digest_id arrives.
+func (s *ChannelService) GetDigests(format string) ([]core.ChannelDigestResponse, error) {
+	digests, err := s.repo.Digest.GetChannelDigests()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, digest := range digests {
+		digests[i].FormattedMessage = formatMessage(format, digest.Title, digest.Body, "")
+	}
+
+	return digests, nil
+}
+
+// queueDigestTasks moves tasks of channels that receive digests into the
+// digest queue and returns the tasks that are delivered right away.
+func (s *ChannelService) queueDigestTasks(tasks []core.ChannelTaskResponse, digestChannels []int) ([]core.ChannelTaskResponse, error) {
+	if len(digestChannels) == 0 {
+		return tasks, nil
+	}
+
+	var result []core.ChannelTaskResponse
+	queuedTasks := make(map[int][]core.ChannelTaskResponse)
+	for _, apiId := range digestChannels {
+		queuedTasks[apiId] = nil
+	}
+
+	for _, task := range tasks {
+		if _, ok := queuedTasks[task.ApiId]; ok {
+			queuedTasks[task.ApiId] = append(queuedTasks[task.ApiId], task)
+			continue
+		}
+		result = append(result, task)
+	}
+
+	for _, apiId := range digestChannels {
+		if len(queuedTasks[apiId]) == 0 {
+			continue
+		}
+
+		if err := s.repo.Digest.QueueChannelTasks(apiId, queuedTasks[apiId]); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
 func (s *ChannelService) GetByApiId(apiId int) (core.ChannelResponse, error) {
-	return s.repo.Channel.GetByApiId(apiId)
+	channel, err := s.repo.Channel.GetByApiId(apiId)
+	return channel, channelError(err)
 }
 
 func (s *ChannelService) Create(channelInput core.ChannelInput) (int, error) {
-	return s.repo.Channel.Create(channelInput)
+	if err := s.normalizeChannelInput(&channelInput); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Channel.Create(channelInput)
+	return id, channelError(err)
 }
 
 func (s *ChannelService) Update(channelInput core.ChannelInput) (int, error) {
-	return s.repo.Channel.Update(channelInput)
+	if err := s.normalizeChannelInput(&channelInput); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Channel.Update(channelInput)
+	return id, channelError(err)
+}
+
+// Upsert creates the channel or updates an existing one.
+func (s *ChannelService) Upsert(channelInput core.ChannelInput) (core.UpsertResponse, error) {
+	if err := s.normalizeChannelInput(&channelInput); err != nil {
+		return core.UpsertResponse{}, err
+	}
+
+	id, created, err := s.repo.Channel.Upsert(channelInput)
+	if err != nil {
+		return core.UpsertResponse{}, channelError(err)
+	}
+
+	return core.UpsertResponse{Id: id, Created: created}, nil
+}
+
+// Patch changes the fields present in input and keeps the others, the
+// api_hash is never changed. Versions work as in UserService.Patch.
+func (s *ChannelService) Patch(apiId int, input core.ChannelPatchInput) (core.ChannelResponse, error) {
+	var channel core.ChannelResponse
+
+	err := retryPatch(input.Version, func() error {
+		var err error
+		channel, err = s.patch(apiId, input)
+		return err
+	})
+
+	return channel, err
+}
+
+func (s *ChannelService) patch(apiId int, input core.ChannelPatchInput) (core.ChannelResponse, error) {
+	channel, err := s.GetByApiId(apiId)
+	if err != nil {
+		return core.ChannelResponse{}, err
+	}
+
+	channelInput := core.ChannelInput{
+		ApiId:    apiId,
+		Name:     channel.Name,
+		Timezone: channel.Timezone,
+		Language: channel.Language,
+		Setting:  settingInput(channel.Setting),
+		Version:  channel.Version,
+	}
+
+	if input.Version != 0 {
+		channelInput.Version = input.Version
+	}
+	if input.Name != nil {
+		channelInput.Name = *input.Name
+	}
+	if input.Timezone != nil {
+		channelInput.Timezone = *input.Timezone
+	}
+	if input.Language != nil {
+		channelInput.Language = *input.Language
+	}
+	if input.Setting != nil {
+		patchSetting(&channelInput.Setting, *input.Setting)
+	}
+
+	if _, err := s.Update(channelInput); err != nil {
+		return core.ChannelResponse{}, err
+	}
+
+	return s.GetByApiId(apiId)
+}
+
+func (s *ChannelService) GetAll(input core.ChannelListInput) (core.ChannelListResponse, error) {
+	page, perPage, offset := pageBounds(input.PageInput)
+
+	channels, total, err := s.repo.Channel.GetAll(input.Status, perPage, offset)
+	if err != nil {
+		return core.ChannelListResponse{}, err
+	}
+
+	return core.ChannelListResponse{Channels: channels, Total: total, Page: page, PerPage: perPage}, nil
 }
 
+// Delete marks the channel as deleted. It can be restored within
+// channels.restore_window, after that it is purged by the scheduler.
 func (s *ChannelService) Delete(apiId int) error {
-	return s.repo.Channel.Delete(apiId)
+	return channelError(s.repo.Channel.SoftDelete(apiId))
+}
+
+func (s *ChannelService) Restore(apiId int) error {
+	err := s.repo.Channel.Restore(apiId, time.Now().Add(-channelRestoreWindow()))
+	if errors.Is(err, core.ErrNotFound) {
+		return ErrNotRestorable
+	}
+
+	return err
+}
+
+// Purge removes the channel with its settings and history right away.
+func (s *ChannelService) Purge(apiId int) error {
+	return channelError(s.repo.Channel.Delete(apiId))
+}
+
+// PurgeDeleted removes channels whose restore window has passed by now.
+func (s *ChannelService) PurgeDeleted(now time.Time) (int64, error) {
+	return s.repo.Channel.PurgeDeleted(now.Add(-channelRestoreWindow()))
+}
+
+func (s *ChannelService) Pause(apiId int) error {
+	return channelError(s.repo.Channel.SetStatus(apiId, core.StatusPaused))
+}
+
+func (s *ChannelService) Resume(apiId int) error {
+	return channelError(s.repo.Channel.SetStatus(apiId, core.StatusActive))
+}
+
+// channelError translates repository errors into the errors of the service.
+func channelError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		return ErrChannelNotFound
+	case errors.Is(err, core.ErrAlreadyExists):
+		return ErrChannelExists
+	case errors.Is(err, core.ErrInvalidReference):
+		return invalidInputf("unknown category or template")
+	}
+
+	return err
+}
+
+// GetCredentials returns the decrypted api_hash of the channel.
+func (s *ChannelService) GetCredentials(apiId int) (core.ChannelCredentialsResponse, error) {
+	credentials, err := s.repo.Channel.GetCredentials(apiId)
+	if errors.Is(err, core.ErrNotFound) {
+		return core.ChannelCredentialsResponse{}, ErrChannelNotFound
+	}
+	if err != nil {
+		return core.ChannelCredentialsResponse{}, err
+	}
+
+	credentials.ApiHash, err = s.credentialsKeys.Open(credentials.ApiHash)
+	if err != nil {
+		return core.ChannelCredentialsResponse{}, err
+	}
+
+	return credentials, nil
+}
+
+// RotateCredentials seals with the current key every api_hash that is
+// stored in plaintext or sealed with an older key, and returns how many
+// were rewritten.
+func (s *ChannelService) RotateCredentials() (int, error) {
+	if s.credentialsKeys == nil {
+		return 0, secret.ErrNoKeys
+	}
+
+	credentials, err := s.repo.Channel.GetAllCredentials()
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, c := range credentials {
+		if !s.credentialsKeys.NeedsRotation(c.ApiHash) {
+			continue
+		}
+
+		apiHash, err := s.credentialsKeys.Open(c.ApiHash)
+		if err != nil {
+			return rotated, fmt.Errorf("channel %d: %w", c.ApiId, err)
+		}
+
+		sealed, err := s.credentialsKeys.Seal(apiHash)
+		if err != nil {
+			return rotated, err
+		}
+
+		replaced, err := s.repo.Channel.ReplaceCredentials(c.ApiId, c.ApiHash, sealed)
+		if err != nil {
+			return rotated, err
+		}
+		if replaced {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+func (s *ChannelService) SetStatus(input core.ChannelStatusInput) error {
+	return channelError(s.repo.Channel.SetStatus(input.ApiId, input.Status))
+}
+
+func (s *ChannelService) ReportDelivery(input core.ChannelDeliveryInput) (string, error) {
+	status, err := s.repo.Channel.ReportDelivery(input.ApiId, input.DeliveryInput, viper.GetInt("recipients.max_failures"))
+	return status, channelError(err)
+}
+
+func channelRestoreWindow() time.Duration {
+	window := viper.GetDuration("channels.restore_window")
+	if window <= 0 {
+		return defaultRestoreWindow
+	}
+
+	return window
+}
+
+// normalizeChannelInput fills defaults, validates the input and seals its
+// api_hash for storage.
+func (s *ChannelService) normalizeChannelInput(channelInput *core.ChannelInput) error {
+	if channelInput.Timezone == "" {
+		channelInput.Timezone = defaultTimezone
+	}
+
+	if channelInput.Language == "" {
+		channelInput.Language = render.DefaultLanguage
+	}
+
+	if err := normalizeDelivery(&channelInput.Setting); err != nil {
+		return err
+	}
+
+	if err := validateSchedule(channelInput.Timezone, nil); err != nil {
+		return err
+	}
+
+	// An empty api_hash keeps the stored one on updates.
+	if channelInput.ApiHash == "" {
+		return nil
+	}
+
+	apiHash, err := s.credentialsKeys.Seal(channelInput.ApiHash)
+	if err != nil {
+		return err
+	}
+	channelInput.ApiHash = apiHash
+
+	return nil
 }
 
-func getParseTasks(datetime string) (core.TasksInput, error) {
+// getParseTasks requests the tasks parsed since datetime. The request is
+// signed like the signed requests core accepts when a parser secret is set.
+func (s *ChannelService) getParseTasks(datetime string) (core.TasksInput, error) {
 	var tasks, emptyTasks core.TasksInput
 
 	jsonRequest, err := json.Marshal(map[string]string{
 		"datetime": datetime,
 	})
+	if err != nil {
+		return emptyTasks, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, viper.GetString("url_parse_tasks"), bytes.NewBuffer(jsonRequest))
+	if err != nil {
+		return emptyTasks, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.parserSecret != "" {
+		if err := signing.SignRequest(req, s.parserKeyId, s.parserSecret, time.Now()); err != nil {
+			return emptyTasks, err
+		}
+	}
 
-	resp, err := http.Post(viper.GetString("url_parse_tasks"), "application/json", bytes.NewBuffer(jsonRequest))
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return emptyTasks, err
 	}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"crypto/hmac"
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+)
+
+// defaultReportPeriod is used when a report is requested without from.
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+var ErrInvalidLink = core.NewError(core.ErrNotFound, "link not found")
+
+type ClickService struct {
+	repo       *repository.Repository
+	signingKey string
+}
+
+func NewClickService(repo *repository.Repository, signingKey string) *ClickService {
+	return &ClickService{repo: repo, signingKey: signingKey}
+}
+
+// Open logs a click on the tracked link and returns the task url to
+// redirect to.
+func (s *ClickService) Open(token string) (string, error) {
+	if s.signingKey == "" {
+		return "", ErrNoSigningKey
+	}
+
+	linkId, err := parseLinkToken(s.signingKey, token)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := s.repo.Click.LogClick(linkId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrInvalidLink
+	}
+
+	return url, err
+}
+
+func (s *ClickService) GetCategoryRates(input core.PeriodInput) ([]core.ClickRateResponse, error) {
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.repo.Click.GetCategoryRates(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return withClickRates(rates), nil
+}
+
+func (s *ClickService) GetSourceRates(input core.PeriodInput) ([]core.ClickRateResponse, error) {
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.repo.Click.GetSourceRates(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return withClickRates(rates), nil
+}
+
+// clickTracker replaces task urls with signed redirect links so that
+// opened tasks can be counted. Links are created once, when the batch is
+// dispatched; feeds only look them up and keep the task url of tasks that
+// have no link.
+type clickTracker struct {
+	repo    repository.Click
+	key     string
+	baseUrl string
+	create  bool
+}
+
+func (t *clickTracker) userUrl(tgId int, task core.Task) (string, error) {
+	var linkId int
+	var err error
+
+	if t.create {
+		linkId, err = t.repo.CreateUserLink(tgId, task)
+	} else {
+		linkId, err = t.repo.GetUserLink(tgId, task.Url)
+	}
+
+	return t.trackedUrl(task.Url, linkId, err)
+}
+
+func (t *clickTracker) channelUrl(apiId int, task core.Task) (string, error) {
+	var linkId int
+	var err error
+
+	if t.create {
+		linkId, err = t.repo.CreateChannelLink(apiId, task)
+	} else {
+		linkId, err = t.repo.GetChannelLink(apiId, task.Url)
+	}
+
+	return t.trackedUrl(task.Url, linkId, err)
+}
+
+func (t *clickTracker) trackedUrl(url string, linkId int, err error) (string, error) {
+	if !t.create && errors.Is(err, sql.ErrNoRows) {
+		return url, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return t.linkUrl(linkId), nil
+}
+
+func (t *clickTracker) linkUrl(linkId int) string {
+	return strings.TrimRight(t.baseUrl, "/") + "/r/" + signLinkToken(t.key, linkId)
+}
+
+// signLinkToken builds the "<id>.<signature>" token of a redirect link
+// with the id in base 36 to keep links short.
+func signLinkToken(key string, linkId int) string {
+	id := strconv.FormatInt(int64(linkId), 36)
+	return id + "." + callbackSignature(key, "r."+id)
+}
+
+func parseLinkToken(key, token string) (int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return 0, ErrInvalidLink
+	}
+
+	expected := callbackSignature(key, "r."+parts[0])
+	if !hmac.Equal([]byte(expected), []byte(parts[1])) {
+		return 0, ErrInvalidLink
+	}
+
+	linkId, err := strconv.ParseInt(parts[0], 36, 32)
+	if err != nil {
+		return 0, ErrInvalidLink
+	}
+
+	return int(linkId), nil
+}
+
+func withClickRates(rates []core.ClickRateResponse) []core.ClickRateResponse {
+	for i, rate := range rates {
+		if rate.Links > 0 {
+			rates[i].Ctr = math.Round(float64(rate.Clicked)/float64(rate.Links)*10000) / 10000
+		}
+	}
+
+	return rates
+}
+
+// periodBounds turns an inclusive range of UTC days into [from, to).
+// Without to the range ends today, without from it covers
+// defaultReportPeriod. A from after to is invalid input.
+func periodBounds(input core.PeriodInput, now time.Time) (time.Time, time.Time, error) {
+	if !input.From.IsZero() && !input.To.IsZero() && input.From.After(input.To) {
+		return time.Time{}, time.Time{}, invalidInputf("from must not be after to")
+	}
+
+	to := input.To
+	if to.IsZero() {
+		now = now.UTC()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	to = to.AddDate(0, 0, 1)
+
+	from := input.From
+	if from.IsZero() {
+		from = to.Add(-defaultReportPeriod)
+	}
+
+	return from, to, nil
+}
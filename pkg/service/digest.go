@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+
+	"github.com/spf13/viper"
+)
+
+const defaultDigestMaxSize = 4096
+
+type DigestService struct {
+	repo *repository.Repository
+}
+
+func NewDigestService(repo *repository.Repository) *DigestService {
+	return &DigestService{repo: repo}
+}
+
+// Build turns queued tasks of every subscriber whose digest period has
+// ended into a digest. Users in their quiet hours are skipped until the
+// quiet window is over.
+func (s *DigestService) Build(now time.Time) error {
+	userSchedules, err := s.repo.Digest.GetUserSchedules()
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range userSchedules {
+		if !schedule.QueuedAt.Before(digestPeriodStart(schedule, now)) ||
+			isQuietTime(schedule.Timezone, schedule.QuietHours, now) {
+			continue
+		}
+
+		tasks, err := s.repo.Digest.GetUserQueue(schedule.Key)
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 {
+			continue
+		}
+
+		if err := s.repo.Digest.CreateUserDigest(schedule.Key, renderDigest(tasks, schedule.Language),
+			tasks[len(tasks)-1].Id); err != nil {
+			return err
+		}
+	}
+
+	channelSchedules, err := s.repo.Digest.GetChannelSchedules()
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range channelSchedules {
+		if !schedule.QueuedAt.Before(digestPeriodStart(schedule, now)) {
+			continue
+		}
+
+		tasks, err := s.repo.Digest.GetChannelQueue(schedule.Key)
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 {
+			continue
+		}
+
+		if err := s.repo.Digest.CreateChannelDigest(schedule.Key, renderDigest(tasks, schedule.Language),
+			tasks[len(tasks)-1].Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderDigest lists queued tasks in a single message. Tasks that do not
+// fit into digest.max_size characters are only counted at the end.
+func renderDigest(tasks []core.QueuedTaskResponse, language string) core.DigestInput {
+	maxSize := viper.GetInt("digest.max_size")
+	if maxSize <= 0 {
+		maxSize = defaultDigestMaxSize
+	}
+
+	var body strings.Builder
+	size := 0
+
+	for i, task := range tasks {
+		entry := fmt.Sprintf("%d. %s\n%s\n\n", i+1, task.Title, task.Url)
+		rest := fmt.Sprintf(render.T(language, "digest_more"), len(tasks)-i)
+
+		entrySize := utf8.RuneCountInString(entry)
+		if i < len(tasks)-1 && size+entrySize+utf8.RuneCountInString(rest) > maxSize ||
+			i == len(tasks)-1 && size+entrySize > maxSize {
+			body.WriteString(rest)
+			break
+		}
+
+		body.WriteString(entry)
+		size += entrySize
+	}
+
+	return core.DigestInput{
+		Title: fmt.Sprintf(render.T(language, "digest_title"), len(tasks)),
+		Body:  strings.TrimRight(body.String(), "\n"),
+		Count: len(tasks),
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultFeedbackWindow    = 30 * 24 * time.Hour
+	defaultFeedbackThreshold = 3
+	minKeywordLength         = 4
+	maxKeywordRules          = 5
+)
+
+var ErrInvalidRule = errors.New("category rule requires category_id and keyword rule requires keyword")
+
+// stopWords are frequent title words that make no sense as exclusions.
+var stopWords = map[string]bool{
+	"нужно": true, "нужен": true, "нужна": true, "сделать": true, "требуется": true, "задача": true,
+	"проект": true, "работа": true, "для": true, "need": true, "with": true, "from": true, "this": true,
+	"that": true, "project": true, "потрібно": true, "зробити": true,
+}
+
+type FeedbackService struct {
+	repo       *repository.Repository
+	signingKey string
+}
+
+func NewFeedbackService(repo *repository.Repository, signingKey string) *FeedbackService {
+	return &FeedbackService{repo: repo, signingKey: signingKey}
+}
+
+// ApplyCallback verifies a button payload and applies its action to the
+// user who pressed the button. Payloads of user buttons are only accepted
+// from the user they were sent to.
+func (s *FeedbackService) ApplyCallback(input core.CallbackInput) (string, error) {
+	if s.signingKey == "" {
+		return "", ErrNoSigningKey
+	}
+
+	action, id, err := parseCallback(s.signingKey, input.Data, input.TgId)
+	if err != nil {
+		return "", err
+	}
+
+	switch action {
+	case ActionHideTask:
+		err = s.repo.Feedback.NotInterested(input.TgId, id, "")
+	case ActionMuteCategory:
+		err = s.repo.Feedback.MuteCategory(input.TgId, id)
+	case ActionSaveTask:
+		err = s.repo.Feedback.SaveTask(input.TgId, id)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return action, nil
+}
+
+func (s *FeedbackService) SaveTask(input core.FeedbackTaskInput) error {
+	return s.repo.Feedback.SaveTask(input.TgId, input.TaskId)
+}
+
+func (s *FeedbackService) GetSavedTasks(tgId int) ([]core.SavedTaskResponse, error) {
+	return s.repo.Feedback.GetSavedTasks(tgId)
+}
+
+// NotInterested hides the task and returns the exclusions the user may
+// want to apply after this mark.
+func (s *FeedbackService) NotInterested(input core.NotInterestedInput) ([]core.ExclusionRuleResponse, error) {
+	if err := s.repo.Feedback.NotInterested(input.TgId, input.TaskId, input.Reason); err != nil {
+		return nil, err
+	}
+
+	return s.GetSuggestions(input.TgId)
+}
+
+// GetSuggestions turns repeated "not interested" marks into exclusion
+// rules: categories the user is still subscribed to and title keywords
+// that are not excluded yet.
+func (s *FeedbackService) GetSuggestions(tgId int) ([]core.ExclusionRuleResponse, error) {
+	window := viper.GetDuration("feedback.window")
+	if window <= 0 {
+		window = defaultFeedbackWindow
+	}
+
+	threshold := viper.GetInt("feedback.threshold")
+	if threshold <= 0 {
+		threshold = defaultFeedbackThreshold
+	}
+
+	since := time.Now().Add(-window)
+
+	rules, err := s.repo.Feedback.GetCategoryMarks(tgId, since, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := s.repo.Feedback.GetMarkedTitles(tgId, since)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.User.GetByTgId(tgId)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rules, getKeywordRules(titles, user.Setting.ExcludedKeywords, threshold)...), nil
+}
+
+func (s *FeedbackService) ApplyRule(input core.ExclusionRuleInput) error {
+	switch {
+	case input.Type == core.RuleCategory && input.CategoryId != 0:
+		return s.repo.Feedback.MuteCategory(input.TgId, input.CategoryId)
+	case input.Type == core.RuleKeyword && strings.TrimSpace(input.Keyword) != "":
+		return s.repo.Feedback.ExcludeKeyword(input.TgId, strings.ToLower(strings.TrimSpace(input.Keyword)))
+	default:
+		return ErrInvalidRule
+	}
+}
+
+func getKeywordRules(titles, excludedKeywords []string, threshold int) []core.ExclusionRuleResponse {
+	excluded := make(map[string]bool, len(excludedKeywords))
+	for _, keyword := range excludedKeywords {
+		excluded[keyword] = true
+	}
+
+	counts := make(map[string]int)
+	for _, title := range titles {
+		seen := make(map[string]bool)
+		for _, word := range strings.FieldsFunc(strings.ToLower(title), isNotWordRune) {
+			if seen[word] || excluded[word] || stopWords[word] || utf8.RuneCountInString(word) < minKeywordLength {
+				continue
+			}
+			seen[word] = true
+			counts[word]++
+		}
+	}
+
+	var rules []core.ExclusionRuleResponse
+	for word, count := range counts {
+		if count >= threshold {
+			rules = append(rules, core.ExclusionRuleResponse{Type: core.RuleKeyword, Keyword: word, Count: count})
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		return rules[i].Keyword < rules[j].Keyword
+	})
+
+	if len(rules) > maxKeywordRules {
+		rules = rules[:maxKeywordRules]
+	}
+
+	return rules
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -6,6 +6,7 @@ package mock_service
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	core "github.com/max-sanch/BotFreelancer-core"
@@ -63,6 +64,21 @@ func (mr *MockChannelMockRecorder) Delete(apiID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockChannel)(nil).Delete), apiID)
 }
 
+// GetAll mocks base method.
+func (m *MockChannel) GetAll(input core.ChannelListInput) (core.ChannelListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", input)
+	ret0, _ := ret[0].(core.ChannelListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockChannelMockRecorder) GetAll(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockChannel)(nil).GetAll), input)
+}
+
 // GetByApiId mocks base method.
 func (m *MockChannel) GetByApiId(apiId int) (core.ChannelResponse, error) {
 	m.ctrl.T.Helper()
@@ -78,19 +94,179 @@ func (mr *MockChannelMockRecorder) GetByApiId(apiId interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByApiId", reflect.TypeOf((*MockChannel)(nil).GetByApiId), apiId)
 }
 
+// GetCredentials mocks base method.
+func (m *MockChannel) GetCredentials(apiId int) (core.ChannelCredentialsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCredentials", apiId)
+	ret0, _ := ret[0].(core.ChannelCredentialsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCredentials indicates an expected call of GetCredentials.
+func (mr *MockChannelMockRecorder) GetCredentials(apiId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentials", reflect.TypeOf((*MockChannel)(nil).GetCredentials), apiId)
+}
+
+// GetDigests mocks base method.
+func (m *MockChannel) GetDigests(format string) ([]core.ChannelDigestResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigests", format)
+	ret0, _ := ret[0].([]core.ChannelDigestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigests indicates an expected call of GetDigests.
+func (mr *MockChannelMockRecorder) GetDigests(format interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigests", reflect.TypeOf((*MockChannel)(nil).GetDigests), format)
+}
+
 // GetTasks mocks base method.
-func (m *MockChannel) GetTasks() ([]core.ChannelTaskResponse, error) {
+func (m *MockChannel) GetTasks(input core.FeedInput) ([]core.ChannelTaskResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTasks")
+	ret := m.ctrl.Call(m, "GetTasks", input)
 	ret0, _ := ret[0].([]core.ChannelTaskResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTasks indicates an expected call of GetTasks.
-func (mr *MockChannelMockRecorder) GetTasks() *gomock.Call {
+func (mr *MockChannelMockRecorder) GetTasks(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockChannel)(nil).GetTasks), input)
+}
+
+// Patch mocks base method.
+func (m *MockChannel) Patch(apiId int, input core.ChannelPatchInput) (core.ChannelResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", apiId, input)
+	ret0, _ := ret[0].(core.ChannelResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockChannelMockRecorder) Patch(apiId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockChannel)(nil).Patch), apiId, input)
+}
+
+// Pause mocks base method.
+func (m *MockChannel) Pause(apiId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", apiId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockChannelMockRecorder) Pause(apiId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockChannel)(nil).Pause), apiId)
+}
+
+// Purge mocks base method.
+func (m *MockChannel) Purge(apiId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", apiId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockChannelMockRecorder) Purge(apiId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockChannel)(nil).Purge), apiId)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockChannel) PurgeDeleted(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockChannelMockRecorder) PurgeDeleted(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockChannel)(nil).PurgeDeleted), now)
+}
+
+// ReportDelivery mocks base method.
+func (m *MockChannel) ReportDelivery(input core.ChannelDeliveryInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportDelivery", input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportDelivery indicates an expected call of ReportDelivery.
+func (mr *MockChannelMockRecorder) ReportDelivery(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportDelivery", reflect.TypeOf((*MockChannel)(nil).ReportDelivery), input)
+}
+
+// Restore mocks base method.
+func (m *MockChannel) Restore(apiId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", apiId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockChannelMockRecorder) Restore(apiId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockChannel)(nil).Restore), apiId)
+}
+
+// Resume mocks base method.
+func (m *MockChannel) Resume(apiId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", apiId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockChannelMockRecorder) Resume(apiId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockChannel)(nil).Resume), apiId)
+}
+
+// RotateCredentials mocks base method.
+func (m *MockChannel) RotateCredentials() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateCredentials")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateCredentials indicates an expected call of RotateCredentials.
+func (mr *MockChannelMockRecorder) RotateCredentials() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateCredentials", reflect.TypeOf((*MockChannel)(nil).RotateCredentials))
+}
+
+// SetStatus mocks base method.
+func (m *MockChannel) SetStatus(input core.ChannelStatusInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockChannelMockRecorder) SetStatus(input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockChannel)(nil).GetTasks))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockChannel)(nil).SetStatus), input)
 }
 
 // Update mocks base method.
@@ -108,6 +284,21 @@ func (mr *MockChannelMockRecorder) Update(channelInput interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChannel)(nil).Update), channelInput)
 }
 
+// Upsert mocks base method.
+func (m *MockChannel) Upsert(channelInput core.ChannelInput) (core.UpsertResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", channelInput)
+	ret0, _ := ret[0].(core.UpsertResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockChannelMockRecorder) Upsert(channelInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockChannel)(nil).Upsert), channelInput)
+}
+
 // MockUser is a mock of User interface.
 type MockUser struct {
 	ctrl     *gomock.Controller
@@ -131,6 +322,20 @@ func (m *MockUser) EXPECT() *MockUserMockRecorder {
 	return m.recorder
 }
 
+// ApplyQuietHours mocks base method.
+func (m *MockUser) ApplyQuietHours(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyQuietHours", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyQuietHours indicates an expected call of ApplyQuietHours.
+func (mr *MockUserMockRecorder) ApplyQuietHours(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyQuietHours", reflect.TypeOf((*MockUser)(nil).ApplyQuietHours), now)
+}
+
 // Create mocks base method.
 func (m *MockUser) Create(userInput core.UserInput) (int, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +351,78 @@ func (mr *MockUserMockRecorder) Create(userInput interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUser)(nil).Create), userInput)
 }
 
+// Deactivate mocks base method.
+func (m *MockUser) Deactivate(tgId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", tgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockUserMockRecorder) Deactivate(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockUser)(nil).Deactivate), tgId)
+}
+
+// Delete mocks base method.
+func (m *MockUser) Delete(tgId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", tgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserMockRecorder) Delete(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUser)(nil).Delete), tgId)
+}
+
+// Erase mocks base method.
+func (m *MockUser) Erase(tgId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", tgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockUserMockRecorder) Erase(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockUser)(nil).Erase), tgId)
+}
+
+// Export mocks base method.
+func (m *MockUser) Export(tgId int) (core.UserExportResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", tgId)
+	ret0, _ := ret[0].(core.UserExportResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUserMockRecorder) Export(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUser)(nil).Export), tgId)
+}
+
+// GetAll mocks base method.
+func (m *MockUser) GetAll(input core.UserListInput) (core.UserListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", input)
+	ret0, _ := ret[0].(core.UserListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockUserMockRecorder) GetAll(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUser)(nil).GetAll), input)
+}
+
 // GetByTgId mocks base method.
 func (m *MockUser) GetByTgId(tgId int) (core.UserResponse, error) {
 	m.ctrl.T.Helper()
@@ -161,19 +438,92 @@ func (mr *MockUserMockRecorder) GetByTgId(tgId interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTgId", reflect.TypeOf((*MockUser)(nil).GetByTgId), tgId)
 }
 
+// GetDigests mocks base method.
+func (m *MockUser) GetDigests(format string) ([]core.UserDigestResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigests", format)
+	ret0, _ := ret[0].([]core.UserDigestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigests indicates an expected call of GetDigests.
+func (mr *MockUserMockRecorder) GetDigests(format interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigests", reflect.TypeOf((*MockUser)(nil).GetDigests), format)
+}
+
 // GetTasks mocks base method.
-func (m *MockUser) GetTasks() ([]core.UserTaskResponse, error) {
+func (m *MockUser) GetTasks(input core.FeedInput) ([]core.UserTaskResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTasks")
+	ret := m.ctrl.Call(m, "GetTasks", input)
 	ret0, _ := ret[0].([]core.UserTaskResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTasks indicates an expected call of GetTasks.
-func (mr *MockUserMockRecorder) GetTasks() *gomock.Call {
+func (mr *MockUserMockRecorder) GetTasks(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockUser)(nil).GetTasks), input)
+}
+
+// Patch mocks base method.
+func (m *MockUser) Patch(tgId int, input core.UserPatchInput) (core.UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", tgId, input)
+	ret0, _ := ret[0].(core.UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockUserMockRecorder) Patch(tgId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockUser)(nil).Patch), tgId, input)
+}
+
+// Reactivate mocks base method.
+func (m *MockUser) Reactivate(tgId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", tgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockUserMockRecorder) Reactivate(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockUser)(nil).Reactivate), tgId)
+}
+
+// ReportDelivery mocks base method.
+func (m *MockUser) ReportDelivery(input core.UserDeliveryInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportDelivery", input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportDelivery indicates an expected call of ReportDelivery.
+func (mr *MockUserMockRecorder) ReportDelivery(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportDelivery", reflect.TypeOf((*MockUser)(nil).ReportDelivery), input)
+}
+
+// SetStatus mocks base method.
+func (m *MockUser) SetStatus(input core.UserStatusInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockUserMockRecorder) SetStatus(input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockUser)(nil).GetTasks))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockUser)(nil).SetStatus), input)
 }
 
 // Update mocks base method.
@@ -190,3 +540,631 @@ func (mr *MockUserMockRecorder) Update(userInput interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUser)(nil).Update), userInput)
 }
+
+// Upsert mocks base method.
+func (m *MockUser) Upsert(userInput core.UserInput) (core.UpsertResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", userInput)
+	ret0, _ := ret[0].(core.UpsertResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockUserMockRecorder) Upsert(userInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockUser)(nil).Upsert), userInput)
+}
+
+// MockTask is a mock of Task interface.
+type MockTask struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskMockRecorder
+}
+
+// MockTaskMockRecorder is the mock recorder for MockTask.
+type MockTaskMockRecorder struct {
+	mock *MockTask
+}
+
+// NewMockTask creates a new mock instance.
+func NewMockTask(ctrl *gomock.Controller) *MockTask {
+	mock := &MockTask{ctrl: ctrl}
+	mock.recorder = &MockTaskMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTask) EXPECT() *MockTaskMockRecorder {
+	return m.recorder
+}
+
+// Ingest mocks base method.
+func (m *MockTask) Ingest(input core.TasksInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ingest", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ingest indicates an expected call of Ingest.
+func (mr *MockTaskMockRecorder) Ingest(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ingest", reflect.TypeOf((*MockTask)(nil).Ingest), input)
+}
+
+// Search mocks base method.
+func (m *MockTask) Search(input core.TaskSearchInput) (core.TaskSearchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", input)
+	ret0, _ := ret[0].(core.TaskSearchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockTaskMockRecorder) Search(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockTask)(nil).Search), input)
+}
+
+// MockDigest is a mock of Digest interface.
+type MockDigest struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestMockRecorder
+}
+
+// MockDigestMockRecorder is the mock recorder for MockDigest.
+type MockDigestMockRecorder struct {
+	mock *MockDigest
+}
+
+// NewMockDigest creates a new mock instance.
+func NewMockDigest(ctrl *gomock.Controller) *MockDigest {
+	mock := &MockDigest{ctrl: ctrl}
+	mock.recorder = &MockDigestMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigest) EXPECT() *MockDigestMockRecorder {
+	return m.recorder
+}
+
+// Build mocks base method.
+func (m *MockDigest) Build(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Build", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Build indicates an expected call of Build.
+func (mr *MockDigestMockRecorder) Build(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockDigest)(nil).Build), now)
+}
+
+// MockTemplate is a mock of Template interface.
+type MockTemplate struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateMockRecorder
+}
+
+// MockTemplateMockRecorder is the mock recorder for MockTemplate.
+type MockTemplateMockRecorder struct {
+	mock *MockTemplate
+}
+
+// NewMockTemplate creates a new mock instance.
+func NewMockTemplate(ctrl *gomock.Controller) *MockTemplate {
+	mock := &MockTemplate{ctrl: ctrl}
+	mock.recorder = &MockTemplateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplate) EXPECT() *MockTemplateMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTemplate) Create(templateInput core.TemplateInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", templateInput)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTemplateMockRecorder) Create(templateInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTemplate)(nil).Create), templateInput)
+}
+
+// Delete mocks base method.
+func (m *MockTemplate) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTemplateMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTemplate)(nil).Delete), id)
+}
+
+// GetAll mocks base method.
+func (m *MockTemplate) GetAll() ([]core.TemplateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]core.TemplateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockTemplateMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTemplate)(nil).GetAll))
+}
+
+// Preview mocks base method.
+func (m *MockTemplate) Preview(input core.TemplatePreviewInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockTemplateMockRecorder) Preview(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockTemplate)(nil).Preview), input)
+}
+
+// Update mocks base method.
+func (m *MockTemplate) Update(templateInput core.TemplateUpdateInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", templateInput)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTemplateMockRecorder) Update(templateInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTemplate)(nil).Update), templateInput)
+}
+
+// MockFeedback is a mock of Feedback interface.
+type MockFeedback struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedbackMockRecorder
+}
+
+// MockFeedbackMockRecorder is the mock recorder for MockFeedback.
+type MockFeedbackMockRecorder struct {
+	mock *MockFeedback
+}
+
+// NewMockFeedback creates a new mock instance.
+func NewMockFeedback(ctrl *gomock.Controller) *MockFeedback {
+	mock := &MockFeedback{ctrl: ctrl}
+	mock.recorder = &MockFeedbackMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedback) EXPECT() *MockFeedbackMockRecorder {
+	return m.recorder
+}
+
+// ApplyCallback mocks base method.
+func (m *MockFeedback) ApplyCallback(input core.CallbackInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCallback", input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyCallback indicates an expected call of ApplyCallback.
+func (mr *MockFeedbackMockRecorder) ApplyCallback(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCallback", reflect.TypeOf((*MockFeedback)(nil).ApplyCallback), input)
+}
+
+// ApplyRule mocks base method.
+func (m *MockFeedback) ApplyRule(input core.ExclusionRuleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyRule", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyRule indicates an expected call of ApplyRule.
+func (mr *MockFeedbackMockRecorder) ApplyRule(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyRule", reflect.TypeOf((*MockFeedback)(nil).ApplyRule), input)
+}
+
+// GetSavedTasks mocks base method.
+func (m *MockFeedback) GetSavedTasks(tgId int) ([]core.SavedTaskResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedTasks", tgId)
+	ret0, _ := ret[0].([]core.SavedTaskResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedTasks indicates an expected call of GetSavedTasks.
+func (mr *MockFeedbackMockRecorder) GetSavedTasks(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedTasks", reflect.TypeOf((*MockFeedback)(nil).GetSavedTasks), tgId)
+}
+
+// GetSuggestions mocks base method.
+func (m *MockFeedback) GetSuggestions(tgId int) ([]core.ExclusionRuleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSuggestions", tgId)
+	ret0, _ := ret[0].([]core.ExclusionRuleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSuggestions indicates an expected call of GetSuggestions.
+func (mr *MockFeedbackMockRecorder) GetSuggestions(tgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSuggestions", reflect.TypeOf((*MockFeedback)(nil).GetSuggestions), tgId)
+}
+
+// NotInterested mocks base method.
+func (m *MockFeedback) NotInterested(input core.NotInterestedInput) ([]core.ExclusionRuleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotInterested", input)
+	ret0, _ := ret[0].([]core.ExclusionRuleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotInterested indicates an expected call of NotInterested.
+func (mr *MockFeedbackMockRecorder) NotInterested(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotInterested", reflect.TypeOf((*MockFeedback)(nil).NotInterested), input)
+}
+
+// SaveTask mocks base method.
+func (m *MockFeedback) SaveTask(input core.FeedbackTaskInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTask", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTask indicates an expected call of SaveTask.
+func (mr *MockFeedbackMockRecorder) SaveTask(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTask", reflect.TypeOf((*MockFeedback)(nil).SaveTask), input)
+}
+
+// MockClick is a mock of Click interface.
+type MockClick struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickMockRecorder
+}
+
+// MockClickMockRecorder is the mock recorder for MockClick.
+type MockClickMockRecorder struct {
+	mock *MockClick
+}
+
+// NewMockClick creates a new mock instance.
+func NewMockClick(ctrl *gomock.Controller) *MockClick {
+	mock := &MockClick{ctrl: ctrl}
+	mock.recorder = &MockClickMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClick) EXPECT() *MockClickMockRecorder {
+	return m.recorder
+}
+
+// GetCategoryRates mocks base method.
+func (m *MockClick) GetCategoryRates(input core.PeriodInput) ([]core.ClickRateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRates", input)
+	ret0, _ := ret[0].([]core.ClickRateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRates indicates an expected call of GetCategoryRates.
+func (mr *MockClickMockRecorder) GetCategoryRates(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRates", reflect.TypeOf((*MockClick)(nil).GetCategoryRates), input)
+}
+
+// GetSourceRates mocks base method.
+func (m *MockClick) GetSourceRates(input core.PeriodInput) ([]core.ClickRateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSourceRates", input)
+	ret0, _ := ret[0].([]core.ClickRateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSourceRates indicates an expected call of GetSourceRates.
+func (mr *MockClickMockRecorder) GetSourceRates(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSourceRates", reflect.TypeOf((*MockClick)(nil).GetSourceRates), input)
+}
+
+// Open mocks base method.
+func (m *MockClick) Open(token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockClickMockRecorder) Open(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockClick)(nil).Open), token)
+}
+
+// MockStats is a mock of Stats interface.
+type MockStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsMockRecorder
+}
+
+// MockStatsMockRecorder is the mock recorder for MockStats.
+type MockStatsMockRecorder struct {
+	mock *MockStats
+}
+
+// NewMockStats creates a new mock instance.
+func NewMockStats(ctrl *gomock.Controller) *MockStats {
+	mock := &MockStats{ctrl: ctrl}
+	mock.recorder = &MockStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStats) EXPECT() *MockStatsMockRecorder {
+	return m.recorder
+}
+
+// GetDeliveryCounts mocks base method.
+func (m *MockStats) GetDeliveryCounts(input core.PeriodInput) ([]core.DeliveryCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryCounts", input)
+	ret0, _ := ret[0].([]core.DeliveryCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryCounts indicates an expected call of GetDeliveryCounts.
+func (mr *MockStatsMockRecorder) GetDeliveryCounts(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryCounts", reflect.TypeOf((*MockStats)(nil).GetDeliveryCounts), input)
+}
+
+// GetDeliveryLatency mocks base method.
+func (m *MockStats) GetDeliveryLatency(input core.PeriodInput) (core.LatencyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryLatency", input)
+	ret0, _ := ret[0].(core.LatencyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryLatency indicates an expected call of GetDeliveryLatency.
+func (mr *MockStatsMockRecorder) GetDeliveryLatency(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryLatency", reflect.TypeOf((*MockStats)(nil).GetDeliveryLatency), input)
+}
+
+// GetSubscriberCounts mocks base method.
+func (m *MockStats) GetSubscriberCounts() (core.SubscriberCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriberCounts")
+	ret0, _ := ret[0].(core.SubscriberCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriberCounts indicates an expected call of GetSubscriberCounts.
+func (mr *MockStatsMockRecorder) GetSubscriberCounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriberCounts", reflect.TypeOf((*MockStats)(nil).GetSubscriberCounts))
+}
+
+// GetTaskCounts mocks base method.
+func (m *MockStats) GetTaskCounts(input core.PeriodInput) ([]core.TaskCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskCounts", input)
+	ret0, _ := ret[0].([]core.TaskCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskCounts indicates an expected call of GetTaskCounts.
+func (mr *MockStatsMockRecorder) GetTaskCounts(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskCounts", reflect.TypeOf((*MockStats)(nil).GetTaskCounts), input)
+}
+
+// GetTopCategories mocks base method.
+func (m *MockStats) GetTopCategories(input core.PeriodInput, limit int) ([]core.CategoryCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopCategories", input, limit)
+	ret0, _ := ret[0].([]core.CategoryCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopCategories indicates an expected call of GetTopCategories.
+func (mr *MockStatsMockRecorder) GetTopCategories(input, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopCategories", reflect.TypeOf((*MockStats)(nil).GetTopCategories), input, limit)
+}
+
+// MockApiKey is a mock of ApiKey interface.
+type MockApiKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyMockRecorder
+}
+
+// MockApiKeyMockRecorder is the mock recorder for MockApiKey.
+type MockApiKeyMockRecorder struct {
+	mock *MockApiKey
+}
+
+// NewMockApiKey creates a new mock instance.
+func NewMockApiKey(ctrl *gomock.Controller) *MockApiKey {
+	mock := &MockApiKey{ctrl: ctrl}
+	mock.recorder = &MockApiKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKey) EXPECT() *MockApiKeyMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockApiKey) Authenticate(key string) (core.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", key)
+	ret0, _ := ret[0].(core.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockApiKeyMockRecorder) Authenticate(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockApiKey)(nil).Authenticate), key)
+}
+
+// AuthenticateSigned mocks base method.
+func (m *MockApiKey) AuthenticateSigned(input core.SignedRequestInput, now time.Time) (core.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateSigned", input, now)
+	ret0, _ := ret[0].(core.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateSigned indicates an expected call of AuthenticateSigned.
+func (mr *MockApiKeyMockRecorder) AuthenticateSigned(input, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateSigned", reflect.TypeOf((*MockApiKey)(nil).AuthenticateSigned), input, now)
+}
+
+// Create mocks base method.
+func (m *MockApiKey) Create(input core.ApiKeyInput) (core.ApiKeyCreatedResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(core.ApiKeyCreatedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApiKeyMockRecorder) Create(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApiKey)(nil).Create), input)
+}
+
+// GetAll mocks base method.
+func (m *MockApiKey) GetAll() ([]core.ApiKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]core.ApiKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockApiKeyMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockApiKey)(nil).GetAll))
+}
+
+// Revoke mocks base method.
+func (m *MockApiKey) Revoke(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockApiKeyMockRecorder) Revoke(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockApiKey)(nil).Revoke), id)
+}
+
+// RotateSecrets mocks base method.
+func (m *MockApiKey) RotateSecrets() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSecrets")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSecrets indicates an expected call of RotateSecrets.
+func (mr *MockApiKeyMockRecorder) RotateSecrets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSecrets", reflect.TypeOf((*MockApiKey)(nil).RotateSecrets))
+}
+
+// MockRetention is a mock of Retention interface.
+type MockRetention struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetentionMockRecorder
+}
+
+// MockRetentionMockRecorder is the mock recorder for MockRetention.
+type MockRetentionMockRecorder struct {
+	mock *MockRetention
+}
+
+// NewMockRetention creates a new mock instance.
+func NewMockRetention(ctrl *gomock.Controller) *MockRetention {
+	mock := &MockRetention{ctrl: ctrl}
+	mock.recorder = &MockRetentionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetention) EXPECT() *MockRetentionMockRecorder {
+	return m.recorder
+}
+
+// Archive mocks base method.
+func (m *MockRetention) Archive(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockRetentionMockRecorder) Archive(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockRetention)(nil).Archive), now)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+// patchAttempts limits how often a patch is applied again after losing a
+// race with a concurrent update.
+const patchAttempts = 3
+
+// retryPatch runs patch, which reads a record, changes it and writes it
+// back under the version it read. A patch that lost a race is applied
+// again to the new state, unless the client asked for a specific version.
+func retryPatch(version int, patch func() error) error {
+	var err error
+	for attempt := 0; attempt < patchAttempts; attempt++ {
+		err = patch()
+		if version != 0 || !errors.Is(err, core.ErrConflict) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// settingInput turns stored settings back into input, e.g. to apply a
+// patch on top of them.
+func settingInput(setting core.SettingResponse) core.SettingInput {
+	isSafeDeal, isBudget, isTerm := setting.IsSafeDeal, setting.IsBudget, setting.IsTerm
+
+	return core.SettingInput{
+		IsSafeDeal:   &isSafeDeal,
+		IsBudget:     &isBudget,
+		IsTerm:       &isTerm,
+		Categories:   setting.Categories,
+		DeliveryMode: setting.DeliveryMode,
+		DigestTime:   setting.DigestTime,
+		TemplateId:   setting.TemplateId,
+	}
+}
+
+// patchSetting changes the settings present in patch.
+func patchSetting(setting *core.SettingInput, patch core.SettingPatchInput) {
+	if patch.IsSafeDeal != nil {
+		setting.IsSafeDeal = patch.IsSafeDeal
+	}
+	if patch.IsBudget != nil {
+		setting.IsBudget = patch.IsBudget
+	}
+	if patch.IsTerm != nil {
+		setting.IsTerm = patch.IsTerm
+	}
+	if patch.Categories != nil {
+		setting.Categories = *patch.Categories
+	}
+	for _, categoryId := range patch.AddCategories {
+		if !containsInt(setting.Categories, categoryId) {
+			setting.Categories = append(setting.Categories, categoryId)
+		}
+	}
+	if len(patch.RemoveCategories) > 0 {
+		categories := make([]int, 0, len(setting.Categories))
+		for _, categoryId := range setting.Categories {
+			if !containsInt(patch.RemoveCategories, categoryId) {
+				categories = append(categories, categoryId)
+			}
+		}
+		setting.Categories = categories
+	}
+	if patch.DeliveryMode != nil {
+		setting.DeliveryMode = *patch.DeliveryMode
+	}
+	if patch.DigestTime != nil {
+		setting.DigestTime = *patch.DigestTime
+	}
+	if patch.TemplateId != nil {
+		setting.TemplateId = patch.TemplateId
+	}
+	if patch.ClearTemplate {
+		setting.TemplateId = nil
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
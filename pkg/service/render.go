@@ -0,0 +1,200 @@
+package service
+
+import (
+	"text/template"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// taskRenderer renders task messages with the templates and languages
+// chosen by users and channels. Recipients without a template, or with a
+// template that fails, get the default one.
+type taskRenderer struct {
+	templates       map[int]*template.Template
+	defaultTemplate *template.Template
+	buttonsKey      string
+	clicks          *clickTracker
+	links           repository.Click
+}
+
+func newTaskRenderer(repo *repository.Repository) (*taskRenderer, error) {
+	defaultTemplate, err := render.Parse("default", render.DefaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	storedTemplates, err := repo.Template.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[int]*template.Template, len(storedTemplates))
+	for _, storedTemplate := range storedTemplates {
+		tmpl, err := render.Parse(storedTemplate.Name, storedTemplate.Body)
+		if err != nil {
+			logrus.Warnf("template %d is skipped: %s", storedTemplate.Id, err.Error())
+			continue
+		}
+		templates[storedTemplate.Id] = tmpl
+	}
+
+	return &taskRenderer{templates: templates, defaultTemplate: defaultTemplate, links: repo.Click}, nil
+}
+
+// newFeedRenderer prepares a renderer for task feeds: with buttons when
+// they were requested and with tracked links when clicks are enabled.
+func newFeedRenderer(repo *repository.Repository, signingKey string, buttons bool) (*taskRenderer, error) {
+	renderer, err := newTaskRenderer(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if buttons {
+		if err := renderer.EnableButtons(signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if viper.GetString("clicks.enabled") == "True" {
+		if err := renderer.EnableClicks(signingKey, viper.GetString("clicks.base_url")); err != nil {
+			return nil, err
+		}
+	}
+
+	return renderer, nil
+}
+
+// newBatchRenderer prepares a renderer for a freshly ingested batch. It
+// creates the tracked links that feeds of the batch later look up.
+func newBatchRenderer(repo *repository.Repository, signingKey string, buttons bool) (*taskRenderer, error) {
+	renderer, err := newFeedRenderer(repo, signingKey, buttons)
+	if err != nil {
+		return nil, err
+	}
+
+	if renderer.clicks != nil {
+		renderer.clicks.create = true
+	}
+
+	return renderer, nil
+}
+
+// EnableButtons adds inline buttons with callback payloads signed by key
+// to rendered tasks.
+func (r *taskRenderer) EnableButtons(key string) error {
+	if key == "" {
+		return ErrNoSigningKey
+	}
+
+	r.buttonsKey = key
+	return nil
+}
+
+// EnableClicks replaces task urls with redirect links under baseUrl that
+// log clicks before sending the reader to the task.
+func (r *taskRenderer) EnableClicks(key, baseUrl string) error {
+	if key == "" {
+		return ErrNoSigningKey
+	}
+
+	r.clicks = &clickTracker{repo: r.links, key: key, baseUrl: baseUrl}
+	return nil
+}
+
+func (r *taskRenderer) Render(templateId *int, language string, task core.Task) (string, error) {
+	if templateId != nil {
+		if tmpl, ok := r.templates[*templateId]; ok {
+			body, err := render.Execute(tmpl, task, language)
+			if err == nil {
+				return body, nil
+			}
+			logrus.Warnf("template %d failed on task %s: %s", *templateId, task.Url, err.Error())
+		}
+	}
+
+	return render.Execute(r.defaultTemplate, task, language)
+}
+
+func (r *taskRenderer) RenderUserTasks(tasks []core.UserTask) ([]core.UserTaskResponse, error) {
+	result := make([]core.UserTaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		if r.clicks != nil {
+			url, err := r.clicks.userUrl(task.TgId, task.Task)
+			if err != nil {
+				return nil, err
+			}
+			task.Url = url
+		}
+
+		body, err := r.Render(task.TemplateId, task.Language, task.Task)
+		if err != nil {
+			return nil, err
+		}
+
+		response := core.UserTaskResponse{
+			TgId:   task.TgId,
+			TaskId: task.Id,
+			Title:  task.Title,
+			Body:   body,
+			Url:    task.Url,
+		}
+		if r.buttonsKey != "" {
+			response.Buttons = userTaskButtons(r.buttonsKey, task.Language, task.TgId, task.Task)
+		}
+
+		result = append(result, response)
+	}
+
+	return result, nil
+}
+
+func (r *taskRenderer) RenderChannelTasks(tasks []core.ChannelTask) ([]core.ChannelTaskResponse, error) {
+	result := make([]core.ChannelTaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		if r.clicks != nil {
+			url, err := r.clicks.channelUrl(task.ApiId, task.Task)
+			if err != nil {
+				return nil, err
+			}
+			task.Url = url
+		}
+
+		body, err := r.Render(task.TemplateId, task.Language, task.Task)
+		if err != nil {
+			return nil, err
+		}
+
+		response := core.ChannelTaskResponse{
+			ApiId:  task.ApiId,
+			TaskId: task.Id,
+			Title:  task.Title,
+			Body:   body,
+			Url:    task.Url,
+		}
+		if r.buttonsKey != "" {
+			response.Buttons = channelTaskButtons(r.buttonsKey, task.Language, task.Task)
+		}
+
+		result = append(result, response)
+	}
+
+	return result, nil
+}
+
+// formatMessage prepares a message for the requested output format. An
+// empty format leaves the feed as it was before formats existed.
+func formatMessage(format, title, body, url string) core.FormattedMessage {
+	if format == "" {
+		return core.FormattedMessage{}
+	}
+
+	return core.FormattedMessage{
+		ParseMode: render.ParseMode(format),
+		Messages:  render.Messages(format, title, body, url),
+	}
+}
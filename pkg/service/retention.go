@@ -0,0 +1,28 @@
+package service
+
+import (
+	"time"
+
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+
+	"github.com/spf13/viper"
+)
+
+type RetentionService struct {
+	repo *repository.Repository
+}
+
+func NewRetentionService(repo *repository.Repository) *RetentionService {
+	return &RetentionService{repo: repo}
+}
+
+// Archive moves tasks ingested more than retention.window before now to
+// the archive table. A zero window keeps all tasks in place.
+func (s *RetentionService) Archive(now time.Time) (int64, error) {
+	window := viper.GetDuration("retention.window")
+	if window <= 0 {
+		return 0, nil
+	}
+
+	return s.repo.Task.ArchiveTasks(now.Add(-window))
+}
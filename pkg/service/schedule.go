@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+)
+
+const defaultTimezone = "UTC"
+
+// ErrInvalidInput matches, through errors.Is, the errors of input that
+// passed binding but failed validation in a service.
+var ErrInvalidInput = core.ErrInvalidInput
+
+func invalidInputf(format string, args ...interface{}) error {
+	return core.NewError(core.ErrInvalidInput, fmt.Sprintf(format, args...))
+}
+
+// parseClock converts "HH:MM" into minutes since midnight.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, invalidInputf("invalid time %q, expected HH:MM", clock)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func localTime(timezone string, now time.Time) time.Time {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return now.In(location)
+}
+
+func normalizeDelivery(setting *core.SettingInput) error {
+	if setting.DeliveryMode == "" {
+		setting.DeliveryMode = core.DeliveryInstant
+	}
+
+	if setting.DeliveryMode != core.DeliveryDaily {
+		return nil
+	}
+
+	if _, err := parseClock(setting.DigestTime); err != nil {
+		return invalidInputf("daily digest requires digest_time: %s", err.Error())
+	}
+
+	return nil
+}
+
+func validateSchedule(timezone string, quietHours *core.QuietHours) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return invalidInputf("unknown timezone %q", timezone)
+	}
+
+	if quietHours == nil {
+		return nil
+	}
+
+	if _, err := parseClock(quietHours.From); err != nil {
+		return err
+	}
+
+	if _, err := parseClock(quietHours.To); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// isQuietTime reports whether now falls into the quiet window. The window
+// may wrap around midnight, e.g. 23:00-07:00.
+func isQuietTime(timezone string, quietHours core.QuietHours, now time.Time) bool {
+	from, err := parseClock(quietHours.From)
+	if err != nil {
+		return false
+	}
+
+	to, err := parseClock(quietHours.To)
+	if err != nil || from == to {
+		return false
+	}
+
+	local := localTime(timezone, now)
+	minutes := local.Hour()*60 + local.Minute()
+
+	if from < to {
+		return minutes >= from && minutes < to
+	}
+
+	return minutes >= from || minutes < to
+}
+
+// digestPeriodStart returns the moment the current digest period began:
+// the start of the local hour for hourly digests and the latest occurrence
+// of the chosen local time for daily ones. Tasks queued before it are due.
+// Hours start in the user's timezone, which is not on the UTC hour for
+// offsets such as +05:30.
+func digestPeriodStart(schedule core.DigestScheduleResponse, now time.Time) time.Time {
+	switch schedule.DeliveryMode {
+	case core.DeliveryHourly:
+		local := localTime(schedule.Timezone, now)
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, local.Location())
+	case core.DeliveryDaily:
+		minutes, err := parseClock(schedule.DigestTime)
+		if err != nil {
+			minutes = 0
+		}
+
+		local := localTime(schedule.Timezone, now)
+		start := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, local.Location())
+		if start.After(local) {
+			start = start.AddDate(0, 0, -1)
+		}
+		return start
+	default:
+		return now
+	}
+}
@@ -1,35 +1,153 @@
 package service
 
 import (
+	"time"
+
 	core "github.com/max-sanch/BotFreelancer-core"
 	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+	"github.com/max-sanch/BotFreelancer-core/pkg/secret"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Channel interface {
-	GetTasks() ([]core.ChannelTaskResponse, error)
+	GetTasks(input core.FeedInput) ([]core.ChannelTaskResponse, error)
+	GetDigests(format string) ([]core.ChannelDigestResponse, error)
 	GetByApiId(apiId int) (core.ChannelResponse, error)
 	Create(channelInput core.ChannelInput) (int, error)
 	Update(channelInput core.ChannelInput) (int, error)
+	Upsert(channelInput core.ChannelInput) (core.UpsertResponse, error)
+	Patch(apiId int, input core.ChannelPatchInput) (core.ChannelResponse, error)
 	Delete(apiID int) error
+	GetAll(input core.ChannelListInput) (core.ChannelListResponse, error)
+	Restore(apiId int) error
+	Purge(apiId int) error
+	PurgeDeleted(now time.Time) (int64, error)
+	Pause(apiId int) error
+	Resume(apiId int) error
+	GetCredentials(apiId int) (core.ChannelCredentialsResponse, error)
+	RotateCredentials() (int, error)
+	SetStatus(input core.ChannelStatusInput) error
+	ReportDelivery(input core.ChannelDeliveryInput) (string, error)
 }
 
 type User interface {
-	GetTasks() ([]core.UserTaskResponse, error)
+	GetTasks(input core.FeedInput) ([]core.UserTaskResponse, error)
+	GetDigests(format string) ([]core.UserDigestResponse, error)
+	ApplyQuietHours(now time.Time) error
 	GetByTgId(tgId int) (core.UserResponse, error)
 	Create(userInput core.UserInput) (int, error)
 	Update(userInput core.UserInput) (int, error)
+	Upsert(userInput core.UserInput) (core.UpsertResponse, error)
+	Patch(tgId int, input core.UserPatchInput) (core.UserResponse, error)
+	GetAll(input core.UserListInput) (core.UserListResponse, error)
+	Delete(tgId int) error
+	Deactivate(tgId int) error
+	Reactivate(tgId int) error
+	Erase(tgId int) error
+	Export(tgId int) (core.UserExportResponse, error)
+	SetStatus(input core.UserStatusInput) error
+	ReportDelivery(input core.UserDeliveryInput) (string, error)
+}
+
+type Task interface {
+	Search(input core.TaskSearchInput) (core.TaskSearchResponse, error)
+	Ingest(input core.TasksInput) error
+}
+
+type Digest interface {
+	Build(now time.Time) error
+}
+
+type Template interface {
+	GetAll() ([]core.TemplateResponse, error)
+	Create(templateInput core.TemplateInput) (int, error)
+	Update(templateInput core.TemplateUpdateInput) (int, error)
+	Delete(id int) error
+	Preview(input core.TemplatePreviewInput) (string, error)
+}
+
+type Feedback interface {
+	ApplyCallback(input core.CallbackInput) (string, error)
+	SaveTask(input core.FeedbackTaskInput) error
+	GetSavedTasks(tgId int) ([]core.SavedTaskResponse, error)
+	NotInterested(input core.NotInterestedInput) ([]core.ExclusionRuleResponse, error)
+	GetSuggestions(tgId int) ([]core.ExclusionRuleResponse, error)
+	ApplyRule(input core.ExclusionRuleInput) error
+}
+
+type Click interface {
+	Open(token string) (string, error)
+	GetCategoryRates(input core.PeriodInput) ([]core.ClickRateResponse, error)
+	GetSourceRates(input core.PeriodInput) ([]core.ClickRateResponse, error)
+}
+
+type Stats interface {
+	GetTaskCounts(input core.PeriodInput) ([]core.TaskCountResponse, error)
+	GetDeliveryCounts(input core.PeriodInput) ([]core.DeliveryCountResponse, error)
+	GetSubscriberCounts() (core.SubscriberCountResponse, error)
+	GetDeliveryLatency(input core.PeriodInput) (core.LatencyResponse, error)
+	GetTopCategories(input core.PeriodInput, limit int) ([]core.CategoryCountResponse, error)
+}
+
+type ApiKey interface {
+	Authenticate(key string) (core.ApiKey, error)
+	AuthenticateSigned(input core.SignedRequestInput, now time.Time) (core.ApiKey, error)
+	Create(input core.ApiKeyInput) (core.ApiKeyCreatedResponse, error)
+	RotateSecrets() (int, error)
+	GetAll() ([]core.ApiKeyResponse, error)
+	Revoke(id int) error
+}
+
+type Retention interface {
+	Archive(now time.Time) (int64, error)
+}
+
+// Config holds the secrets services need at runtime.
+type Config struct {
+	// SigningKey signs the callback payloads of task buttons and the
+	// tokens of tracked links.
+	SigningKey string
+	// ErasureKey keys the HMAC of tg_ids kept in the erasure audit log.
+	// Users can not be erased without it.
+	ErasureKey string
+	// CredentialsKeys seal the api_hash of channels and the signing secrets
+	// of API keys. Without keys channels can not be created or updated.
+	CredentialsKeys *secret.Keyring
+	// BootstrapKey is an admin API key that works without being stored,
+	// so the first keys can be issued.
+	BootstrapKey string
+	// ParserKeyId and ParserSecret sign the requests to url_parse_tasks.
+	// Requests are sent unsigned without a secret.
+	ParserKeyId  string
+	ParserSecret string
 }
 
 type Service struct {
 	Channel
 	User
+	Digest
+	Template
+	Feedback
+	Click
+	Stats
+	Retention
+	Task
+	ApiKey
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, config Config) *Service {
 	return &Service{
-		Channel: NewChannelService(repos),
-		User:    NewUserService(repos),
+		Channel: NewChannelService(repos, config.SigningKey, config.CredentialsKeys, config.ParserKeyId,
+			config.ParserSecret),
+		User:      NewUserService(repos, config.SigningKey, config.ErasureKey),
+		Digest:    NewDigestService(repos),
+		Template:  NewTemplateService(repos),
+		Feedback:  NewFeedbackService(repos, config.SigningKey),
+		Click:     NewClickService(repos, config.SigningKey),
+		Stats:     NewStatsService(repos),
+		Retention: NewRetentionService(repos),
+		Task:      NewTaskService(repos, config.SigningKey),
+		ApiKey:    NewApiKeyService(repos, config.BootstrapKey, config.CredentialsKeys),
 	}
 }
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+)
+
+const defaultTopCategories = 10
+
+type StatsService struct {
+	repo *repository.Repository
+}
+
+func NewStatsService(repo *repository.Repository) *StatsService {
+	return &StatsService{repo: repo}
+}
+
+func (s *StatsService) GetTaskCounts(input core.PeriodInput) ([]core.TaskCountResponse, error) {
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Stats.GetTaskCounts(from, to)
+}
+
+func (s *StatsService) GetDeliveryCounts(input core.PeriodInput) ([]core.DeliveryCountResponse, error) {
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Stats.GetDeliveryCounts(from, to)
+}
+
+func (s *StatsService) GetSubscriberCounts() (core.SubscriberCountResponse, error) {
+	return s.repo.Stats.GetSubscriberCounts()
+}
+
+func (s *StatsService) GetDeliveryLatency(input core.PeriodInput) (core.LatencyResponse, error) {
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return core.LatencyResponse{}, err
+	}
+
+	return s.repo.Stats.GetDeliveryLatency(from, to)
+}
+
+func (s *StatsService) GetTopCategories(input core.PeriodInput, limit int) ([]core.CategoryCountResponse, error) {
+	if limit <= 0 {
+		limit = defaultTopCategories
+	}
+
+	from, to, err := periodBounds(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Stats.GetTopCategories(from, to, limit)
+}
+
+// userMatches pairs the matched tasks with their rendered responses, which
+// come in the same order and carry the url the task is delivered under.
+func userMatches(userTasks []core.UserTask, tasks []core.UserTaskResponse) []core.MatchInput {
+	matches := make([]core.MatchInput, 0, len(tasks))
+	for i, task := range tasks {
+		matches = append(matches, newMatch(task.TgId, task.Url, userTasks[i].Task))
+	}
+
+	return matches
+}
+
+func channelMatches(channelTasks []core.ChannelTask, tasks []core.ChannelTaskResponse) []core.MatchInput {
+	matches := make([]core.MatchInput, 0, len(tasks))
+	for i, task := range tasks {
+		matches = append(matches, newMatch(task.ApiId, task.Url, channelTasks[i].Task))
+	}
+
+	return matches
+}
+
+func newMatch(key int, url string, task core.Task) core.MatchInput {
+	match := core.MatchInput{Key: key, Url: url, FLName: task.FLName, CategoryId: task.CategoryId}
+	if published, err := render.ParseDateTime(task.DateTime); err == nil {
+		match.PublishedAt = &published
+	}
+
+	return match
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"time"
+
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+)
+
+const defaultPerPage = 20
+
+type TaskService struct {
+	repo       *repository.Repository
+	signingKey string
+}
+
+func NewTaskService(repo *repository.Repository, signingKey string) *TaskService {
+	return &TaskService{repo: repo, signingKey: signingKey}
+}
+
+// Ingest stores a batch pushed by a parser and dispatches it to users like
+// a batch pulled from url_parse_tasks. The pull then continues after it.
+func (s *TaskService) Ingest(input core.TasksInput) error {
+	if len(input.Tasks) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Task.AddTasks(input); err != nil {
+		return err
+	}
+
+	if err := s.repo.Task.SetLastParseTime(); err != nil {
+		return err
+	}
+
+	return dispatchUserBatch(s.repo, s.signingKey, time.Now())
+}
+
+// Search finds stored and archived tasks. Both ends of the day range are
+// inclusive and either may be omitted.
+func (s *TaskService) Search(input core.TaskSearchInput) (core.TaskSearchResponse, error) {
+	page, perPage, offset := pageBounds(input.PageInput)
+
+	filter := core.TaskSearchFilter{
+		Query:     input.Query,
+		Category:  input.Category,
+		MinBudget: input.MinBudget,
+		Source:    input.Source,
+		From:      input.From,
+		Limit:     perPage,
+		Offset:    offset,
+	}
+	if !input.To.IsZero() {
+		filter.To = input.To.AddDate(0, 0, 1)
+	}
+
+	tasks, total, err := s.repo.Task.Search(filter)
+	if err != nil {
+		return core.TaskSearchResponse{}, err
+	}
+
+	return core.TaskSearchResponse{Tasks: tasks, Total: total, Page: page, PerPage: perPage}, nil
+}
+
+// pageBounds fills in the defaults of a page request and returns the page,
+// its size and the offset of its first row.
+func pageBounds(input core.PageInput) (int, int, int) {
+	page := input.Page
+	if page == 0 {
+		page = 1
+	}
+
+	perPage := input.PerPage
+	if perPage == 0 {
+		perPage = defaultPerPage
+	}
+
+	return page, perPage, (page - 1) * perPage
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
+	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+)
+
+type TemplateService struct {
+	repo *repository.Repository
+}
+
+func NewTemplateService(repo *repository.Repository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+func (s *TemplateService) GetAll() ([]core.TemplateResponse, error) {
+	return s.repo.Template.GetAll()
+}
+
+func (s *TemplateService) Create(templateInput core.TemplateInput) (int, error) {
+	if err := render.Validate(templateInput.Body); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Template.Create(templateInput)
+}
+
+func (s *TemplateService) Update(templateInput core.TemplateUpdateInput) (int, error) {
+	if err := render.Validate(templateInput.Body); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Template.Update(templateInput)
+}
+
+func (s *TemplateService) Delete(id int) error {
+	return s.repo.Template.Delete(id)
+}
+
+// Preview renders the template in the given language with the given task,
+// or with a sample task when none is passed.
+func (s *TemplateService) Preview(input core.TemplatePreviewInput) (string, error) {
+	tmpl, err := render.Parse("preview", input.Body)
+	if err != nil {
+		return "", err
+	}
+
+	task := render.SampleTask
+	if input.Task != nil {
+		task = core.Task{
+			FLName:          input.Task.FLName,
+			FLUrl:           input.Task.FLUrl,
+			Url:             input.Task.TaskUrl,
+			Category:        input.Task.Category,
+			Title:           input.Task.Title,
+			Description:     input.Task.Description,
+			Budget:          input.Task.Budget,
+			IsBudgetPerHour: input.Task.IsBudgetPerHour,
+			Term:            input.Task.Term,
+			IsSafeDeal:      input.Task.IsSafeDeal,
+			DateTime:        input.Task.DateTime,
+		}
+	}
+
+	language := input.Language
+	if language == "" {
+		language = render.DefaultLanguage
+	}
+
+	return render.Execute(tmpl, task, language)
+}
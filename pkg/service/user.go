@@ -1,35 +1,572 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	core "github.com/max-sanch/BotFreelancer-core"
+	"github.com/max-sanch/BotFreelancer-core/pkg/render"
 	"github.com/max-sanch/BotFreelancer-core/pkg/repository"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrUserNotFound = core.NewError(core.ErrNotFound, "user not found")
+	ErrUserExists   = core.NewError(core.ErrAlreadyExists, "user already exists")
+	ErrNoErasureKey = core.NewError(core.ErrNotConfigured, "erasure key is not configured")
 )
 
 type UserService struct {
-	repo *repository.Repository
+	repo       *repository.Repository
+	signingKey string
+	erasureKey string
 }
 
-func (s *UserService) GetTasks() ([]core.UserTaskResponse, error) {
-	tasks, err := s.repo.Task.GetAllForUsers()
+func (s *UserService) GetTasks(input core.FeedInput) ([]core.UserTaskResponse, error) {
+	userTasks, err := s.repo.Task.GetAllForUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	digestUsers, err := s.repo.Digest.GetDigestUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Tasks of digest users were queued when the batch was ingested.
+	userTasks, _ = splitDigestTasks(userTasks, digestUsers)
+
+	schedules, err := s.repo.User.GetSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	heldTasks, err := s.repo.Task.GetHeldTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	userTasks, heldTasks = applyQuietHours(userTasks, heldTasks, schedules, time.Now())
+
+	renderer, err := newFeedRenderer(s.repo, s.signingKey, input.Buttons)
 	if err != nil {
 		return nil, err
 	}
 
+	tasks, err := renderer.RenderUserTasks(userTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks = append(heldTasks, tasks...)
+	for i, task := range tasks {
+		tasks[i].FormattedMessage = formatMessage(input.Format, task.Title, task.Body, task.Url)
+	}
+
 	return tasks, nil
 }
 
-func NewUserService(repo *repository.Repository) *UserService {
-	return &UserService{repo: repo}
+// GetDigests returns the digests that were not delivered yet. A digest is
+// served once a delivery report with its digest_id arrives.
+func (s *UserService) GetDigests(format string) ([]core.UserDigestResponse, error) {
+	digests, err := s.repo.Digest.GetUserDigests()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, digest := range digests {
+		digests[i].FormattedMessage = formatMessage(format, digest.Title, digest.Body, "")
+	}
+
+	return digests, nil
+}
+
+// ApplyQuietHours holds the tasks of users who are in their quiet hours and
+// turns the tasks held for users whose quiet hours are over into a digest
+// when they asked for one. Other held tasks stay in the feed until their
+// delivery is reported.
+func (s *UserService) ApplyQuietHours(now time.Time) error {
+	schedules, err := s.repo.User.GetSchedules()
+	if err != nil {
+		return err
+	}
+
+	if err := holdQuietTasks(s.repo, s.signingKey, schedules, now); err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if !schedule.IsDigest || isQuietTime(schedule.Timezone, schedule.QuietHours, now) {
+			continue
+		}
+
+		heldTasks, err := s.repo.Digest.GetHeldQueue(schedule.TgId)
+		if err != nil {
+			return err
+		}
+
+		if len(heldTasks) < 2 {
+			continue
+		}
+
+		if err := s.repo.Digest.CreateQuietDigest(schedule.TgId, getQuietDigest(schedule.Language, heldTasks),
+			heldTasks[len(heldTasks)-1].Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dispatchUserBatch does what a freshly ingested batch needs once: matches
+// are recorded, tasks of users who receive digests are queued and tasks of
+// users who are in their quiet hours are held.
+func dispatchUserBatch(repo *repository.Repository, signingKey string, now time.Time) error {
+	userTasks, err := repo.Task.GetAllForUsers()
+	if err != nil {
+		return err
+	}
+
+	renderer, err := newBatchRenderer(repo, signingKey, false)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := renderer.RenderUserTasks(userTasks)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Stats.AddUserMatches(userMatches(userTasks, tasks)); err != nil {
+		return err
+	}
+
+	if err := queueUserDigests(repo, signingKey); err != nil {
+		return err
+	}
+
+	schedules, err := repo.User.GetSchedules()
+	if err != nil {
+		return err
+	}
+
+	return holdQuietTasks(repo, signingKey, schedules, now)
+}
+
+// queueUserDigests moves the tasks of the latest batch that match users
+// who receive digests into their digest queues.
+func queueUserDigests(repo *repository.Repository, signingKey string) error {
+	digestUsers, err := repo.Digest.GetDigestUsers()
+	if err != nil || len(digestUsers) == 0 {
+		return err
+	}
+
+	userTasks, err := repo.Task.GetAllForUsers()
+	if err != nil {
+		return err
+	}
+
+	_, userTasks = splitDigestTasks(userTasks, digestUsers)
+
+	renderer, err := newBatchRenderer(repo, signingKey, false)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := renderer.RenderUserTasks(userTasks)
+	if err != nil {
+		return err
+	}
+
+	queuedTasks := make(map[int][]core.UserTaskResponse)
+	for _, task := range tasks {
+		queuedTasks[task.TgId] = append(queuedTasks[task.TgId], task)
+	}
+
+	for _, tgId := range digestUsers {
+		if len(queuedTasks[tgId]) == 0 {
+			continue
+		}
+
+		if err := repo.Digest.QueueUserTasks(tgId, queuedTasks[tgId]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// holdQuietTasks holds the undelivered tasks of the latest batch for users
+// who are in their quiet hours, so that they are kept when the next batch
+// arrives before the quiet hours end.
+func holdQuietTasks(repo *repository.Repository, signingKey string, schedules []core.UserScheduleResponse,
+	now time.Time) error {
+	quietUsers := make(map[int]bool)
+	for _, schedule := range schedules {
+		if isQuietTime(schedule.Timezone, schedule.QuietHours, now) {
+			quietUsers[schedule.TgId] = true
+		}
+	}
+
+	if len(quietUsers) == 0 {
+		return nil
+	}
+
+	userTasks, err := repo.Task.GetAllForUsers()
+	if err != nil {
+		return err
+	}
+
+	digestUsers, err := repo.Digest.GetDigestUsers()
+	if err != nil {
+		return err
+	}
+
+	userTasks, _ = splitDigestTasks(userTasks, digestUsers)
+
+	var quietTasks []core.UserTask
+	for _, task := range userTasks {
+		if quietUsers[task.TgId] {
+			quietTasks = append(quietTasks, task)
+		}
+	}
+
+	if len(quietTasks) == 0 {
+		return nil
+	}
+
+	renderer, err := newBatchRenderer(repo, signingKey, false)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := renderer.RenderUserTasks(quietTasks)
+	if err != nil {
+		return err
+	}
+
+	heldTasks := make(map[int][]core.UserTaskResponse)
+	for _, task := range tasks {
+		heldTasks[task.TgId] = append(heldTasks[task.TgId], task)
+	}
+
+	for _, schedule := range schedules {
+		if len(heldTasks[schedule.TgId]) == 0 {
+			continue
+		}
+
+		if err := repo.Task.HoldUserTasks(schedule.TgId, heldTasks[schedule.TgId]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitDigestTasks separates the tasks delivered right away from the
+// tasks of users who receive digests.
+func splitDigestTasks(tasks []core.UserTask, digestUsers []int) (instant, queued []core.UserTask) {
+	isDigestUser := make(map[int]bool, len(digestUsers))
+	for _, tgId := range digestUsers {
+		isDigestUser[tgId] = true
+	}
+
+	for _, task := range tasks {
+		if isDigestUser[task.TgId] {
+			queued = append(queued, task)
+			continue
+		}
+		instant = append(instant, task)
+	}
+
+	return instant, queued
+}
+
+// applyQuietHours leaves out the tasks of users who are in their quiet
+// hours and returns the tasks held for the other users. Several tasks held
+// for a user who gets them as a quiet digest are left to ApplyQuietHours.
+func applyQuietHours(tasks []core.UserTask, heldTasks []core.UserTaskResponse,
+	schedules []core.UserScheduleResponse, now time.Time) ([]core.UserTask, []core.UserTaskResponse) {
+	quietUsers := make(map[int]bool)
+	quietDigestUsers := make(map[int]bool)
+	for _, schedule := range schedules {
+		if isQuietTime(schedule.Timezone, schedule.QuietHours, now) {
+			quietUsers[schedule.TgId] = true
+		}
+		if schedule.IsDigest {
+			quietDigestUsers[schedule.TgId] = true
+		}
+	}
+
+	heldCounts := make(map[int]int)
+	heldIds := make(map[int]map[int]bool)
+	for _, task := range heldTasks {
+		heldCounts[task.TgId]++
+		if heldIds[task.TgId] == nil {
+			heldIds[task.TgId] = make(map[int]bool)
+		}
+		heldIds[task.TgId][task.TaskId] = true
+	}
+
+	var releasedTasks []core.UserTaskResponse
+	for _, task := range heldTasks {
+		if quietUsers[task.TgId] || quietDigestUsers[task.TgId] && heldCounts[task.TgId] > 1 {
+			continue
+		}
+		releasedTasks = append(releasedTasks, task)
+	}
+
+	var result []core.UserTask
+	for _, task := range tasks {
+		if quietUsers[task.TgId] || heldIds[task.TgId][task.Id] {
+			continue
+		}
+		result = append(result, task)
+	}
+
+	return result, releasedTasks
+}
+
+func getQuietDigest(language string, tasks []core.QueuedTaskResponse) core.DigestInput {
+	entries := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		entries = append(entries, fmt.Sprintf("%s\n%s", task.Title, task.Url))
+	}
+
+	return core.DigestInput{
+		Title: fmt.Sprintf(render.T(language, "quiet_title"), len(tasks)),
+		Body:  strings.Join(entries, "\n\n"),
+		Count: len(tasks),
+	}
+}
+
+func NewUserService(repo *repository.Repository, signingKey, erasureKey string) *UserService {
+	return &UserService{repo: repo, signingKey: signingKey, erasureKey: erasureKey}
 }
 
 func (s *UserService) GetByTgId(tgId int) (core.UserResponse, error) {
-	return s.repo.User.GetByTgId(tgId)
+	user, err := s.repo.User.GetByTgId(tgId)
+	return user, userError(err)
 }
 
 func (s *UserService) Create(userInput core.UserInput) (int, error) {
-	return s.repo.User.Create(userInput)
+	if err := normalizeUserInput(&userInput); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.User.Create(userInput)
+	return id, userError(err)
 }
 
 func (s *UserService) Update(userInput core.UserInput) (int, error) {
-	return s.repo.User.Update(userInput)
+	if err := normalizeUserInput(&userInput); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.User.Update(userInput)
+	return id, userError(err)
+}
+
+// Upsert creates the user or updates an existing one, so a bot does not
+// need to know whether it has seen the user before.
+func (s *UserService) Upsert(userInput core.UserInput) (core.UpsertResponse, error) {
+	if err := normalizeUserInput(&userInput); err != nil {
+		return core.UpsertResponse{}, err
+	}
+
+	id, created, err := s.repo.User.Upsert(userInput)
+	if err != nil {
+		return core.UpsertResponse{}, userError(err)
+	}
+
+	return core.UpsertResponse{Id: id, Created: created}, nil
+}
+
+// Patch changes the fields present in input and keeps the others. With
+// input.Version set it fails with core.ErrConflict if the user was changed
+// since, without it the patch is applied to the latest state.
+func (s *UserService) Patch(tgId int, input core.UserPatchInput) (core.UserResponse, error) {
+	var user core.UserResponse
+
+	err := retryPatch(input.Version, func() error {
+		var err error
+		user, err = s.patch(tgId, input)
+		return err
+	})
+
+	return user, err
+}
+
+func (s *UserService) patch(tgId int, input core.UserPatchInput) (core.UserResponse, error) {
+	user, err := s.GetByTgId(tgId)
+	if err != nil {
+		return core.UserResponse{}, err
+	}
+
+	userInput := core.UserInput{
+		TgId:       tgId,
+		Username:   user.Username,
+		Timezone:   user.Timezone,
+		Language:   user.Language,
+		QuietHours: user.QuietHours,
+		Setting:    settingInput(user.Setting),
+		Version:    user.Version,
+	}
+
+	if input.Version != 0 {
+		userInput.Version = input.Version
+	}
+	if input.Username != nil {
+		userInput.Username = *input.Username
+	}
+	if input.Timezone != nil {
+		userInput.Timezone = *input.Timezone
+	}
+	if input.Language != nil {
+		userInput.Language = *input.Language
+	}
+	if input.QuietHours != nil {
+		userInput.QuietHours = input.QuietHours
+	}
+	if input.ClearQuietHours {
+		userInput.QuietHours = nil
+	}
+	if input.Setting != nil {
+		patchSetting(&userInput.Setting, *input.Setting)
+	}
+
+	if _, err := s.Update(userInput); err != nil {
+		return core.UserResponse{}, err
+	}
+
+	return s.GetByTgId(tgId)
+}
+
+// userError translates repository errors into the errors of the service.
+func userError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, core.ErrAlreadyExists):
+		return ErrUserExists
+	case errors.Is(err, core.ErrInvalidReference):
+		return invalidInputf("unknown category or template")
+	}
+
+	return err
+}
+
+func normalizeUserInput(userInput *core.UserInput) error {
+	if userInput.Timezone == "" {
+		userInput.Timezone = defaultTimezone
+	}
+
+	if userInput.Language == "" {
+		userInput.Language = render.DefaultLanguage
+	}
+
+	if err := normalizeDelivery(&userInput.Setting); err != nil {
+		return err
+	}
+
+	return validateSchedule(userInput.Timezone, userInput.QuietHours)
+}
+
+func (s *UserService) GetAll(input core.UserListInput) (core.UserListResponse, error) {
+	page, perPage, offset := pageBounds(input.PageInput)
+
+	users, total, err := s.repo.User.GetAll(input.Status, perPage, offset)
+	if err != nil {
+		return core.UserListResponse{}, err
+	}
+
+	return core.UserListResponse{Users: users, Total: total, Page: page, PerPage: perPage}, nil
+}
+
+// Delete marks the user as deleted, which stops their deliveries. Their
+// data is kept until it is erased.
+func (s *UserService) Delete(tgId int) error {
+	return userError(s.repo.User.SoftDelete(tgId))
+}
+
+// Deactivate stops deliveries to the user while keeping their settings.
+func (s *UserService) Deactivate(tgId int) error {
+	return userError(s.repo.User.SetStatus(tgId, core.StatusInactive))
+}
+
+func (s *UserService) Reactivate(tgId int) error {
+	return userError(s.repo.User.SetStatus(tgId, core.StatusActive))
+}
+
+// Erase removes everything stored about the user. The audit record keeps
+// only an HMAC of the tg_id under the erasure key, which shows that the
+// request was fulfilled but can not be reversed by trying tg_ids.
+func (s *UserService) Erase(tgId int) error {
+	if s.erasureKey == "" {
+		return ErrNoErasureKey
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.erasureKey))
+	mac.Write([]byte(strconv.Itoa(tgId)))
+
+	return userError(s.repo.User.Erase(tgId, hex.EncodeToString(mac.Sum(nil))))
+}
+
+// Export collects everything stored about the user.
+func (s *UserService) Export(tgId int) (core.UserExportResponse, error) {
+	profile, err := s.repo.User.GetByTgId(tgId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.UserExportResponse{}, ErrUserNotFound
+	}
+	if err != nil {
+		return core.UserExportResponse{}, err
+	}
+
+	subscriptions, err := s.repo.User.GetSubscriptions(tgId)
+	if err != nil {
+		return core.UserExportResponse{}, err
+	}
+
+	deliveries, err := s.repo.User.GetDeliveries(tgId)
+	if err != nil {
+		return core.UserExportResponse{}, err
+	}
+
+	savedTasks, err := s.repo.Feedback.GetSavedTasks(tgId)
+	if err != nil {
+		return core.UserExportResponse{}, err
+	}
+
+	activity, err := s.repo.User.GetActivity(tgId)
+	if err != nil {
+		return core.UserExportResponse{}, err
+	}
+
+	return core.UserExportResponse{
+		Profile:       profile,
+		Subscriptions: subscriptions,
+		Deliveries:    deliveries,
+		SavedTasks:    savedTasks,
+		Activity:      activity,
+		ExportedAt:    time.Now().UTC(),
+	}, nil
+}
+
+// SetStatus applies a status reported by the bot. A user deactivated by an
+// admin stays inactive until they are reactivated.
+func (s *UserService) SetStatus(input core.UserStatusInput) error {
+	return userError(s.repo.User.SetStatus(input.TgId, input.Status, core.StatusInactive))
+}
+
+func (s *UserService) ReportDelivery(input core.UserDeliveryInput) (string, error) {
+	status, err := s.repo.User.ReportDelivery(input.TgId, input.DeliveryInput, viper.GetInt("recipients.max_failures"))
+	return status, userError(err)
 }
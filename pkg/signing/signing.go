@@ -0,0 +1,123 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers of a signed request.
+const (
+	HeaderKeyId     = "X-Key-Id"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+const nonceSize = 16
+
+// Sign returns the hex HMAC-SHA256 of a request. The signed string is the
+// method, the path with its query, the unix timestamp, the nonce and the
+// hex SHA-256 of the body, separated by newlines.
+func Sign(secret, method, path, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches the request.
+func Verify(secret, signature, method, path, timestamp, nonce string, body []byte) bool {
+	expected := Sign(secret, method, path, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// SignRequest sets the signature headers of an outgoing request. The body
+// is read and put back, so the request can still be sent.
+func SignRequest(req *http.Request, keyId, secret string, now time.Time) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set(HeaderKeyId, keyId)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(secret, req.Method, req.URL.RequestURI(), timestamp, nonce, body))
+
+	return nil
+}
+
+func newNonce() (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
+
+// NonceCache remembers nonces of accepted requests until their timestamp
+// falls out of the allowed window, so a captured request can not be
+// replayed.
+type NonceCache struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	nextPrune time.Time
+}
+
+func NewNonceCache() *NonceCache {
+	return &NonceCache{seen: make(map[string]time.Time)}
+}
+
+// Add records nonce until expiresAt. It returns false if the nonce is
+// already recorded.
+func (c *NonceCache) Add(nonce string, expiresAt, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.After(c.nextPrune) {
+		for n, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, n)
+			}
+		}
+		c.nextPrune = now.Add(time.Minute)
+	}
+
+	if exp, ok := c.seen[nonce]; ok && !now.After(exp) {
+		return false
+	}
+
+	c.seen[nonce] = expiresAt
+	return true
+}
@@ -0,0 +1,61 @@
+package signing
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignRequest(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"datetime":"2021-10-01 11:00:00"}`)
+
+	req := httptest.NewRequest("POST", "http://parser:8001/api/parse/data?source=fl", bytes.NewReader(body))
+	assert.NoError(t, SignRequest(req, "core", "secret", now))
+
+	sent, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, sent)
+
+	assert.Equal(t, "core", req.Header.Get(HeaderKeyId))
+	assert.Equal(t, "1633089600", req.Header.Get(HeaderTimestamp))
+
+	signature := req.Header.Get(HeaderSignature)
+	nonce := req.Header.Get(HeaderNonce)
+
+	testTable := []struct {
+		name   string
+		secret string
+		method string
+		path   string
+		body   []byte
+		want   bool
+	}{
+		{name: "OK", secret: "secret", method: "POST", path: "/api/parse/data?source=fl", body: body, want: true},
+		{name: "Wrong Secret", secret: "other", method: "POST", path: "/api/parse/data?source=fl", body: body},
+		{name: "Other Method", secret: "secret", method: "GET", path: "/api/parse/data?source=fl", body: body},
+		{name: "Other Path", secret: "secret", method: "POST", path: "/api/parse/data", body: body},
+		{name: "Other Body", secret: "secret", method: "POST", path: "/api/parse/data?source=fl", body: []byte(`{}`)},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := Verify(testCase.secret, signature, testCase.method, testCase.path, "1633089600", nonce, testCase.body)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestNonceCache_Add(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewNonceCache()
+
+	assert.True(t, cache.Add("1:a", now.Add(5*time.Minute), now))
+	assert.False(t, cache.Add("1:a", now.Add(5*time.Minute), now.Add(time.Minute)))
+	assert.True(t, cache.Add("2:a", now.Add(5*time.Minute), now))
+	assert.True(t, cache.Add("1:a", now.Add(20*time.Minute), now.Add(10*time.Minute)))
+}
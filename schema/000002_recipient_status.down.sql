@@ -0,0 +1,9 @@
+DROP TABLE deliveries;
+
+ALTER TABLE channels
+    DROP COLUMN failures,
+    DROP COLUMN status;
+
+ALTER TABLE users
+    DROP COLUMN failures,
+    DROP COLUMN status;
@@ -0,0 +1,20 @@
+ALTER TABLE users
+    ADD COLUMN status   varchar(16) not null default 'active',
+    ADD COLUMN failures integer     not null default 0;
+
+ALTER TABLE channels
+    ADD COLUMN status   varchar(16) not null default 'active',
+    ADD COLUMN failures integer     not null default 0;
+
+CREATE TABLE deliveries
+(
+    id           serial                                             not null unique,
+    user_id      integer references users (id) on delete cascade,
+    channel_id   integer references channels (id) on delete cascade,
+    task_url     varchar(2048)                                      not null,
+    is_delivered boolean                                            not null,
+    is_permanent boolean                                            not null default false,
+    reason       varchar(1024)                                      not null default '',
+    created_at   timestamp with time zone                           not null default now(),
+    check ((user_id is null) != (channel_id is null))
+);
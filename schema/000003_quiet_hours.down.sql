@@ -0,0 +1,7 @@
+DROP TABLE held_tasks;
+
+ALTER TABLE user_settings
+    DROP COLUMN is_quiet_digest,
+    DROP COLUMN quiet_to,
+    DROP COLUMN quiet_from,
+    DROP COLUMN timezone;
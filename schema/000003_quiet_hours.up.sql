@@ -0,0 +1,16 @@
+ALTER TABLE user_settings
+    ADD COLUMN timezone        varchar(64) not null default 'UTC',
+    ADD COLUMN quiet_from      varchar(5)  not null default '',
+    ADD COLUMN quiet_to        varchar(5)  not null default '',
+    ADD COLUMN is_quiet_digest boolean     not null default false;
+
+CREATE TABLE held_tasks
+(
+    id       serial                                          not null unique,
+    user_id  integer references users (id) on delete cascade not null,
+    task_url varchar(2048)                                   not null,
+    title    varchar(256)                                    not null,
+    body     text                                            not null,
+    held_at  timestamp with time zone                        not null default now(),
+    unique (user_id, task_url)
+);
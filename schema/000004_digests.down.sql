@@ -0,0 +1,12 @@
+DROP TABLE digests;
+
+DROP TABLE digest_tasks;
+
+ALTER TABLE channel_settings
+    DROP COLUMN digest_time,
+    DROP COLUMN delivery_mode,
+    DROP COLUMN timezone;
+
+ALTER TABLE user_settings
+    DROP COLUMN digest_time,
+    DROP COLUMN delivery_mode;
@@ -0,0 +1,35 @@
+ALTER TABLE user_settings
+    ADD COLUMN delivery_mode varchar(16) not null default 'instant',
+    ADD COLUMN digest_time   varchar(5)  not null default '';
+
+ALTER TABLE channel_settings
+    ADD COLUMN timezone      varchar(64) not null default 'UTC',
+    ADD COLUMN delivery_mode varchar(16) not null default 'instant',
+    ADD COLUMN digest_time   varchar(5)  not null default '';
+
+CREATE TABLE digest_tasks
+(
+    id         serial                                             not null unique,
+    user_id    integer references users (id) on delete cascade,
+    channel_id integer references channels (id) on delete cascade,
+    task_url   varchar(2048)                                      not null,
+    title      varchar(256)                                       not null,
+    body       text                                               not null,
+    queued_at  timestamp with time zone                           not null default now(),
+    unique (user_id, task_url),
+    unique (channel_id, task_url),
+    check ((user_id is null) != (channel_id is null))
+);
+
+CREATE TABLE digests
+(
+    id          serial                                             not null unique,
+    user_id     integer references users (id) on delete cascade,
+    channel_id  integer references channels (id) on delete cascade,
+    title       varchar(256)                                       not null,
+    body        text                                               not null,
+    tasks_count integer                                            not null,
+    created_at  timestamp with time zone                           not null default now(),
+    served_at   timestamp with time zone,
+    check ((user_id is null) != (channel_id is null))
+);
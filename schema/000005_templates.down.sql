@@ -0,0 +1,19 @@
+DELETE FROM freelance_tasks;
+
+ALTER TABLE freelance_tasks
+    DROP COLUMN datetime,
+    DROP COLUMN term,
+    DROP COLUMN is_budget_per_hour,
+    DROP COLUMN budget,
+    DROP COLUMN description,
+    DROP COLUMN fl_url,
+    DROP COLUMN fl_name,
+    ADD COLUMN body text not null;
+
+ALTER TABLE channel_settings
+    DROP COLUMN template_id;
+
+ALTER TABLE user_settings
+    DROP COLUMN template_id;
+
+DROP TABLE templates;
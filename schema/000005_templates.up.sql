@@ -0,0 +1,25 @@
+CREATE TABLE templates
+(
+    id         serial                   not null unique,
+    name       varchar(256)             not null unique,
+    body       text                     not null,
+    created_at timestamp with time zone not null default now()
+);
+
+ALTER TABLE user_settings
+    ADD COLUMN template_id integer references templates (id) on delete set null;
+
+ALTER TABLE channel_settings
+    ADD COLUMN template_id integer references templates (id) on delete set null;
+
+DELETE FROM freelance_tasks;
+
+ALTER TABLE freelance_tasks
+    DROP COLUMN body,
+    ADD COLUMN fl_name            varchar(256)  not null,
+    ADD COLUMN fl_url             varchar(2048) not null,
+    ADD COLUMN description        text          not null,
+    ADD COLUMN budget             integer       not null default 0,
+    ADD COLUMN is_budget_per_hour boolean       not null default false,
+    ADD COLUMN term               varchar(256)  not null default '',
+    ADD COLUMN datetime           varchar(64)   not null;
@@ -0,0 +1,5 @@
+ALTER TABLE channel_settings
+    DROP COLUMN language;
+
+ALTER TABLE user_settings
+    DROP COLUMN language;
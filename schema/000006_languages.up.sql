@@ -0,0 +1,5 @@
+ALTER TABLE user_settings
+    ADD COLUMN language varchar(8) not null default 'ru';
+
+ALTER TABLE channel_settings
+    ADD COLUMN language varchar(8) not null default 'ru';
@@ -0,0 +1,3 @@
+DROP TABLE saved_tasks;
+
+DROP TABLE hidden_tasks;
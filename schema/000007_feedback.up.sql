@@ -0,0 +1,18 @@
+CREATE TABLE hidden_tasks
+(
+    id        serial                                          not null unique,
+    user_id   integer references users (id) on delete cascade not null,
+    task_url  varchar(2048)                                   not null,
+    hidden_at timestamp with time zone                        not null default now(),
+    unique (user_id, task_url)
+);
+
+CREATE TABLE saved_tasks
+(
+    id       serial                                          not null unique,
+    user_id  integer references users (id) on delete cascade not null,
+    task_url varchar(2048)                                   not null,
+    title    varchar(256)                                    not null,
+    saved_at timestamp with time zone                        not null default now(),
+    unique (user_id, task_url)
+);
@@ -0,0 +1,3 @@
+DROP TABLE user_excluded_keywords;
+
+DROP TABLE not_interested_tasks;
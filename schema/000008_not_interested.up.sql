@@ -0,0 +1,19 @@
+CREATE TABLE not_interested_tasks
+(
+    id          serial                                               not null unique,
+    user_id     integer references users (id) on delete cascade      not null,
+    task_url    varchar(2048)                                        not null,
+    title       varchar(256)                                         not null,
+    category_id integer references categories (id) on delete cascade not null,
+    reason      text                                                 not null default '',
+    created_at  timestamp with time zone                             not null default now(),
+    unique (user_id, task_url)
+);
+
+CREATE TABLE user_excluded_keywords
+(
+    id              serial                                                  not null unique,
+    user_setting_id integer references user_settings (id) on delete cascade not null,
+    keyword         varchar(256)                                            not null,
+    unique (user_setting_id, keyword)
+);
@@ -0,0 +1,3 @@
+DROP TABLE clicks;
+
+DROP TABLE tracked_links;
@@ -0,0 +1,22 @@
+CREATE TABLE tracked_links
+(
+    id          serial                                               not null unique,
+    user_id     integer references users (id) on delete cascade,
+    channel_id  integer references channels (id) on delete cascade,
+    task_url    varchar(2048)                                        not null,
+    fl_name     varchar(256)                                         not null,
+    category_id integer references categories (id) on delete cascade not null,
+    created_at  timestamp with time zone                             not null default now(),
+    unique (user_id, task_url),
+    unique (channel_id, task_url),
+    check ((user_id is null) != (channel_id is null))
+);
+
+CREATE TABLE clicks
+(
+    id         serial                                                  not null unique,
+    link_id    integer references tracked_links (id) on delete cascade not null,
+    clicked_at timestamp with time zone                                not null default now()
+);
+
+CREATE INDEX tracked_links_created_at_idx ON tracked_links (created_at);
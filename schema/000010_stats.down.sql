@@ -0,0 +1,3 @@
+DROP INDEX deliveries_created_at_idx;
+
+DROP TABLE matches;
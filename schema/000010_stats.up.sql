@@ -0,0 +1,18 @@
+CREATE TABLE matches
+(
+    id           serial                                               not null unique,
+    user_id      integer references users (id) on delete cascade,
+    channel_id   integer references channels (id) on delete cascade,
+    task_url     varchar(2048)                                        not null,
+    fl_name      varchar(256)                                         not null,
+    category_id  integer references categories (id) on delete cascade not null,
+    published_at timestamp with time zone,
+    matched_at   timestamp with time zone                             not null default now(),
+    unique (user_id, task_url),
+    unique (channel_id, task_url),
+    check ((user_id is null) != (channel_id is null))
+);
+
+CREATE INDEX matches_matched_at_idx ON matches (matched_at);
+
+CREATE INDEX deliveries_created_at_idx ON deliveries (created_at);
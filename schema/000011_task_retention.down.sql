@@ -0,0 +1,9 @@
+DROP TABLE freelance_tasks_archive;
+
+DROP INDEX freelance_tasks_ingested_at_idx;
+
+ALTER TABLE freelance_tasks
+    DROP CONSTRAINT freelance_tasks_task_url_key;
+
+ALTER TABLE freelance_tasks
+    DROP COLUMN ingested_at;
@@ -0,0 +1,30 @@
+ALTER TABLE freelance_tasks
+    ADD COLUMN ingested_at timestamp with time zone not null default now();
+
+DELETE FROM freelance_tasks a USING freelance_tasks b
+WHERE a.task_url = b.task_url AND a.id < b.id;
+
+ALTER TABLE freelance_tasks
+    ADD CONSTRAINT freelance_tasks_task_url_key UNIQUE (task_url);
+
+CREATE INDEX freelance_tasks_ingested_at_idx ON freelance_tasks (ingested_at);
+
+CREATE TABLE freelance_tasks_archive
+(
+    id                 integer                                              not null,
+    task_url           varchar(2048)                                        not null,
+    title              varchar(256)                                         not null,
+    category_id        integer references categories (id) on delete cascade not null,
+    is_budget          boolean                                              not null,
+    is_term            boolean                                              not null,
+    is_safe_deal       boolean                                              not null,
+    fl_name            varchar(256)                                         not null,
+    fl_url             varchar(2048)                                        not null,
+    description        text                                                 not null,
+    budget             integer                                              not null,
+    is_budget_per_hour boolean                                              not null,
+    term               varchar(256)                                         not null,
+    datetime           varchar(64)                                          not null,
+    ingested_at        timestamp with time zone                             not null,
+    archived_at        timestamp with time zone                             not null default now()
+) PARTITION BY RANGE (ingested_at);
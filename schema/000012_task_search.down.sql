@@ -0,0 +1,9 @@
+DROP INDEX freelance_tasks_archive_search_vector_idx;
+
+ALTER TABLE freelance_tasks_archive
+    DROP COLUMN search_vector;
+
+DROP INDEX freelance_tasks_search_vector_idx;
+
+ALTER TABLE freelance_tasks
+    DROP COLUMN search_vector;
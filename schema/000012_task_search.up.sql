@@ -0,0 +1,9 @@
+ALTER TABLE freelance_tasks
+    ADD COLUMN search_vector tsvector generated always as (to_tsvector('russian', title || ' ' || description)) stored;
+
+CREATE INDEX freelance_tasks_search_vector_idx ON freelance_tasks USING gin (search_vector);
+
+ALTER TABLE freelance_tasks_archive
+    ADD COLUMN search_vector tsvector generated always as (to_tsvector('russian', title || ' ' || description)) stored;
+
+CREATE INDEX freelance_tasks_archive_search_vector_idx ON freelance_tasks_archive USING gin (search_vector);
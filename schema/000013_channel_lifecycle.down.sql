@@ -0,0 +1,4 @@
+DELETE FROM channels WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE channels
+    DROP COLUMN deleted_at;
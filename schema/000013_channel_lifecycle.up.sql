@@ -0,0 +1,2 @@
+ALTER TABLE channels
+    ADD COLUMN deleted_at timestamp with time zone;
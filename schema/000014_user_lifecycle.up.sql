@@ -0,0 +1,8 @@
+CREATE TABLE erasures
+(
+    id           serial                   not null unique,
+    subject_hash varchar(64)              not null,
+    deliveries   integer                  not null,
+    clicks       integer                  not null,
+    erased_at    timestamp with time zone not null default now()
+);
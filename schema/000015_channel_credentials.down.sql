@@ -0,0 +1,2 @@
+ALTER TABLE channels
+    ALTER COLUMN api_hash TYPE varchar(256);
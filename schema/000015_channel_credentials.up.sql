@@ -0,0 +1,4 @@
+-- api_hash now holds a sealed value. Plaintext rows are sealed with the
+-- current key from CREDENTIALS_KEYS when the service starts.
+ALTER TABLE channels
+    ALTER COLUMN api_hash TYPE text;
@@ -0,0 +1,10 @@
+CREATE TABLE api_keys
+(
+    id           serial                   not null unique,
+    name         varchar(255)             not null,
+    key_hash     varchar(64)              not null unique,
+    scopes       varchar(255)             not null,
+    created_at   timestamp with time zone not null default now(),
+    last_used_at timestamp with time zone,
+    revoked_at   timestamp with time zone
+);
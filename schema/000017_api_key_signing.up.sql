@@ -0,0 +1,4 @@
+-- Sealed with the current key from CREDENTIALS_KEYS, empty for keys
+-- without request signing.
+ALTER TABLE api_keys
+    ADD COLUMN signing_secret text not null default '';
@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    DROP COLUMN version;
+
+ALTER TABLE users
+    DROP COLUMN version;
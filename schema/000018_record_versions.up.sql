@@ -0,0 +1,7 @@
+-- Incremented by every update of the record or its settings, used for
+-- optimistic concurrency.
+ALTER TABLE users
+    ADD COLUMN version integer not null default 1;
+
+ALTER TABLE channels
+    ADD COLUMN version integer not null default 1;
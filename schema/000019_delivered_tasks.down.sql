@@ -0,0 +1,6 @@
+DROP INDEX deliveries_channel_task_idx;
+
+DROP INDEX deliveries_user_task_idx;
+
+ALTER TABLE deliveries
+    DROP COLUMN task_id;
@@ -0,0 +1,9 @@
+-- Set by delivery reports of single tasks so that feeds skip tasks that
+-- already reached the recipient. Archived tasks keep their ids, so there
+-- is no reference to freelance_tasks.
+ALTER TABLE deliveries
+    ADD COLUMN task_id integer;
+
+CREATE INDEX deliveries_user_task_idx ON deliveries (user_id, task_id) WHERE is_delivered;
+
+CREATE INDEX deliveries_channel_task_idx ON deliveries (channel_id, task_id) WHERE is_delivered;
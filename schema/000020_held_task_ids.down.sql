@@ -0,0 +1,2 @@
+ALTER TABLE held_tasks
+    DROP COLUMN task_id;
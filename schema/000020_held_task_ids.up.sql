@@ -0,0 +1,3 @@
+-- Lets delivery reports release held tasks by the task_id of the feed.
+ALTER TABLE held_tasks
+    ADD COLUMN task_id integer;
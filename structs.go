@@ -1,25 +1,82 @@
 package core
 
+import "time"
+
+// Recipient statuses
+
+const (
+	StatusActive   = "active"
+	StatusBlocked  = "blocked"
+	StatusPaused   = "paused"
+	StatusDeleted  = "deleted"
+	StatusInactive = "inactive"
+)
+
+// Delivery modes
+
+const (
+	DeliveryInstant = "instant"
+	DeliveryHourly  = "hourly"
+	DeliveryDaily   = "daily"
+)
+
+// Exclusion rule types
+
+const (
+	RuleCategory = "category"
+	RuleKeyword  = "keyword"
+)
+
+// API key scopes. Admin grants access to every route.
+
+const (
+	ScopeParserIngest = "parser:ingest"
+	ScopeBotRead      = "bot:read"
+	ScopeBotWrite     = "bot:write"
+	ScopeCredentials  = "channels:credentials"
+	ScopeAdmin        = "admin"
+)
+
 // Input structs
 
 type SettingInput struct {
-	IsSafeDeal *bool `json:"is_safe_deal" binding:"required"`
-	IsBudget   *bool `json:"is_budget" binding:"required"`
-	IsTerm     *bool `json:"is_term" binding:"required"`
-	Categories []int `json:"categories" binding:"required"`
+	IsSafeDeal   *bool  `json:"is_safe_deal" binding:"required"`
+	IsBudget     *bool  `json:"is_budget" binding:"required"`
+	IsTerm       *bool  `json:"is_term" binding:"required"`
+	Categories   []int  `json:"categories" binding:"required"`
+	DeliveryMode string `json:"delivery_mode" binding:"omitempty,oneof=instant hourly daily"`
+	DigestTime   string `json:"digest_time"`
+	TemplateId   *int   `json:"template_id"`
 }
 
 type ChannelInput struct {
-	ApiId   int          `json:"api_id" binding:"required"`
-	ApiHash string       `json:"api_hash" binding:"required"`
-	Name    string       `json:"name" binding:"required"`
-	Setting SettingInput `json:"setting" binding:"required"`
+	ApiId    int          `json:"api_id" binding:"required"`
+	ApiHash  string       `json:"api_hash" binding:"required"`
+	Name     string       `json:"name" binding:"required"`
+	Timezone string       `json:"timezone"`
+	Language string       `json:"language" binding:"omitempty,oneof=ru en uk"`
+	Setting  SettingInput `json:"setting" binding:"required"`
+	// Version, when set, makes an update fail with ErrConflict if the
+	// channel was changed since it was read.
+	Version int `json:"version"`
+}
+
+type QuietHours struct {
+	From     string `json:"from" binding:"required" db:"quiet_from"`
+	To       string `json:"to" binding:"required" db:"quiet_to"`
+	IsDigest bool   `json:"is_digest" db:"is_quiet_digest"`
 }
 
 type UserInput struct {
-	TgId     int          `json:"tg_id" binding:"required"`
-	Username string       `json:"username" binding:"required"`
-	Setting  SettingInput `json:"setting" binding:"required"`
+	TgId       int          `json:"tg_id" binding:"required"`
+	Username   string       `json:"username" binding:"required"`
+	Timezone   string       `json:"timezone"`
+	Language   string       `json:"language" binding:"omitempty,oneof=ru en uk"`
+	QuietHours *QuietHours  `json:"quiet_hours"`
+	Setting    SettingInput `json:"setting" binding:"required"`
+	// Version, when set, makes an update fail with ErrConflict if the user
+	// was changed since it was read.
+	Version int `json:"version"`
 }
 
 type ApiIdInput struct {
@@ -30,6 +87,138 @@ type TgIdInput struct {
 	TgId int `json:"tg_id" binding:"required"`
 }
 
+// TgIdUri is the tg_id in v2 resource paths.
+type TgIdUri struct {
+	TgId int `uri:"tg_id" binding:"required"`
+}
+
+// ApiIdUri is the api_id in v2 resource paths.
+type ApiIdUri struct {
+	ApiId int `uri:"api_id" binding:"required"`
+}
+
+// UserBody is a user in v2 requests, where the tg_id comes from the path.
+type UserBody struct {
+	Username   string       `json:"username" binding:"required"`
+	Timezone   string       `json:"timezone"`
+	Language   string       `json:"language" binding:"omitempty,oneof=ru en uk"`
+	QuietHours *QuietHours  `json:"quiet_hours"`
+	Setting    SettingInput `json:"setting" binding:"required"`
+	Version    int          `json:"version"`
+}
+
+func (b UserBody) Input(tgId int) UserInput {
+	return UserInput{
+		TgId:       tgId,
+		Username:   b.Username,
+		Timezone:   b.Timezone,
+		Language:   b.Language,
+		QuietHours: b.QuietHours,
+		Setting:    b.Setting,
+		Version:    b.Version,
+	}
+}
+
+// ChannelBody is a channel in v2 requests, where the api_id comes from the
+// path.
+type ChannelBody struct {
+	ApiHash  string       `json:"api_hash" binding:"required"`
+	Name     string       `json:"name" binding:"required"`
+	Timezone string       `json:"timezone"`
+	Language string       `json:"language" binding:"omitempty,oneof=ru en uk"`
+	Setting  SettingInput `json:"setting" binding:"required"`
+	Version  int          `json:"version"`
+}
+
+func (b ChannelBody) Input(apiId int) ChannelInput {
+	return ChannelInput{
+		ApiId:    apiId,
+		ApiHash:  b.ApiHash,
+		Name:     b.Name,
+		Timezone: b.Timezone,
+		Language: b.Language,
+		Setting:  b.Setting,
+		Version:  b.Version,
+	}
+}
+
+// SettingPatchInput changes only the settings that are present.
+// Categories replaces the whole list, AddCategories and RemoveCategories
+// change single categories and are applied after it. ClearTemplate goes
+// back to the default template.
+type SettingPatchInput struct {
+	IsSafeDeal       *bool   `json:"is_safe_deal"`
+	IsBudget         *bool   `json:"is_budget"`
+	IsTerm           *bool   `json:"is_term"`
+	Categories       *[]int  `json:"categories"`
+	AddCategories    []int   `json:"add_categories"`
+	RemoveCategories []int   `json:"remove_categories"`
+	DeliveryMode     *string `json:"delivery_mode" binding:"omitempty,oneof=instant hourly daily"`
+	DigestTime       *string `json:"digest_time"`
+	TemplateId       *int    `json:"template_id"`
+	ClearTemplate    bool    `json:"clear_template" binding:"excluded_with=TemplateId"`
+}
+
+// UserPatchInput changes only the fields of a user that are present. A
+// non-zero Version makes the patch fail with ErrConflict if the user was
+// changed since that version was read. ClearQuietHours switches quiet
+// hours off.
+type UserPatchInput struct {
+	Username        *string            `json:"username" binding:"omitempty,min=1"`
+	Timezone        *string            `json:"timezone"`
+	Language        *string            `json:"language" binding:"omitempty,oneof=ru en uk"`
+	QuietHours      *QuietHours        `json:"quiet_hours"`
+	ClearQuietHours bool               `json:"clear_quiet_hours" binding:"excluded_with=QuietHours"`
+	Setting         *SettingPatchInput `json:"setting"`
+	Version         int                `json:"version"`
+}
+
+// ChannelPatchInput changes only the fields of a channel that are present.
+// The api_hash is kept, Version works as in UserPatchInput.
+type ChannelPatchInput struct {
+	Name     *string            `json:"name" binding:"omitempty,min=1"`
+	Timezone *string            `json:"timezone"`
+	Language *string            `json:"language" binding:"omitempty,oneof=ru en uk"`
+	Setting  *SettingPatchInput `json:"setting"`
+	Version  int                `json:"version"`
+}
+
+type StatusInput struct {
+	Status string `json:"status" binding:"required,oneof=active blocked paused"`
+}
+
+type UserStatusInput struct {
+	TgId int `json:"tg_id" binding:"required"`
+	StatusInput
+}
+
+type ChannelStatusInput struct {
+	ApiId int `json:"api_id" binding:"required"`
+	StatusInput
+}
+
+type DeliveryInput struct {
+	Url         string `json:"url" binding:"required"`
+	IsDelivered *bool  `json:"is_delivered" binding:"required"`
+	IsPermanent bool   `json:"is_permanent"`
+	Reason      string `json:"reason"`
+	// TaskId is the task_id of the delivered feed item. Tasks reported as
+	// delivered are not returned by the feeds again.
+	TaskId int `json:"task_id"`
+	// DigestId marks the digest as served once it is delivered.
+	DigestId int `json:"digest_id"`
+}
+
+type UserDeliveryInput struct {
+	TgId int `json:"tg_id" binding:"required"`
+	DeliveryInput
+}
+
+type ChannelDeliveryInput struct {
+	ApiId int `json:"api_id" binding:"required"`
+	DeliveryInput
+}
+
 type TaskDataInput struct {
 	FLName          string `json:"fl_name" binding:"required"`
 	FLUrl           string `json:"fl_url" binding:"required"`
@@ -48,49 +237,582 @@ type TasksInput struct {
 	Tasks []TaskDataInput `json:"tasks" binding:"required"`
 }
 
+type FeedInput struct {
+	Format  string `form:"format" binding:"omitempty,oneof=plain markdownv2 html"`
+	Buttons bool   `form:"buttons"`
+}
+
+// PeriodInput selects a range of days, both ends inclusive.
+type PeriodInput struct {
+	From time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+}
+
+type PageInput struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+type ChannelListInput struct {
+	PageInput
+	Status string `form:"status" binding:"omitempty,oneof=active blocked paused deleted"`
+}
+
+type UserExportInput struct {
+	TgId   int    `json:"tg_id" binding:"required"`
+	Format string `json:"format" binding:"omitempty,oneof=json zip"`
+}
+
+type UserListInput struct {
+	PageInput
+	Status string `form:"status" binding:"omitempty,oneof=active blocked paused inactive"`
+}
+
+type TaskSearchInput struct {
+	PeriodInput
+	PageInput
+	Query     string `form:"q"`
+	Category  string `form:"category"`
+	MinBudget int    `form:"min_budget" binding:"omitempty,min=0"`
+	Source    string `form:"source"`
+}
+
+// TaskSearchFilter is a search over tasks ingested in [From, To); zero
+// times leave the range open.
+type TaskSearchFilter struct {
+	Query     string
+	Category  string
+	MinBudget int
+	Source    string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+type StatsInput struct {
+	PeriodInput
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// MatchInput records that a task was served to the user or channel with
+// the key under Url, which differs from the task url for tracked links.
+type MatchInput struct {
+	Key         int
+	Url         string
+	FLName      string
+	CategoryId  int
+	PublishedAt *time.Time
+}
+
+type CallbackInput struct {
+	TgId int    `json:"tg_id" binding:"required"`
+	Data string `json:"data" binding:"required"`
+}
+
+type FeedbackTaskInput struct {
+	TgId   int `json:"tg_id" binding:"required"`
+	TaskId int `json:"task_id" binding:"required"`
+}
+
+type NotInterestedInput struct {
+	FeedbackTaskInput
+	Reason string `json:"reason"`
+}
+
+type ExclusionRuleInput struct {
+	TgId       int    `json:"tg_id" binding:"required"`
+	Type       string `json:"type" binding:"required,oneof=category keyword"`
+	CategoryId int    `json:"category_id"`
+	Keyword    string `json:"keyword"`
+}
+
+type TemplateInput struct {
+	Name string `json:"name" binding:"required"`
+	Body string `json:"body" binding:"required"`
+}
+
+type TemplateUpdateInput struct {
+	Id int `json:"id" binding:"required"`
+	TemplateInput
+}
+
+type TemplateIdInput struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type TemplatePreviewInput struct {
+	Body     string         `json:"body" binding:"required"`
+	Language string         `json:"language" binding:"omitempty,oneof=ru en uk"`
+	Task     *TaskDataInput `json:"task"`
+}
+
+// Task structs
+
+// Task is a freelance task as it was received from the parser. It is the
+// data available to message templates.
+type Task struct {
+	Id              int    `json:"id" db:"id"`
+	FLName          string `json:"fl_name" db:"fl_name"`
+	FLUrl           string `json:"fl_url" db:"fl_url"`
+	Url             string `json:"task_url" db:"task_url"`
+	CategoryId      int    `json:"category_id" db:"category_id"`
+	Category        string `json:"category" db:"category"`
+	Title           string `json:"title" db:"title"`
+	Description     string `json:"description" db:"description"`
+	Budget          int    `json:"budget" db:"budget"`
+	IsBudgetPerHour bool   `json:"is_budget_per_hour" db:"is_budget_per_hour"`
+	Term            string `json:"term" db:"term"`
+	IsSafeDeal      bool   `json:"is_safe_deal" db:"is_safe_deal"`
+	DateTime        string `json:"datetime" db:"datetime"`
+}
+
+type UserTask struct {
+	TgId       int    `db:"tg_id"`
+	TemplateId *int   `db:"template_id"`
+	Language   string `db:"language"`
+	Task
+}
+
+type ChannelTask struct {
+	ApiId      int    `db:"api_id"`
+	TemplateId *int   `db:"template_id"`
+	Language   string `db:"language"`
+	Task
+}
+
+type ApiKeyInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=parser:ingest bot:read bot:write channels:credentials admin"`
+	// Signing issues a shared secret for HMAC request signing along with
+	// the key.
+	Signing bool `json:"signing"`
+}
+
+// SignedRequestInput is a request signed with the shared secret of an API
+// key instead of carrying the key itself.
+type SignedRequestInput struct {
+	KeyId     int
+	Method    string
+	Path      string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+type ApiKeyIdInput struct {
+	Id int `json:"id" binding:"required"`
+}
+
 // Response structs
 
 type SettingResponse struct {
-	IsSafeDeal bool  `json:"is_safe_deal" db:"is_safe_deal"`
-	IsBudget   bool  `json:"is_budget" db:"is_budget"`
-	IsTerm     bool  `json:"is_term" db:"is_term"`
-	Categories []int `json:"categories" db:"categories"`
+	IsSafeDeal   bool   `json:"is_safe_deal" db:"is_safe_deal"`
+	IsBudget     bool   `json:"is_budget" db:"is_budget"`
+	IsTerm       bool   `json:"is_term" db:"is_term"`
+	Categories   []int  `json:"categories" db:"categories"`
+	DeliveryMode string `json:"delivery_mode" db:"delivery_mode"`
+	DigestTime   string `json:"digest_time,omitempty" db:"digest_time"`
+	TemplateId   *int   `json:"template_id,omitempty" db:"template_id"`
+	// ExcludedKeywords are only set for users.
+	ExcludedKeywords []string `json:"excluded_keywords,omitempty" db:"-"`
 }
 
-type ChannelResponse struct {
-	Id      int             `json:"id" db:"id"`
-	ApiId   int             `json:"api_id" db:"api_id"`
-	ApiHash string          `json:"api_hash" db:"api_hash"`
-	Name    string          `json:"name" db:"name"`
-	Setting SettingResponse `json:"setting"`
+// UpsertResponse tells whether an upsert created the record or updated an
+// existing one.
+type UpsertResponse struct {
+	Id      int  `json:"id"`
+	Created bool `json:"created"`
 }
 
-type UserResponse struct {
+type ChannelResponse struct {
 	Id       int             `json:"id" db:"id"`
-	TgId     int             `json:"tg_id" db:"tg_id"`
-	Username string          `json:"username" db:"username"`
+	ApiId    int             `json:"api_id" db:"api_id"`
+	Name     string          `json:"name" db:"name"`
+	Status   string          `json:"status" db:"status"`
+	Timezone string          `json:"timezone,omitempty"`
+	Language string          `json:"language,omitempty"`
 	Setting  SettingResponse `json:"setting"`
+	Version  int             `json:"version,omitempty" db:"version"`
+}
+
+type UserResponse struct {
+	Id         int             `json:"id" db:"id"`
+	TgId       int             `json:"tg_id" db:"tg_id"`
+	Username   string          `json:"username" db:"username"`
+	Status     string          `json:"status" db:"status"`
+	Timezone   string          `json:"timezone,omitempty"`
+	Language   string          `json:"language,omitempty"`
+	QuietHours *QuietHours     `json:"quiet_hours,omitempty"`
+	Setting    SettingResponse `json:"setting"`
+	Version    int             `json:"version,omitempty" db:"version"`
+}
+
+type UserScheduleResponse struct {
+	TgId     int    `db:"tg_id"`
+	Timezone string `db:"timezone"`
+	Language string `db:"language"`
+	QuietHours
+}
+
+// FormattedMessage is the text of a task or digest prepared for sending:
+// escaped for the requested parse mode and split to fit Telegram limits.
+// It is only filled when a feed is requested with a format.
+type FormattedMessage struct {
+	ParseMode string   `json:"parse_mode,omitempty" db:"-"`
+	Messages  []string `json:"messages,omitempty" db:"-"`
+}
+
+// Button is an inline keyboard button. It either opens Url or sends
+// CallbackData, signed by core, back to /api/callbacks.
+type Button struct {
+	Text         string `json:"text"`
+	Url          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
 }
 
 type ChannelTaskResponse struct {
-	ApiId   int    `json:"api_id" db:"api_id"`
-	ApiHash string `json:"api_hash" db:"api_hash"`
-	Title   string `json:"title" db:"title"`
-	Body    string `json:"body" db:"body"`
-	Url     string `json:"url" db:"task_url"`
+	ApiId  int    `json:"api_id" db:"api_id"`
+	TaskId int    `json:"task_id,omitempty" db:"task_id"`
+	Title  string `json:"title" db:"title"`
+	Body   string `json:"body" db:"body"`
+	Url    string `json:"url" db:"task_url"`
+	FormattedMessage
+	Buttons [][]Button `json:"buttons,omitempty" db:"-"`
+}
+
+type ChannelDigestResponse struct {
+	DigestId int    `json:"digest_id" db:"id"`
+	ApiId    int    `json:"api_id" db:"api_id"`
+	Title    string `json:"title" db:"title"`
+	Body     string `json:"body" db:"body"`
+	Count    int    `json:"count" db:"tasks_count"`
+	FormattedMessage
 }
 
 type ChannelTasksResponse struct {
-	Tasks []ChannelTaskResponse `json:"tasks"`
+	Tasks   []ChannelTaskResponse   `json:"tasks"`
+	Digests []ChannelDigestResponse `json:"digests,omitempty"`
 }
 
 type UserTaskResponse struct {
-	TgId  int    `json:"tg_id" db:"tg_id"`
-	Title string `json:"title" db:"title"`
-	Body  string `json:"body" db:"body"`
-	Url   string `json:"url" db:"task_url"`
+	TgId int `json:"tg_id" db:"tg_id"`
+	// TaskId is what the feedback endpoints take as task_id. It is not set
+	// for messages that combine several tasks.
+	TaskId int    `json:"task_id,omitempty" db:"task_id"`
+	Title  string `json:"title" db:"title"`
+	Body   string `json:"body" db:"body"`
+	Url    string `json:"url" db:"task_url"`
+	FormattedMessage
+	Buttons [][]Button `json:"buttons,omitempty" db:"-"`
+}
+
+type UserDigestResponse struct {
+	DigestId int    `json:"digest_id" db:"id"`
+	TgId     int    `json:"tg_id" db:"tg_id"`
+	Title    string `json:"title" db:"title"`
+	Body     string `json:"body" db:"body"`
+	Count    int    `json:"count" db:"tasks_count"`
+	FormattedMessage
 }
 
 type UserTasksResponse struct {
-	Tasks []UserTaskResponse `json:"tasks"`
+	Tasks   []UserTaskResponse   `json:"tasks"`
+	Digests []UserDigestResponse `json:"digests,omitempty"`
+}
+
+type CallbackResponse struct {
+	Action string `json:"action"`
+}
+
+type SavedTaskResponse struct {
+	Url     string    `json:"task_url" db:"task_url"`
+	Title   string    `json:"title" db:"title"`
+	SavedAt time.Time `json:"saved_at" db:"saved_at"`
+}
+
+type SavedTasksResponse struct {
+	Tasks []SavedTaskResponse `json:"tasks"`
+}
+
+// ExclusionRuleResponse is a suggested exclusion: a category or a keyword
+// the user repeatedly marked as not interesting, Count times.
+type ExclusionRuleResponse struct {
+	Type       string `json:"type"`
+	CategoryId int    `json:"category_id,omitempty" db:"category_id"`
+	Category   string `json:"category,omitempty" db:"category"`
+	Keyword    string `json:"keyword,omitempty"`
+	Count      int    `json:"count" db:"count"`
+}
+
+type ExclusionRulesResponse struct {
+	Rules []ExclusionRuleResponse `json:"rules"`
+}
+
+// ClickRateResponse holds the click-through rate of the links issued for
+// a category or a source: Clicked of Links were opened at least once.
+type ClickRateResponse struct {
+	CategoryId int     `json:"category_id,omitempty" db:"category_id"`
+	Category   string  `json:"category,omitempty" db:"category"`
+	Source     string  `json:"source,omitempty" db:"source"`
+	Links      int     `json:"links" db:"links"`
+	Clicked    int     `json:"clicked" db:"clicked"`
+	Clicks     int     `json:"clicks" db:"clicks"`
+	Ctr        float64 `json:"ctr" db:"-"`
+}
+
+type ClickRatesResponse struct {
+	Rates []ClickRateResponse `json:"rates"`
+}
+
+type ChannelListItemResponse struct {
+	ApiId     int        `json:"api_id" db:"api_id"`
+	Name      string     `json:"name" db:"name"`
+	Status    string     `json:"status" db:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// ChannelCredentialsResponse is the only response that carries the api_hash
+// of a channel, everywhere else it is redacted.
+type ChannelCredentialsResponse struct {
+	ApiId   int    `json:"api_id" db:"api_id"`
+	ApiHash string `json:"api_hash" db:"api_hash"`
+}
+
+type ChannelListResponse struct {
+	Channels []ChannelListItemResponse `json:"channels"`
+	Total    int                       `json:"total"`
+	Page     int                       `json:"page"`
+	PerPage  int                       `json:"per_page"`
+}
+
+type UserListItemResponse struct {
+	TgId     int    `json:"tg_id" db:"tg_id"`
+	Username string `json:"username" db:"username"`
+	Status   string `json:"status" db:"status"`
+}
+
+type UserListResponse struct {
+	Users   []UserListItemResponse `json:"users"`
+	Total   int                    `json:"total"`
+	Page    int                    `json:"page"`
+	PerPage int                    `json:"per_page"`
+}
+
+type SubscriptionResponse struct {
+	CategoryId int    `json:"category_id" db:"category_id"`
+	Category   string `json:"category" db:"category"`
+}
+
+type DeliveryRecordResponse struct {
+	Url         string    `json:"task_url" db:"task_url"`
+	IsDelivered bool      `json:"is_delivered" db:"is_delivered"`
+	IsPermanent bool      `json:"is_permanent" db:"is_permanent"`
+	Reason      string    `json:"reason" db:"reason"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type NotInterestedRecordResponse struct {
+	Url        string    `json:"task_url" db:"task_url"`
+	Title      string    `json:"title" db:"title"`
+	CategoryId int       `json:"category_id" db:"category_id"`
+	Reason     string    `json:"reason" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type HiddenTaskRecordResponse struct {
+	Url      string    `json:"task_url" db:"task_url"`
+	HiddenAt time.Time `json:"hidden_at" db:"hidden_at"`
+}
+
+// PendingTaskRecordResponse is a task held for quiet hours or queued for a
+// digest.
+type PendingTaskRecordResponse struct {
+	Url     string    `json:"task_url" db:"task_url"`
+	Title   string    `json:"title" db:"title"`
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+}
+
+type DigestRecordResponse struct {
+	Title      string     `json:"title" db:"title"`
+	TasksCount int        `json:"tasks_count" db:"tasks_count"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ServedAt   *time.Time `json:"served_at" db:"served_at"`
+}
+
+type MatchRecordResponse struct {
+	Url         string     `json:"task_url" db:"task_url"`
+	FLName      string     `json:"fl_name" db:"fl_name"`
+	CategoryId  int        `json:"category_id" db:"category_id"`
+	PublishedAt *time.Time `json:"published_at" db:"published_at"`
+	MatchedAt   time.Time  `json:"matched_at" db:"matched_at"`
+}
+
+type LinkRecordResponse struct {
+	Url        string    `json:"task_url" db:"task_url"`
+	FLName     string    `json:"fl_name" db:"fl_name"`
+	CategoryId int       `json:"category_id" db:"category_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type ClickRecordResponse struct {
+	Url       string    `json:"task_url" db:"task_url"`
+	ClickedAt time.Time `json:"clicked_at" db:"clicked_at"`
+}
+
+// UserActivityResponse is what was recorded while serving a user: feedback
+// marks, pending tasks and digests, matches and tracked links with clicks.
+type UserActivityResponse struct {
+	NotInterested []NotInterestedRecordResponse `json:"not_interested"`
+	HiddenTasks   []HiddenTaskRecordResponse    `json:"hidden_tasks"`
+	HeldTasks     []PendingTaskRecordResponse   `json:"held_tasks"`
+	DigestQueue   []PendingTaskRecordResponse   `json:"digest_queue"`
+	Digests       []DigestRecordResponse        `json:"digests"`
+	Matches       []MatchRecordResponse         `json:"matches"`
+	Links         []LinkRecordResponse          `json:"tracked_links"`
+	Clicks        []ClickRecordResponse         `json:"clicks"`
+}
+
+// UserExportResponse is everything stored about a user.
+type UserExportResponse struct {
+	Profile       UserResponse             `json:"profile"`
+	Subscriptions []SubscriptionResponse   `json:"subscriptions"`
+	Deliveries    []DeliveryRecordResponse `json:"deliveries"`
+	SavedTasks    []SavedTaskResponse      `json:"saved_tasks"`
+	Activity      UserActivityResponse     `json:"activity"`
+	ExportedAt    time.Time                `json:"exported_at"`
+}
+
+type TaskSearchResponse struct {
+	Tasks   []Task `json:"tasks"`
+	Total   int    `json:"total"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+}
+
+type TaskCountResponse struct {
+	Day      string `json:"day" db:"day"`
+	Source   string `json:"source" db:"source"`
+	Category string `json:"category" db:"category"`
+	Tasks    int    `json:"tasks" db:"tasks"`
+}
+
+// DeliveryCountResponse counts the tasks matched for a user or a channel
+// and the delivery reports received for it. Key is tg_id or api_id.
+type DeliveryCountResponse struct {
+	Type      string `json:"type" db:"type"`
+	Key       int    `json:"key" db:"key"`
+	Matches   int    `json:"matches" db:"matches"`
+	Delivered int    `json:"delivered" db:"delivered"`
+	Failed    int    `json:"failed" db:"failed"`
+}
+
+type SubscriberCountResponse struct {
+	ActiveUsers    int `json:"active_users" db:"active_users"`
+	Users          int `json:"users" db:"users"`
+	ActiveChannels int `json:"active_channels" db:"active_channels"`
+	Channels       int `json:"channels" db:"channels"`
+}
+
+type LatencyResponse struct {
+	Deliveries    int     `json:"deliveries" db:"deliveries"`
+	MedianSeconds float64 `json:"median_seconds" db:"median_seconds"`
+}
+
+type CategoryCountResponse struct {
+	CategoryId int    `json:"category_id" db:"category_id"`
+	Category   string `json:"category" db:"category"`
+	Tasks      int    `json:"tasks" db:"tasks"`
+	Matches    int    `json:"matches" db:"matches"`
+}
+
+type TemplateResponse struct {
+	Id   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	Body string `json:"body" db:"body"`
+}
+
+type TemplatesResponse struct {
+	Templates []TemplateResponse `json:"templates"`
+}
+
+type TemplatePreviewResponse struct {
+	Body string `json:"body"`
+}
+
+// DigestScheduleResponse describes a user or channel that has tasks
+// waiting for a digest. Key is tg_id for users and api_id for channels.
+type DigestScheduleResponse struct {
+	Key          int       `db:"key"`
+	Timezone     string    `db:"timezone"`
+	DeliveryMode string    `db:"delivery_mode"`
+	DigestTime   string    `db:"digest_time"`
+	Language     string    `db:"language"`
+	QueuedAt     time.Time `db:"queued_at"`
+	QuietHours
+}
+
+type QueuedTaskResponse struct {
+	Id    int    `db:"id"`
+	Title string `db:"title"`
+	Body  string `db:"body"`
+	Url   string `db:"task_url"`
+}
+
+type DigestInput struct {
+	Title string
+	Body  string
+	Count int
+}
+
+type RecipientStatusResponse struct {
+	Status string `json:"status"`
+}
+
+// ApiKey is the client a request was authenticated as.
+type ApiKey struct {
+	Id     int
+	Name   string
+	Scopes []string
+}
+
+// HasScope reports whether the key grants scope. Admin keys grant every
+// scope.
+func (k ApiKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+type ApiKeyResponse struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ApiKeysResponse struct {
+	Keys []ApiKeyResponse `json:"keys"`
+}
+
+// ApiKeyCreatedResponse carries the plaintext key. It is only shown once,
+// core keeps a hash of it.
+type ApiKeyCreatedResponse struct {
+	Id     int    `json:"id"`
+	Key    string `json:"key"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// ApiKeySecret is the sealed signing secret of an API key.
+type ApiKeySecret struct {
+	Id            int    `db:"id"`
+	SigningSecret string `db:"signing_secret"`
 }